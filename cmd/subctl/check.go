@@ -0,0 +1,69 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subctl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/cli"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/internal/exit"
+	"github.com/submariner-io/subctl/internal/restconfig"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	"github.com/submariner-io/subctl/pkg/deploy"
+)
+
+var checkOperatorNamespace string
+
+var checkBrokerRestConfigProducer = restconfig.NewProducer().
+	WithDefaultNamespace(constants.DefaultBrokerNamespace)
+
+var (
+	checkCmd = &cobra.Command{
+		Use:   "check",
+		Short: "Check the health of Submariner components",
+	}
+
+	checkBrokerCmd = &cobra.Command{
+		Use:   "broker",
+		Short: "Check the health of a deployed broker",
+		Long: "This command checks a deployed broker's RBAC, operator, CRDs, Broker resource and globalnet configuration, " +
+			"without modifying anything. It exits with a non-zero status if any check fails.",
+		Run: func(cmd *cobra.Command, args []string) {
+			exit.OnError(checkBrokerRestConfigProducer.RunOnSelectedContext(checkBrokerInContext, cli.NewReporter()))
+		},
+	}
+)
+
+func init() {
+	checkBrokerCmd.Flags().StringVar(&checkOperatorNamespace, "operator-namespace", "",
+		fmt.Sprintf("namespace in which the operator was deployed (default %q)", constants.OperatorNamespace))
+	checkBrokerRestConfigProducer.SetupFlags(checkBrokerCmd.Flags())
+	checkCmd.AddCommand(checkBrokerCmd)
+	rootCmd.AddCommand(checkCmd)
+}
+
+func checkBrokerInContext(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
+	_, err := deploy.CheckBroker(context.Background(), namespace, checkOperatorNamespace, clusterInfo.ClientProducer, status)
+
+	return err //nolint:wrapcheck // No need to wrap errors here.
+}
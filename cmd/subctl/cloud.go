@@ -29,6 +29,7 @@ const (
 	infraIDFlag        = "infra-id"
 	regionFlag         = "region"
 	defaultNumGateways = 1
+	defaultMaxGateways = 10
 	projectIDFlag      = "project-id"
 	cloudEntryFlag     = "cloud-entry"
 )
@@ -19,9 +19,14 @@ limitations under the License.
 package subctl
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"strings"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/submariner-io/admiral/pkg/reporter"
 	"github.com/submariner-io/subctl/internal/cli"
@@ -30,27 +35,50 @@ import (
 	"github.com/submariner-io/subctl/internal/exit"
 	"github.com/submariner-io/subctl/internal/restconfig"
 	"github.com/submariner-io/subctl/pkg/broker"
+	"github.com/submariner-io/subctl/pkg/brokercr"
 	"github.com/submariner-io/subctl/pkg/cluster"
 	"github.com/submariner-io/subctl/pkg/deploy"
 	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
-	"k8s.io/apimachinery/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
 )
 
 var (
-	deployflags       deploy.BrokerOptions
-	ipsecSubmFile     string
-	defaultComponents = []string{component.ServiceDiscovery, component.Connectivity}
+	deployflags           deploy.BrokerOptions
+	ipsecSubmFile         string
+	joinInfoFile          string
+	globalnetClusterSizes map[string]int
+	operatorTolerations   []string
+	operatorEnv           []string
+	caCertFile            string
+	caKeyFile             string
+	defaultComponents     = []string{component.ServiceDiscovery, component.Connectivity}
 )
 
+// The broker's context is checked, to avoid deploying it on the wrong cluster through the ambient current context.
 var deployRestConfigProducer = restconfig.NewProducer().
-	WithDefaultNamespace(constants.DefaultBrokerNamespace)
+	WithDefaultNamespace(constants.DefaultBrokerNamespace).
+	WithExplicitContext()
 
 // deployBroker represents the deployBroker command.
 var deployBroker = &cobra.Command{
 	Use:   "deploy-broker",
 	Short: "Deploys the broker",
 	Run: func(cmd *cobra.Command, args []string) {
-		exit.OnError(deployRestConfigProducer.RunOnSelectedContext(deployBrokerInContext, cli.NewReporter()))
+		err := deployRestConfigProducer.RunOnSelectedContext(deployBrokerInContext, cli.NewReporter())
+
+		// Timeouts are distinguished, since retrying may succeed
+		var timeoutErr *deploy.TimeoutError
+		if errors.As(err, &timeoutErr) {
+			exit.OnTimeout(err)
+		}
+
+		// The broker is deployed even if its join information couldn't be written
+		var exportErr *deploy.JoinInfoExportError
+		if errors.As(err, &exportErr) {
+			exit.OnPartialFailure(err)
+		}
+
+		exit.OnError(err)
 	},
 }
 
@@ -67,9 +95,23 @@ func addDeployBrokerFlags() {
 		globalnet.DefaultGlobalnetCIDR, "GlobalCIDR supernet range for allocating GlobalCIDRs to each cluster")
 	deployBroker.PersistentFlags().UintVar(&deployflags.BrokerSpec.DefaultGlobalnetClusterSize, "globalnet-cluster-size",
 		globalnet.DefaultGlobalnetClusterSize, "default cluster size for GlobalCIDR allocated to each cluster (amount of global IPs)")
+	deployBroker.PersistentFlags().StringToIntVar(&globalnetClusterSizes, "globalnet-cluster-sizes", nil,
+		"comma-separated list of cluster ID=size pairs, pre-allocating GlobalCIDRs of the given size to specific clusters")
+	deployBroker.PersistentFlags().StringSliceVar(&deployflags.GlobalnetExcludedCIDRs, "globalnet-excluded-cidrs", nil,
+		"comma-separated list of sub-ranges of the globalnet CIDR range which are never allocated to clusters")
 
 	deployBroker.PersistentFlags().StringVar(&ipsecSubmFile, "ipsec-psk-from", "",
 		"import IPsec PSK from existing submariner broker file, like broker-info.subm")
+	deployBroker.PersistentFlags().StringVar(&joinInfoFile, "join-info-file", broker.InfoFileName,
+		"file to which the information needed to join the broker is written once it's deployed")
+	deployBroker.PersistentFlags().IntVar(&deployflags.IPsec.NATTPort, "ipsec-natt-port", 0,
+		"IPsec NAT-T port used by the joining clusters, unless overridden with join's --nattport (0 for the join default)")
+	deployBroker.PersistentFlags().StringVar(&deployflags.IPsec.Encapsulation, "ipsec-encapsulation", "",
+		fmt.Sprintf("preferred IPsec encapsulation of the joining clusters, any of %s: auto uses UDP encapsulation only across NAT, "+
+			"udp always uses it, esp disables NAT traversal", strings.Join(broker.Encapsulations, ",")))
+	deployBroker.PersistentFlags().DurationVar(&deployflags.TokenTTL, "token-ttl", 0,
+		fmt.Sprintf("lifetime of the broker administrator token written to the join information, at least %v, if the cluster "+
+			"supports bound service account tokens (0 for a long-lived token)", broker.MinTokenTTL))
 
 	deployBroker.PersistentFlags().StringSliceVar(&deployflags.BrokerSpec.DefaultCustomDomains, "custom-domains", nil,
 		"list of domains to use for multicluster service discovery")
@@ -77,20 +119,162 @@ func addDeployBrokerFlags() {
 	deployBroker.PersistentFlags().StringSliceVar(&deployflags.BrokerSpec.Components, "components", defaultComponents,
 		fmt.Sprintf("The components to be installed - any of %s", strings.Join(deploy.ValidComponents, ",")))
 
+	deployBroker.PersistentFlags().StringSliceVar(&deployflags.FeatureGates, "feature-gates", nil,
+		"comma-separated list of feature gates to enable, allowing the corresponding experimental components in --components")
+
 	deployBroker.PersistentFlags().StringVar(&deployflags.Repository, "repository", "", "image repository")
 	deployBroker.PersistentFlags().StringVar(&deployflags.ImageVersion, "version", "", "image version")
+	deployBroker.PersistentFlags().StringToStringVar(&deployflags.ImageOverrides, "image-override", nil,
+		"override component image, as comma-separated component=image pairs")
+	deployBroker.PersistentFlags().BoolVar(&deployflags.PinImageDigests, "pin-image-digests", false,
+		"resolve the image tags to their digests by querying the registry, and deploy the images by digest")
 
+	deployBroker.PersistentFlags().StringVar(&deployflags.OperatorNamespace, "operator-namespace", "",
+		fmt.Sprintf("namespace in which to deploy the operator (default %q)", constants.OperatorNamespace))
+	deployBroker.PersistentFlags().StringToStringVar(&deployflags.OperatorNodeSelector, "operator-node-selector", nil,
+		"node selector for the operator pods, as comma-separated label=value pairs")
+	deployBroker.PersistentFlags().StringSliceVar(&operatorTolerations, "operator-tolerations", nil,
+		"comma-separated list of tolerations for the operator pods, each of the form key[=value][:effect]")
+	deployBroker.PersistentFlags().StringVar(&deployflags.OperatorResources.CPURequest, "operator-cpu-request", "",
+		"CPU request of the operator container, e.g. 100m")
+	deployBroker.PersistentFlags().StringVar(&deployflags.OperatorResources.CPULimit, "operator-cpu-limit", "",
+		"CPU limit of the operator container, e.g. 500m")
+	deployBroker.PersistentFlags().StringVar(&deployflags.OperatorResources.MemoryRequest, "operator-memory-request", "",
+		"memory request of the operator container, e.g. 128Mi")
+	deployBroker.PersistentFlags().StringVar(&deployflags.OperatorResources.MemoryLimit, "operator-memory-limit", "",
+		"memory limit of the operator container, e.g. 256Mi")
+	deployBroker.PersistentFlags().StringArrayVar(&operatorEnv, "operator-env", nil,
+		"environment variable to set in the operator's container, as NAME=VALUE (e.g. NO_PROXY=.svc,.cluster.local); may be repeated")
 	deployBroker.PersistentFlags().BoolVar(&deployflags.OperatorDebug, "operator-debug", false, "enable operator debugging (verbose logging)")
+
+	deployBroker.PersistentFlags().IntVar(&deployflags.Retry.MaxAttempts, "retry-attempts", 5,
+		"maximum number of attempts when deploying the operator and broker fail with transient API server errors")
+	deployBroker.PersistentFlags().DurationVar(&deployflags.Retry.InitialInterval, "retry-interval", 2*time.Second,
+		"initial interval between attempts, doubled after each failure")
+
+	deployBroker.PersistentFlags().BoolVar(&deployflags.AdoptExistingNamespace, "adopt-existing-namespace", false,
+		"deploy into an existing broker namespace even if it wasn't created by subctl")
+
+	deployBroker.PersistentFlags().DurationVar(&deployflags.VerifyTimeout, "verify-timeout", 0,
+		"wait up to the given duration for the deployed broker to become ready (0 to skip verification)")
+
+	deployBroker.PersistentFlags().BoolVar(&deployflags.SkipRBAC, "skip-rbac", false,
+		"use the existing, externally-managed broker namespace, RBAC and service accounts instead of creating them")
+
+	deployBroker.PersistentFlags().StringVar(&caCertFile, "ca-cert", "",
+		"PEM file containing the CA certificate to use as the broker's trust anchor, instead of the broker cluster's CA")
+	deployBroker.PersistentFlags().StringVar(&caKeyFile, "ca-key", "", "PEM file containing the private key of the CA given by --ca-cert")
+	deployBroker.PersistentFlags().StringVar(&deployflags.CASecret, "ca-secret", "",
+		"existing TLS secret in the broker namespace containing the CA to use as the broker's trust anchor")
+
+	deployBroker.PersistentFlags().StringToStringVar(&deployflags.NamespaceLabels, "broker-namespace-labels", nil,
+		"additional labels for the broker namespace, as comma-separated key=value pairs")
+	deployBroker.PersistentFlags().StringToStringVar(&deployflags.NamespaceAnnotations, "broker-namespace-annotations", nil,
+		"additional annotations for the broker namespace, as comma-separated key=value pairs")
+	deployBroker.PersistentFlags().StringToStringVar(&deployflags.BrokerLabels, "broker-labels", nil,
+		"additional labels for the Broker resource, as comma-separated key=value pairs")
+
+	deployBroker.PersistentFlags().BoolVar(&deployflags.StrictCRDs, "strict-crds", false,
+		"abort instead of updating CRDs installed by an incompatible Submariner release")
+
+	deployBroker.PersistentFlags().DurationVar(&deployflags.CRDEstablishTimeout, "crd-timeout", deploy.DefaultCRDEstablishTimeout,
+		"wait up to the given duration for the Broker CRD to be established before creating the Broker resource")
+
+	deployBroker.PersistentFlags().StringVar(&deployflags.BrokerAPIVersion, "broker-api-version", "",
+		"API version with which to create the Broker resource, e.g. v1alpha1; it must be served by the Broker CRD")
+
+	deployBroker.PersistentFlags().DurationVar(&deployflags.Timeout, "timeout", 0,
+		fmt.Sprintf("abort the deployment if it doesn't complete within the given duration, exiting with code %d "+
+			"(0 for no timeout)", exit.TimeoutCode))
+
+	deployBroker.PersistentFlags().BoolVar(&deployflags.DryRun, "dry-run", false,
+		"validate and display the resources which would be deployed, without applying them")
+
+	deployBroker.PersistentFlags().BoolVar(&deployflags.DetectDriftOnly, "detect-drift", false,
+		"only compare the live Broker resource and globalnet ConfigMap with the intended ones, failing if they differ")
 }
 
 func deployBrokerInContext(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
 	deployflags.BrokerNamespace = namespace
 
-	if err := deploy.Broker(&deployflags, clusterInfo.ClientProducer, status); err != nil {
-		return err //nolint:wrapcheck // No need to wrap errors here.
+	deployflags.GlobalnetClusterSizes = make(map[string]uint, len(globalnetClusterSizes))
+	for clusterID, size := range globalnetClusterSizes {
+		if size <= 0 {
+			return status.Error(fmt.Errorf("invalid globalnet size %d for cluster %q", size, clusterID), "")
+		}
+
+		deployflags.GlobalnetClusterSizes[clusterID] = uint(size)
+	}
+
+	deployflags.OperatorTolerations = make([]corev1.Toleration, len(operatorTolerations))
+	for i, toleration := range operatorTolerations {
+		deployflags.OperatorTolerations[i] = parseToleration(toleration)
+	}
+
+	deployflags.OperatorEnv = make(map[string]string, len(operatorEnv))
+	for _, variable := range operatorEnv {
+		name, value, found := strings.Cut(variable, "=")
+		if !found {
+			return status.Error(fmt.Errorf("invalid operator environment variable %q, expected NAME=VALUE", variable), "")
+		}
+
+		deployflags.OperatorEnv[name] = value
+	}
+
+	if err := loadBrokerCA(); err != nil {
+		return status.Error(err, "error loading the broker CA")
+	}
+
+	deployflags.JoinInfo = &deploy.JoinInfoExport{
+		File:         joinInfoFile,
+		BrokerURL:    clusterInfo.RestConfig.Host + clusterInfo.RestConfig.APIPath,
+		IPsecPSKFile: ipsecSubmFile,
+	}
+
+	// Cancel the deployment on interrupt, so that the interrupted step is reported
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	return deploy.Broker(ctx, &deployflags, clusterInfo.ClientProducer, status) //nolint:wrapcheck // No need to wrap errors here.
+}
+
+func loadBrokerCA() error {
+	if caCertFile == "" && caKeyFile == "" {
+		return nil
+	}
+
+	if caCertFile == "" || caKeyFile == "" {
+		return errors.New("both --ca-cert and --ca-key must be specified")
+	}
+
+	cert, err := os.ReadFile(caCertFile)
+	if err != nil {
+		return errors.Wrapf(err, "error reading the CA certificate from %q", caCertFile)
+	}
+
+	key, err := os.ReadFile(caKeyFile)
+	if err != nil {
+		return errors.Wrapf(err, "error reading the CA key from %q", caKeyFile)
+	}
+
+	deployflags.CA = &brokercr.CA{Cert: cert, Key: key}
+
+	return nil
+}
+
+// parseToleration parses a toleration of the form key[=value][:effect]; without a value, the key only has to exist.
+func parseToleration(toleration string) corev1.Toleration {
+	result := corev1.Toleration{Operator: corev1.TolerationOpExists}
+
+	keyValue, effect, found := strings.Cut(toleration, ":")
+	if found {
+		result.Effect = corev1.TaintEffect(effect)
+	}
+
+	result.Key, result.Value, found = strings.Cut(keyValue, "=")
+	if found {
+		result.Operator = corev1.TolerationOpEqual
 	}
 
-	return broker.WriteInfoToFile( //nolint:wrapcheck // No need to wrap errors here.
-		clusterInfo.RestConfig, namespace, ipsecSubmFile,
-		sets.New(deployflags.BrokerSpec.Components...), deployflags.BrokerSpec.DefaultCustomDomains, status)
+	return result
 }
@@ -19,22 +19,33 @@ limitations under the License.
 package subctl
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/submariner-io/admiral/pkg/reporter"
 	"github.com/submariner-io/subctl/internal/cli"
 	"github.com/submariner-io/subctl/internal/exit"
 	"github.com/submariner-io/subctl/internal/gather"
 	"github.com/submariner-io/subctl/internal/restconfig"
 	"github.com/submariner-io/subctl/pkg/cluster"
+	k8serrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
 )
 
 var options gather.Options
 
-var gatherRestConfigProducer = restconfig.NewProducer().WithContextsFlag()
+var gatherRestConfigProducer = restconfig.NewProducer().WithContextsFlag().WithPrefixedContext("broker")
 
 var gatherCmd = &cobra.Command{
 	Use:   "gather",
@@ -43,22 +54,86 @@ var gatherCmd = &cobra.Command{
 		"can be selected by component (%v) and type (%v). Default is to capture all data.",
 		strings.Join(gather.AllModules.UnsortedList(), ","), strings.Join(gather.AllTypes.UnsortedList(), ",")),
 	Run: func(command *cobra.Command, args []string) {
+		if listGatherables {
+			printGatherables()
+			return
+		}
+
+		err := applyGatherConfig(command.Flags())
+		exit.OnErrorWithMessage(err, "Invalid configuration file")
+
+		now := time.Now()
+
+		zone := time.UTC
+		if useLocalTime {
+			zone = time.Local
+		}
+
 		if options.Directory == "" {
-			options.Directory = "submariner-" + time.Now().UTC().Format("20060102150405") // submariner-YYYYMMDDHHMMSS
+			options.Directory = "submariner-" + now.In(zone).Format("20060102150405") // submariner-YYYYMMDDHHMMSS
 		}
 
-		err := checkGatherArguments()
+		err = checkGatherArguments(command.Flags())
+		printSelectionError(err)
 		exit.OnErrorWithMessage(err, "Invalid argument")
 
+		err = gather.WriteMetadata(options.Directory, now, zone)
+		exit.OnErrorWithMessage(err, "Error writing the gather metadata")
+
 		status := cli.NewReporter()
+		warnings := gather.CaptureWarnings()
+
+		clusterInfos := []*cluster.Info{}
+		clusterNames := sets.New[string]()
 
-		exit.OnError(gatherRestConfigProducer.RunOnAllContexts(
+		// The contexts are processed sequentially to obtain their configuration; the data itself is gathered in parallel
+		err = gatherRestConfigProducer.RunOnAllContexts(
 			func(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
-				return gather.Data(clusterInfo, status, options) //nolint:wrapcheck // No need to wrap errors here.
-			}, status))
+				if clusterNames.Has(clusterInfo.Name) {
+					status.Warning("Skipping context for cluster %q, the cluster was already selected through another context",
+						clusterInfo.Name)
+					return nil
+				}
+
+				clusterNames.Insert(clusterInfo.Name)
+				clusterInfos = append(clusterInfos, clusterInfo)
+
+				return nil
+			}, status)
+
+		clusterInfos, clustersErr := gatherAllClusters(clusterInfos, status)
+		err = k8serrors.NewAggregate([]error{err, clustersErr})
+
+		if brokerErr := crossReferenceBroker(clusterInfos, status); brokerErr != nil {
+			err = k8serrors.NewAggregate([]error{err, brokerErr})
+		}
+
+		if warnings.Len() > 0 {
+			fmt.Printf("\nEncountered following Kubernetes warnings while running:\n%s", warnings.String())
+		}
+
+		if options.Archive {
+			archiveGatheredData()
+		}
+
+		exitOnContextFailures(clusterInfos, err)
 	},
 }
 
+var (
+	maxParallelGathers int
+	logsSince          string
+	logsUntil          string
+	useLocalTime       bool
+	listGatherables    bool
+	gatherConfigFile   string
+	excludedTypes      []string
+	excludedModules    []string
+	sinkURL            string
+	sinkHeaders        map[string]string
+	extraResources     []string
+)
+
 func init() {
 	addGatherFlags(gatherCmd)
 	rootCmd.AddCommand(gatherCmd)
@@ -69,26 +144,434 @@ func addGatherFlags(gatherCmd *cobra.Command) {
 		"comma-separated list of data types to gather")
 	gatherCmd.Flags().StringSliceVar(&options.Modules, "module", gather.AllModules.UnsortedList(),
 		"comma-separated list of components for which to gather data")
+	gatherCmd.Flags().StringSliceVar(&excludedTypes, "exclude-type", nil,
+		"comma-separated list of data types to exclude from the default selection; can't be combined with --type")
+	gatherCmd.Flags().StringSliceVar(&excludedModules, "exclude-module", nil,
+		"comma-separated list of components to exclude from the default selection; can't be combined with --module")
 	gatherCmd.Flags().StringVar(&options.Directory, "dir", "",
 		"the directory in which to store files. If not specified, a directory of the form \"submariner-<timestamp>\" "+
 			"is created in the current directory")
+	gatherCmd.Flags().StringSliceVar(&options.Namespaces, "namespaces", nil,
+		"comma-separated list of namespaces to scan for Submariner resources, in addition to the detected ones")
+	gatherCmd.Flags().StringArrayVar(&extraResources, "extra-resource", nil,
+		"additional kind of resource to gather, as group/version/kind[:namespace] (e.g. networking.istio.io/v1beta1/Gateway:istio-system), "+
+			"saved in an \"extra\" sub-directory; may be repeated")
+	gatherCmd.Flags().StringVarP(&options.Selector, "selector", "l", "",
+		"only gather the resources matching this label selector (e.g. submariner.io/clusterID=cluster1); "+
+			"the pod logs, metrics and diagnose results aren't constrained by it")
+	gatherCmd.Flags().BoolVar(&options.PreviousLogs, "previous-logs", true,
+		"also gather the logs of the previous container instances of the pods which restarted, e.g. when crash-looping")
+	gatherCmd.Flags().BoolVar(&options.DumpDatapath, "dump-datapath", false,
+		"schedule a diagnostic pod on each gateway node to dump its iptables and nftables rules")
+	gatherCmd.Flags().DurationVar(&options.GatewayHistoryWindow, "gateway-history", 0,
+		"record the Gateways' status repeatedly during the given duration (e.g. 1m), to trace flapping connections")
+	gatherCmd.Flags().DurationVar(&options.GatewayHistoryInterval, "gateway-history-interval", gather.DefaultGatewayHistoryInterval,
+		"the interval between the Gateway status samples recorded with --gateway-history")
+	gatherCmd.Flags().BoolVar(&useLocalTime, "local-time", false,
+		"use the local time zone instead of UTC for the default directory's timestamp")
+	gatherCmd.Flags().BoolVar(&options.Resume, "resume", false,
+		"resume a previous gather in the directory given by --dir, only collecting the data which is missing")
 	gatherCmd.Flags().BoolVar(&options.IncludeSensitiveData, "include-sensitive-data", false,
 		"do not redact sensitive data such as credentials and security tokens")
+	gatherCmd.Flags().StringSliceVar(&options.Redact, "redact", nil,
+		fmt.Sprintf("comma-separated list of the categories of sensitive data to redact, instead of all of them (any of %s); "+
+			"can't be combined with --include-sensitive-data", strings.Join(sets.List(gather.AllRedactionCategories), ",")))
+	gatherCmd.Flags().StringVar(&options.OutputFormat, "output", "",
+		"additionally write a machine-readable manifest of the gathered files; the only supported format is \"json\"")
+	gatherCmd.Flags().BoolVar(&options.Archive, "archive", false,
+		"store the gathered data in a compressed tar file named after the directory, e.g. \"submariner-<timestamp>.tar.gz\"")
+	gatherCmd.Flags().StringVar(&sinkURL, "sink-url", "",
+		"also upload each gathered file, as it's produced, with an HTTP PUT below the given URL; failed uploads are retried, "+
+			"then reported, and the files remain available locally")
+	gatherCmd.Flags().StringToStringVar(&sinkHeaders, "sink-header", nil,
+		"comma-separated list of header=value pairs added to the uploads, e.g. for authorization")
+	gatherCmd.Flags().BoolVar(&options.RemoveDirectory, "remove-dir", false,
+		"remove the directory once the gathered data has been archived")
+	gatherCmd.Flags().StringVar(&logsSince, "since", "",
+		"only gather logs written after this time, specified as an RFC 3339 timestamp or as a duration relative to now (e.g. 2h)")
+	gatherCmd.Flags().StringVar(&logsUntil, "until", "",
+		"only gather logs written before this time, specified as an RFC 3339 timestamp or as a duration relative to now (e.g. 30m)")
+	gatherCmd.Flags().Float32Var(&options.QPS, "qps", gather.DefaultQPS,
+		"maximum number of API requests per second made to each cluster")
+	gatherCmd.Flags().IntVar(&options.Burst, "burst", gather.DefaultBurst,
+		"maximum burst of API requests made to each cluster above the --qps rate")
+	gatherCmd.Flags().IntVar(&maxParallelGathers, "max-parallel", 4,
+		"maximum number of clusters from which to gather data in parallel")
+	gatherCmd.Flags().StringVar(&gatherConfigFile, "config", "",
+		"JSON or YAML file specifying the types, modules, dir, includeSensitiveData, redact and namespaces to use; "+
+			"flags given explicitly override the file's values")
+	gatherCmd.Flags().BoolVar(&listGatherables, "list", false,
+		"list the available modules and data types, describing what each gathers, and exit")
 	gatherRestConfigProducer.SetupFlags(gatherCmd.Flags())
 }
 
-func checkGatherArguments() error {
-	for _, t := range options.Types {
-		if !gather.AllTypes.Has(t) {
-			return fmt.Errorf("%q is not a supported type", t)
+// gatherConfig is the content of a gather configuration file.
+type gatherConfig struct {
+	Types                []string `json:"types,omitempty"`
+	Modules              []string `json:"modules,omitempty"`
+	Directory            string   `json:"dir,omitempty"`
+	IncludeSensitiveData *bool    `json:"includeSensitiveData,omitempty"`
+	Redact               []string `json:"redact,omitempty"`
+	Namespaces           []string `json:"namespaces,omitempty"`
+}
+
+// applyGatherConfig sets the flags which weren't given explicitly from the configuration file, if any. The values go through
+// the flags so that they're parsed, and checked later, in the same way.
+func applyGatherConfig(flags *pflag.FlagSet) error {
+	if gatherConfigFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(gatherConfigFile)
+	if err != nil {
+		return errors.Wrapf(err, "error reading %q", gatherConfigFile)
+	}
+
+	config := gatherConfig{}
+
+	// Unknown keys are rejected, so that misspelt settings aren't silently ignored
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return errors.Wrapf(err, "error parsing %q", gatherConfigFile)
+	}
+
+	// The flags corresponding to each configured key
+	values := map[string]string{}
+
+	if config.Types != nil {
+		values["type"] = strings.Join(config.Types, ",")
+	}
+
+	if config.Modules != nil {
+		values["module"] = strings.Join(config.Modules, ",")
+	}
+
+	if config.Directory != "" {
+		values["dir"] = config.Directory
+	}
+
+	if config.IncludeSensitiveData != nil {
+		values["include-sensitive-data"] = strconv.FormatBool(*config.IncludeSensitiveData)
+	}
+
+	if config.Redact != nil {
+		values["redact"] = strings.Join(config.Redact, ",")
+	}
+
+	if config.Namespaces != nil {
+		values["namespaces"] = strings.Join(config.Namespaces, ",")
+	}
+
+	for name, value := range values {
+		if flags.Changed(name) {
+			continue
+		}
+
+		if err := flags.Set(name, value); err != nil {
+			return errors.Wrapf(err, "invalid value %q in %q, used for --%s", value, gatherConfigFile, name)
+		}
+	}
+
+	return nil
+}
+
+func printGatherables() {
+	fmt.Println("Modules:")
+
+	for _, module := range gather.Modules() {
+		fmt.Printf("  %-18s %s\n", module.Name, module.Description)
+	}
+
+	fmt.Println("\nTypes:")
+
+	for _, dataType := range gather.Types() {
+		fmt.Printf("  %-18s %s\n", dataType.Name, dataType.Description)
+	}
+
+	fmt.Println("\nRedaction categories:")
+
+	for _, category := range gather.RedactionCategories() {
+		fmt.Printf("  %-18s %s\n", category.Name, category.Description)
+	}
+}
+
+// parseTimeBound parses the given value as an RFC 3339 timestamp, or as a duration in the past relative to now.
+func parseTimeBound(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither an RFC 3339 timestamp nor a duration", value)
+	}
+
+	return time.Now().Add(-duration), nil
+}
+
+// exitOnContextFailures summarizes the clusters which were gathered and the contexts and clusters which failed. If any failed,
+// it exits with a partial failure code when data was gathered from other clusters, and a failure code otherwise.
+func exitOnContextFailures(clusterInfos []*cluster.Info, err error) {
+	gathered := make([]string, len(clusterInfos))
+	for i := range clusterInfos {
+		gathered[i] = clusterInfos[i].Name
+	}
+
+	if len(gathered) > 0 {
+		fmt.Printf("Gathered data from cluster(s): %s\n", strings.Join(gathered, ", "))
+	}
+
+	if err == nil {
+		return
+	}
+
+	errs := []error{err}
+
+	var aggregate k8serrors.Aggregate
+	if errors.As(err, &aggregate) {
+		errs = k8serrors.Flatten(aggregate).Errors()
+	}
+
+	fmt.Println("Failed to gather data from the following context(s) and cluster(s):")
+
+	for _, failure := range errs {
+		var contextErr *restconfig.ContextError
+		if errors.As(failure, &contextErr) {
+			fmt.Printf("  %s: %v\n", contextErr.Context, contextErr.Err)
+		} else {
+			fmt.Printf("  %v\n", failure)
+		}
+	}
+
+	if len(gathered) == 0 {
+		exit.OnError(err)
+	}
+
+	exit.OnPartialFailure(err)
+}
+
+// crossReferenceBroker correlates the broker resources with the gathered clusters, if a broker context was given.
+func crossReferenceBroker(clusterInfos []*cluster.Info, status reporter.Interface) error {
+	_, err := gatherRestConfigProducer.RunOnSelectedPrefixedContext("broker",
+		func(brokerInfo *cluster.Info, _ string, status reporter.Interface) error {
+			return gather.CrossReferenceBroker(brokerInfo, clusterInfos, options.Directory, status) //nolint:wrapcheck // No need to wrap here
+		}, status)
+	if err != nil {
+		return &restconfig.ContextError{Context: "broker", Err: err}
+	}
+
+	return nil
+}
+
+func archiveGatheredData() {
+	archiveFile := filepath.Clean(options.Directory) + gather.ArchiveExtension
+
+	err := gather.Archive(options.Directory, archiveFile)
+	exit.OnErrorWithMessage(err, "Error archiving the gathered data")
+
+	fmt.Printf("The gathered data is archived in %q\n", archiveFile)
+
+	if options.RemoveDirectory {
+		err = os.RemoveAll(options.Directory)
+		exit.OnErrorWithMessage(err, fmt.Sprintf("Error removing directory %q", options.Directory))
+	}
+}
+
+// gatherAllClusters gathers the data from the given clusters, processing up to maxParallelGathers clusters at a time.
+// When running in parallel, each cluster's output is buffered and written out once its gathering is complete. The clusters
+// whose gathering completed are returned, along with the failures of the others as ContextErrors.
+func gatherAllClusters(clusterInfos []*cluster.Info, status reporter.Interface) ([]*cluster.Info, error) {
+	clusterErrs := make([]error, len(clusterInfos))
+
+	if maxParallelGathers <= 1 || len(clusterInfos) <= 1 {
+		for i, clusterInfo := range clusterInfos {
+			clusterErrs[i] = gather.Data(clusterInfo, status, options)
+
+			fmt.Println()
 		}
+
+		return gatheredClusters(clusterInfos, clusterErrs)
 	}
 
-	for _, m := range options.Modules {
-		if !gather.AllModules.Has(m) {
-			return fmt.Errorf("%q is not a supported module", m)
+	var (
+		wg          sync.WaitGroup
+		outputMutex sync.Mutex
+	)
+
+	semaphore := make(chan struct{}, maxParallelGathers)
+
+	for i, clusterInfo := range clusterInfos {
+		i, clusterInfo := i, clusterInfo
+
+		wg.Add(1)
+
+		semaphore <- struct{}{}
+
+		go func() {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			output := &bytes.Buffer{}
+			clusterOptions := options
+			clusterOptions.Writer = output
+
+			// Each goroutine only sets its own entry
+			clusterErrs[i] = gather.Data(clusterInfo, cli.NewReporterWithWriter(output), clusterOptions)
+
+			outputMutex.Lock()
+			defer outputMutex.Unlock()
+
+			fmt.Printf("Cluster %q\n%s\n", clusterInfo.Name, output.String())
+		}()
+	}
+
+	wg.Wait()
+
+	return gatheredClusters(clusterInfos, clusterErrs)
+}
+
+// gatheredClusters returns the clusters without errors, and the others' errors as an aggregate of ContextErrors.
+func gatheredClusters(clusterInfos []*cluster.Info, clusterErrs []error) ([]*cluster.Info, error) {
+	gathered := []*cluster.Info{}
+	errs := []error{}
+
+	for i := range clusterInfos {
+		if clusterErrs[i] != nil {
+			errs = append(errs, &restconfig.ContextError{Context: "cluster " + clusterInfos[i].Name, Err: clusterErrs[i]})
+		} else {
+			gathered = append(gathered, clusterInfos[i])
 		}
 	}
 
+	return gathered, k8serrors.NewAggregate(errs)
+}
+
+// printSelectionError writes the unsupported types and modules as JSON to the standard output when JSON output is requested,
+// for the benefit of tools building gather commands.
+func printSelectionError(err error) {
+	var selectionErr *gather.SelectionError
+	if options.OutputFormat != gather.OutputJSON || !errors.As(err, &selectionErr) {
+		return
+	}
+
+	data, marshalErr := json.MarshalIndent(selectionErr, "", "  ")
+	if marshalErr == nil {
+		fmt.Println(string(data))
+	}
+}
+
+func checkGatherArguments(flags *pflag.FlagSet) error {
+	err := gather.ValidateSelection(append(append([]string{}, options.Types...), excludedTypes...),
+		append(append([]string{}, options.Modules...), excludedModules...))
+	if err != nil {
+		return err //nolint:wrapcheck // No need to wrap here
+	}
+
+	if options.Types, err = applyExclusions(flags, options.Types, excludedTypes, "type"); err != nil {
+		return err
+	}
+
+	if options.Modules, err = applyExclusions(flags, options.Modules, excludedModules, "module"); err != nil {
+		return err
+	}
+
+	if options.Since, err = parseTimeBound(logsSince); err != nil {
+		return errors.Wrap(err, "invalid --since value")
+	}
+
+	if options.Until, err = parseTimeBound(logsUntil); err != nil {
+		return errors.Wrap(err, "invalid --until value")
+	}
+
+	if !options.Since.IsZero() && !options.Until.IsZero() && !options.Since.Before(options.Until) {
+		return fmt.Errorf("the --since time must be before the --until time")
+	}
+
+	if flags.Changed("redact") {
+		if options.IncludeSensitiveData {
+			return fmt.Errorf("--redact can't be combined with --include-sensitive-data")
+		}
+
+		if options.Redact == nil {
+			options.Redact = []string{}
+		}
+
+		if err := gather.ValidateRedaction(options.Redact); err != nil {
+			return err //nolint:wrapcheck // No need to wrap here
+		}
+	}
+
+	if options.QPS <= 0 || options.Burst <= 0 {
+		return fmt.Errorf("the --qps and --burst values must be positive")
+	}
+
+	if options.RemoveDirectory && !options.Archive {
+		return fmt.Errorf("the directory can only be removed when archiving")
+	}
+
+	if options.OutputFormat != "" && options.OutputFormat != gather.OutputJSON {
+		return fmt.Errorf("%q is not a supported output format", options.OutputFormat)
+	}
+
+	if options.GatewayHistoryWindow > 0 && options.GatewayHistoryInterval <= 0 {
+		return fmt.Errorf("the --gateway-history-interval must be positive")
+	}
+
+	if options.Resume {
+		if _, err := os.Stat(options.Directory); err != nil {
+			return errors.Wrapf(err, "unable to resume the gather in %q", options.Directory)
+		}
+	}
+
+	if err := gather.ValidateSelector(options.Selector); err != nil {
+		return err //nolint:wrapcheck // No need to wrap here
+	}
+
+	options.ExtraResources = nil
+
+	for _, value := range extraResources {
+		resource, err := gather.ParseExtraResource(value)
+		if err != nil {
+			return err //nolint:wrapcheck // No need to wrap here
+		}
+
+		options.ExtraResources = append(options.ExtraResources, resource)
+	}
+
+	if sinkURL != "" {
+		sink, err := gather.NewHTTPSink(sinkURL, sinkHeaders)
+		if err != nil {
+			return err //nolint:wrapcheck // No need to wrap here
+		}
+
+		options.Sink = sink
+	} else if len(sinkHeaders) > 0 {
+		return fmt.Errorf("the --sink-header values can only be used with --sink-url")
+	}
+
 	return nil
 }
+
+// applyExclusions removes the excluded values, which must have been validated, from the default selection; excluding values
+// while also explicitly selecting them with the corresponding inclusion flag is ambiguous and rejected.
+func applyExclusions(flags *pflag.FlagSet, selected, excluded []string, kind string) ([]string, error) {
+	if len(excluded) == 0 {
+		return selected, nil
+	}
+
+	if flags.Changed(kind) {
+		return nil, fmt.Errorf("--%s and --exclude-%s can't be used together", kind, kind)
+	}
+
+	remaining := sets.List(sets.New(selected...).Delete(excluded...))
+	if len(remaining) == 0 {
+		return nil, fmt.Errorf("all the %ss are excluded, there is nothing to gather", kind)
+	}
+
+	return remaining, nil
+}
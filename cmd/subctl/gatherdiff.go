@@ -0,0 +1,64 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/submariner-io/subctl/internal/exit"
+	"github.com/submariner-io/subctl/internal/gather"
+)
+
+var gatherDiffOutput string
+
+var gatherDiffCmd = &cobra.Command{
+	Use:   "diff <before-dir> <after-dir>",
+	Short: "Compare the resources collected by two gather runs",
+	Long: "This command compares the resources collected by two gather runs, listing the resources which were added, removed " +
+		"or modified. Volatile fields such as resource versions, managed fields and condition timestamps are ignored.",
+	Args: cobra.ExactArgs(2),
+	Run: func(command *cobra.Command, args []string) {
+		if gatherDiffOutput != "" && gatherDiffOutput != gather.OutputJSON {
+			exit.WithMessage(fmt.Sprintf("Unsupported output format %q", gatherDiffOutput))
+		}
+
+		result, err := gather.Diff(args[0], args[1])
+		exit.OnErrorWithMessage(err, "Error comparing the gathered data")
+
+		if gatherDiffOutput == gather.OutputJSON {
+			data, err := json.MarshalIndent(result, "", "  ")
+			exit.OnErrorWithMessage(err, "Error marshalling the differences")
+
+			fmt.Println(string(data))
+
+			return
+		}
+
+		result.Write(os.Stdout)
+	},
+}
+
+func init() {
+	gatherDiffCmd.Flags().StringVar(&gatherDiffOutput, "output", "",
+		"write the differences in a machine-readable format; the only supported format is \"json\"")
+	gatherCmd.AddCommand(gatherDiffCmd)
+}
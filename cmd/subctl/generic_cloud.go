@@ -24,25 +24,25 @@ import (
 	"github.com/submariner-io/subctl/internal/cli"
 	"github.com/submariner-io/subctl/internal/exit"
 	"github.com/submariner-io/subctl/pkg/cloud/cleanup"
+	"github.com/submariner-io/subctl/pkg/cloud/generic"
 	"github.com/submariner-io/subctl/pkg/cloud/prepare"
 	"github.com/submariner-io/subctl/pkg/cluster"
 )
 
 var (
-	genericCloudConfig struct {
-		gateways int
-	}
+	genericCloudConfig generic.Config
 
 	genericPrepareCmd = &cobra.Command{
 		Use:        "generic",
 		Deprecated: "Deprecated in 0.15, to be removed in 0.16. Use `subctl join` instead.",
 		Short:      "Prepares a generic cluster for Submariner",
-		Long:       "This command labels the required number of gateway nodes for Submariner installation.",
+		Long: "This command labels the required number of gateway nodes for Submariner installation, " +
+			"and reports the ports which must be opened in the firewall for each of them.",
 		Run: func(cmd *cobra.Command, args []string) {
 			exit.OnError(cloudRestConfigProducer.RunOnSelectedContext(
 				func(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
 					return prepare.GenericCluster( //nolint:wrapcheck // No need to wrap errors here.
-						clusterInfo, genericCloudConfig.gateways, status)
+						clusterInfo, &cloudOptions.ports, &genericCloudConfig, status)
 				}, cli.NewReporter()))
 		},
 	}
@@ -61,7 +61,10 @@ var (
 )
 
 func init() {
-	genericPrepareCmd.Flags().IntVar(&genericCloudConfig.gateways, "gateways", defaultNumGateways, "Number of gateways to deploy")
+	genericPrepareCmd.Flags().IntVar(&genericCloudConfig.Gateways, "gateways", defaultNumGateways, "Number of gateways to deploy")
+	genericPrepareCmd.Flags().StringVar(&genericCloudConfig.PublicInterface, "public-interface", "",
+		"Name of the gateway nodes' interface to use for inter-cluster traffic (defaults to auto-detection); only supported "+
+			"with Submariner releases whose gateway reads the "+generic.PublicInterfaceAnnotation+" annotation")
 	cloudPrepareCmd.AddCommand(genericPrepareCmd)
 
 	cloudCleanupCmd.AddCommand(genericCleanupCmd)
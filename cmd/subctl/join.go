@@ -22,9 +22,11 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/submariner-io/admiral/pkg/reporter"
 	"github.com/submariner-io/subctl/internal/cli"
 	"github.com/submariner-io/subctl/internal/constants"
@@ -58,6 +60,13 @@ var joinCmd = &cobra.Command{
 		exit.OnError(status.Error(err, "Error loading the broker information from the given file"))
 		status.Success("%s indicates broker is at %s", args[0], brokerInfo.BrokerURL)
 
+		if brokerInfo.ClientTokenExpiry != nil && brokerInfo.ClientTokenExpiry.Time.Before(time.Now()) {
+			exit.OnError(status.Error(fmt.Errorf("the broker administrator token in %s expired at %s", args[0],
+				brokerInfo.ClientTokenExpiry.Format(time.RFC3339)), "Re-deploy the broker to write a new token"))
+		}
+
+		applyBrokerIPsecSettings(brokerInfo.IPsec, cmd.Flags(), status)
+
 		exit.OnError(joinRestConfigProducer.RunOnSelectedContext(
 			func(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
 				return joinInContext(brokerInfo, clusterInfo, status)
@@ -144,6 +153,31 @@ func joinInContext(brokerInfo *broker.Info, clusterInfo *cluster.Info, status re
 		ctx, brokerInfo, clusterInfo, &joinFlags, clusterInfo.ClientProducer, status)
 }
 
+// applyBrokerIPsecSettings uses the IPsec settings recorded when deploying the broker, unless overridden by the join flags.
+func applyBrokerIPsecSettings(ipsec *broker.IPsecSettings, flags *pflag.FlagSet, status reporter.Interface) {
+	if ipsec == nil {
+		return
+	}
+
+	if ipsec.NATTPort != 0 && !flags.Changed("nattport") {
+		joinFlags.NATTPort = ipsec.NATTPort
+		status.Success("Using the broker's IPsec NAT-T port %d", ipsec.NATTPort)
+	}
+
+	switch ipsec.Encapsulation {
+	case broker.EncapsulationUDP:
+		if !flags.Changed("force-udp-encaps") {
+			joinFlags.ForceUDPEncaps = true
+			status.Success("Using the broker's preferred IPsec encapsulation, forcing UDP encapsulation")
+		}
+	case broker.EncapsulationESP:
+		if !flags.Changed("natt") {
+			joinFlags.NATTraversal = false
+			status.Success("Using the broker's preferred IPsec encapsulation, disabling NAT traversal")
+		}
+	}
+}
+
 func possiblyLabelGateway(kubeClient kubernetes.Interface, status reporter.Interface) {
 	status.Start("Retrieving the gateway nodes")
 	defer status.End()
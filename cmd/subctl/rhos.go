@@ -20,6 +20,7 @@ limitations under the License.
 package subctl
 
 import (
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/submariner-io/admiral/pkg/reporter"
 	"github.com/submariner-io/subctl/internal/cli"
@@ -28,10 +29,13 @@ import (
 	"github.com/submariner-io/subctl/pkg/cloud/prepare"
 	"github.com/submariner-io/subctl/pkg/cloud/rhos"
 	"github.com/submariner-io/subctl/pkg/cluster"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 var (
-	rhosConfig rhos.Config
+	rhosConfig         rhos.Config
+	rhosRegionGateways map[string]int
+	rhosRegions        []string
 
 	rhosPrepareCmd = &cobra.Command{
 		Use:     "rhos",
@@ -71,13 +75,61 @@ func init() {
 			"OCP metadata.json file (or the directory containing it) from which to read the RHOS infra ID "+
 				"and region from (takes precedence over the specific flags)")
 		command.Flags().StringVar(&rhosConfig.CloudEntry, cloudEntryFlag, "", "Specific cloud configuration to use from the clouds.yaml")
+		command.Flags().StringVar(&rhosConfig.Credentials.AuthURL, "auth-url", "",
+			"OpenStack identity endpoint (bypasses the clouds.yaml, OS_CLOUD and the other OS_* credential variables)")
+		command.Flags().StringVar(&rhosConfig.Credentials.Username, "username", "", "OpenStack user name")
+		command.Flags().StringVar(&rhosConfig.Credentials.Password, "password", "",
+			"OpenStack user password (visible to other users, prefer --password-file or OS_PASSWORD)")
+		command.Flags().StringVar(&rhosConfig.Credentials.PasswordFile, "password-file", "",
+			"file containing the OpenStack user password (defaults to OS_PASSWORD with --username)")
+		command.Flags().StringVar(&rhosConfig.Credentials.ApplicationCredentialID, "application-credential-id", "",
+			"OpenStack application credential ID")
+		command.Flags().StringVar(&rhosConfig.Credentials.ApplicationCredentialSecret, "application-credential-secret", "",
+			"OpenStack application credential secret (visible to other users, prefer --application-credential-secret-file "+
+				"or OS_APPLICATION_CREDENTIAL_SECRET)")
+		command.Flags().StringVar(&rhosConfig.Credentials.ApplicationCredentialSecretFile, "application-credential-secret-file", "",
+			"file containing the OpenStack application credential secret (defaults to OS_APPLICATION_CREDENTIAL_SECRET with "+
+				"--application-credential-id)")
+		command.Flags().StringVar(&rhosConfig.Credentials.ProjectName, "project-name", "", "OpenStack project name to scope to")
+		command.Flags().StringVar(&rhosConfig.Credentials.UserDomainName, "user-domain-name", "", "OpenStack user domain name")
+		command.Flags().StringVar(&rhosConfig.Credentials.ProjectDomainName, "project-domain-name", "",
+			"OpenStack project domain name")
+		command.Flags().StringVar(&rhosConfig.HTTPProxy, "http-proxy", "",
+			"HTTP(S) proxy used to reach the OpenStack API (defaults to the proxy environment variables)")
+		command.Flags().StringVar(&rhosConfig.CACertFile, "ca-cert", "",
+			"PEM bundle of the CAs used to verify the OpenStack API endpoints")
+		command.Flags().BoolVar(&rhosConfig.InsecureSkipVerify, "insecure-skip-tls-verify", false,
+			"Skip the verification of the OpenStack API endpoints' certificates (insecure)")
+		command.Flags().StringVar(&rhosConfig.SecurityGroupName, "security-group", "",
+			"Existing security group to use for the gateways instead of creating one; only the missing rules are added to it")
+		command.Flags().BoolVar(&rhosConfig.ContinueOnError, "continue-on-error", false,
+			"When processing several regions, continue with the remaining regions if one fails")
+		command.Flags().BoolVar(&rhosConfig.Preview, "preview", false,
+			"Only report the changes which would be made to RHOS, listing the existing resources cleanup would delete, without making them")
 	}
 
 	addGeneralRHOSFlags(rhosPrepareCmd)
 	rhosPrepareCmd.Flags().IntVar(&rhosConfig.Gateways, "gateways", defaultNumGateways,
 		"Number of gateways to deploy")
+	rhosPrepareCmd.Flags().StringToIntVar(&rhosRegionGateways, "region-gateways", nil,
+		"comma-separated list of region=count pairs, preparing each region with the given number of gateways, instead of --region")
+	rhosPrepareCmd.Flags().BoolVar(&rhosConfig.PortsOnly, "ports-only", false,
+		"Only open the Submariner ports on the existing gateway nodes, without deploying gateways or labeling workers")
+	rhosPrepareCmd.Flags().IntVar(&rhosConfig.MaxGateways, "max-gateways", defaultMaxGateways,
+		"Maximum number of gateways that may be deployed (0 for no limit)")
+	rhosPrepareCmd.Flags().BoolVar(&rhosConfig.StrictQuota, "strict-quota", false,
+		"Fail instead of warning if the gateways would exceed the project's instance quota")
 	rhosPrepareCmd.Flags().StringVar(&rhosConfig.GWInstanceType, "gateway-instance", "PnTAE.CPU_4_Memory_8192_Disk_50",
 		"Type of gateway instance machine")
+	rhosPrepareCmd.Flags().StringVar(&rhosConfig.AvailabilityZone, "availability-zone", "",
+		"Availability zone in which to deploy the gateway instances (defaults to any zone)")
+	rhosPrepareCmd.Flags().IntVar(&rhosConfig.RootVolumeSizeGB, "root-volume-size", 0,
+		"Size in GB of the root volume from which the gateway instances boot (defaults to the gateway instance type's disk)")
+	rhosPrepareCmd.Flags().StringToStringVar(&rhosConfig.Tags, "tags", nil,
+		"comma-separated list of key=value tags applied to the created RHOS resources, in addition to Submariner's own")
+	rhosPrepareCmd.Flags().StringVar(&rhosConfig.ExternalNetwork, "external-network", "",
+		"Name of the external network from which to assign a floating IP to each dedicated gateway instance "+
+			"(by default, no floating IP is assigned)")
 	rhosPrepareCmd.Flags().BoolVar(&rhosConfig.DedicatedGateway, "dedicated-gateway", true,
 		"Whether a dedicated gateway node has to be deployed")
 
@@ -87,13 +139,43 @@ func init() {
 	cloudPrepareCmd.AddCommand(rhosPrepareCmd)
 
 	addGeneralRHOSFlags(rhosCleanupCmd)
+	rhosCleanupCmd.Flags().StringSliceVar(&rhosRegions, "regions", nil,
+		"comma-separated list of regions to clean up, instead of --region")
+	rhosCleanupCmd.Flags().IntVar(&rhosConfig.MaxParallelRegions, "max-parallel", 1,
+		"maximum number of the --regions to clean up in parallel; each region's output is written once it's complete")
+	rhosCleanupCmd.Flags().BoolVar(&rhosConfig.VerifyCleanup, "verify", true,
+		"check after cleaning up that the gateway instances and Submariner security groups are gone, reporting any which remain")
+	rhosCleanupCmd.Flags().DurationVar(&rhosConfig.VerifyTimeout, "verify-timeout", 0,
+		"wait up to the given duration for the deletions to complete when verifying (0 to check once)")
 	cloudCleanupCmd.AddCommand(rhosCleanupCmd)
 }
 
 func checkRHOSFlags(cmd *cobra.Command, args []string) error {
+	// The regions are processed in a stable order
+	for _, region := range sets.List(sets.KeySet(rhosRegionGateways)) {
+		rhosConfig.Regions = append(rhosConfig.Regions, rhos.RegionGateways{Region: region, Gateways: rhosRegionGateways[region]})
+	}
+
+	for _, region := range rhosRegions {
+		rhosConfig.Regions = append(rhosConfig.Regions, rhos.RegionGateways{Region: region})
+	}
+
+	if rhosConfig.PortsOnly && (cmd.Flags().Changed("gateways") || len(rhosRegionGateways) > 0) {
+		return errors.New("--ports-only doesn't deploy gateways, it can't be combined with --gateways or --region-gateways")
+	}
+
+	if rhosConfig.ExternalNetwork != "" && (rhosConfig.PortsOnly || !rhosConfig.DedicatedGateway) {
+		return errors.New("--external-network only applies to the dedicated gateway instances, it can't be combined with " +
+			"--ports-only or --dedicated-gateway=false")
+	}
+
 	if rhosConfig.OcpMetadataFile == "" {
 		expectFlag(infraIDFlag, rhosConfig.InfraID)
-		expectFlag(regionFlag, rhosConfig.Region)
+
+		if len(rhosConfig.Regions) == 0 {
+			expectFlag(regionFlag, rhosConfig.Region)
+		}
+
 		expectFlag(projectIDFlag, rhosConfig.ProjectID)
 	}
 
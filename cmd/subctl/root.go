@@ -27,6 +27,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/submariner-io/shipyard/test/e2e/framework"
+	"github.com/submariner-io/subctl/internal/cli"
 	"github.com/submariner-io/subctl/internal/exit"
 	"github.com/submariner-io/subctl/pkg/cluster"
 	submarinerv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
@@ -57,6 +58,17 @@ func init() {
 var rootCmd = &cobra.Command{
 	Use:   "subctl",
 	Short: "An installer for Submariner",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return cli.SetFormat(logFormat) //nolint:wrapcheck // No need to wrap here
+	},
+}
+
+var logFormat string
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", cli.FormatText,
+		fmt.Sprintf("format of the progress reports: %q for human-readable text, or %q for structured JSON lines "+
+			"(timestamp, phase, status, message, error)", cli.FormatText, cli.FormatJSON))
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -0,0 +1,69 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/cli"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/internal/exit"
+	"github.com/submariner-io/subctl/internal/restconfig"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	"github.com/submariner-io/subctl/pkg/deploy"
+)
+
+var uninstallBrokerOptions deploy.BrokerUninstallOptions
+
+var uninstallBrokerRestConfigProducer = restconfig.NewProducer().
+	WithDefaultNamespace(constants.DefaultBrokerNamespace)
+
+var uninstallBrokerCmd = &cobra.Command{
+	Use:   "uninstall-broker",
+	Short: "Uninstalls the broker",
+	Long: "This command removes the broker deployed by deploy-broker: the Broker resource, the globalnet configuration, " +
+		"the operator if it isn't used by the cluster's own Submariner components, and the broker namespace",
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.OnError(uninstallBrokerRestConfigProducer.RunOnSelectedContext(uninstallBrokerInContext, cli.NewReporter()))
+	},
+}
+
+func init() {
+	uninstallBrokerCmd.Flags().StringVar(&uninstallBrokerOptions.OperatorNamespace, "operator-namespace", "",
+		fmt.Sprintf("namespace in which the operator was deployed (default %q)", constants.OperatorNamespace))
+	uninstallBrokerCmd.Flags().BoolVar(&uninstallBrokerOptions.Force, "force", false,
+		"uninstall the broker even if clusters are still connected to it")
+	uninstallBrokerRestConfigProducer.SetupFlags(uninstallBrokerCmd.Flags())
+	rootCmd.AddCommand(uninstallBrokerCmd)
+}
+
+func uninstallBrokerInContext(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error {
+	uninstallBrokerOptions.BrokerNamespace = namespace
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	return deploy.UninstallBroker( //nolint:wrapcheck // No need to wrap errors here.
+		ctx, &uninstallBrokerOptions, clusterInfo.ClientProducer, status)
+}
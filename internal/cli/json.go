@@ -0,0 +1,173 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+)
+
+// The output formats of the reporters.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+var (
+	formatMutex sync.Mutex
+	format      = FormatText
+)
+
+// SetFormat selects the output format of the reporters created afterwards.
+func SetFormat(f string) error {
+	if f != FormatText && f != FormatJSON {
+		return fmt.Errorf("unsupported output format %q, expected %q or %q", f, FormatText, FormatJSON)
+	}
+
+	formatMutex.Lock()
+	defer formatMutex.Unlock()
+
+	format = f
+
+	return nil
+}
+
+func currentFormat() string {
+	formatMutex.Lock()
+	defer formatMutex.Unlock()
+
+	return format
+}
+
+// Event is a reporter event, written as a JSON line by the structured reporter.
+type Event struct {
+	Timestamp time.Time `json:"timestamp"`
+	// Phase is the operation in progress, as given to Start.
+	Phase string `json:"phase,omitempty"`
+	// Status is "start" when a phase starts, "success", "warning" or "failure" for the messages reported during a phase,
+	// and for the result of a phase when it ends.
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+const (
+	statusStart   = "start"
+	statusSuccess = "success"
+	statusWarning = "warning"
+	statusFailure = "failure"
+)
+
+// jsonStatus reports structured events, one JSON object per line, for log ingestion.
+type jsonStatus struct {
+	writer io.Writer
+	phase  string
+	result string
+}
+
+func newJSONReporter(writer io.Writer) reporter.Interface {
+	return &jsonStatus{writer: writer}
+}
+
+func (s *jsonStatus) emit(event Event) {
+	event.Timestamp = time.Now().UTC()
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	// A single write per line, so that the events of concurrent reporters aren't interleaved
+	_, _ = s.writer.Write(append(data, '\n'))
+}
+
+func (s *jsonStatus) report(status, message string, args ...interface{}) {
+	if message == "" {
+		return
+	}
+
+	if s.phase != "" && (status == statusFailure || (status == statusWarning && s.result != statusFailure)) {
+		s.result = status
+	}
+
+	s.emit(Event{Phase: s.phase, Status: status, Message: fmt.Sprintf(message, args...)})
+}
+
+func (s *jsonStatus) Start(message string, args ...interface{}) {
+	s.End()
+
+	s.phase = fmt.Sprintf(message, args...)
+	s.result = statusSuccess
+
+	s.emit(Event{Phase: s.phase, Status: statusStart})
+}
+
+func (s *jsonStatus) Success(message string, args ...interface{}) {
+	s.report(statusSuccess, message, args...)
+}
+
+func (s *jsonStatus) Failure(message string, args ...interface{}) {
+	s.report(statusFailure, message, args...)
+}
+
+func (s *jsonStatus) Warning(message string, args ...interface{}) {
+	s.report(statusWarning, message, args...)
+}
+
+func (s *jsonStatus) End() {
+	if s.phase == "" {
+		return
+	}
+
+	s.emit(Event{Phase: s.phase, Status: s.result})
+
+	s.phase = ""
+}
+
+// Error reports the error as a failure of the current phase, with the error in its own field, and ends the phase.
+func (s *jsonStatus) Error(err error, message string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	if message != "" {
+		err = errors.Wrapf(err, message, args...)
+	}
+
+	event := Event{Phase: s.phase, Status: statusFailure, Error: err.Error()}
+	if message != "" {
+		event.Message = fmt.Sprintf(message, args...)
+	}
+
+	s.emit(event)
+
+	if s.phase != "" {
+		s.result = statusFailure
+	}
+
+	s.End()
+
+	return err
+}
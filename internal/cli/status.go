@@ -55,7 +55,17 @@ type status struct {
 }
 
 func NewReporter() reporter.Interface {
-	var writer io.Writer = os.Stderr
+	return NewReporterWithWriter(os.Stderr)
+}
+
+// NewReporterWithWriter returns a reporter writing to the given writer; a loading spinner
+// is only used if the writer is a terminal. If the JSON format is selected, structured events
+// are written instead.
+func NewReporterWithWriter(writer io.Writer) reporter.Interface {
+	if currentFormat() == FormatJSON {
+		return newJSONReporter(writer)
+	}
+
 	if env.IsSmartTerminal(writer) {
 		writer = NewSpinner(writer)
 	}
@@ -23,6 +23,7 @@ const (
 	DefaultBrokerNamespace       = "submariner-k8s-broker"
 	OperatorNamespace            = "submariner-operator"
 	SubmarinerBrokerAdminSA      = "submariner-k8s-broker-admin"
+	SubmarinerBrokerLabel        = "submariner.io/broker"
 	SubmarinerGatewayLabel       = "submariner.io/gateway"
 	SubmarinerNotInstalled       = "No Submariner feature is installed"
 	ConnectivityNotInstalled     = "Submariner connectivity feature is not installed"
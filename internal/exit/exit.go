@@ -25,6 +25,12 @@ import (
 	"github.com/submariner-io/subctl/pkg/version"
 )
 
+// PartialFailureCode is the exit code used when a command completed, but failed on some of its targets.
+const PartialFailureCode = 2
+
+// TimeoutCode is the exit code used when a command failed because it timed out, and may succeed if retried.
+const TimeoutCode = 3
+
 // OnError exits in case of error.
 func OnError(err error) {
 	if err != nil {
@@ -33,6 +39,22 @@ func OnError(err error) {
 	}
 }
 
+// OnPartialFailure exits with PartialFailureCode in case of error.
+func OnPartialFailure(err error) {
+	if err != nil {
+		printVersion()
+		os.Exit(PartialFailureCode)
+	}
+}
+
+// OnTimeout exits with TimeoutCode in case of error.
+func OnTimeout(err error) {
+	if err != nil {
+		printVersion()
+		os.Exit(TimeoutCode)
+	}
+}
+
 // WithMessage will print the message and quit the program with an error code.
 func WithMessage(message string) {
 	fmt.Fprintln(os.Stderr, message)
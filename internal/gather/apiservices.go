@@ -0,0 +1,143 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+)
+
+const apiServicesFileName = "apiservices.yaml"
+
+var apiServicesGVR = schema.GroupVersionResource{Group: "apiregistration.k8s.io", Version: "v1", Resource: "apiservices"}
+
+// The API groups registered for Submariner's own resources.
+var submarinerOwnAPIGroups = sets.New("submariner.io", "multicluster.x-k8s.io")
+
+// gatherAPIServices gathers the APIServices which can affect the operations on Submariner resources: those registering
+// Submariner's API groups, those backed by an aggregated API server in a Submariner namespace, and any which isn't available,
+// since an unavailable aggregated API breaks the discovery on which the clients rely. Their availability is recorded at the
+// top of the file, and the unavailable ones are reported.
+func gatherAPIServices(info *Info) {
+	list, err := info.ClientProducer.ForDynamic().Resource(apiServicesGVR).List(context.TODO(), metav1.ListOptions{})
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return
+	}
+
+	if err != nil {
+		info.Status.Failure("Error listing the APIServices: %v", err)
+		return
+	}
+
+	namespaces := sets.New(info.namespaces...)
+	header := []string{}
+	items := []interface{}{}
+
+	for i := range list.Items {
+		apiService := &list.Items[i]
+		available, reason, message := apiServiceAvailability(apiService)
+		group, _, _ := unstructured.NestedString(apiService.Object, "spec", "group")
+		serviceNamespace, _, _ := unstructured.NestedString(apiService.Object, "spec", "service", "namespace")
+
+		if available && !submarinerOwnAPIGroups.Has(group) && !namespaces.Has(serviceNamespace) {
+			continue
+		}
+
+		if available {
+			header = append(header, fmt.Sprintf("# APIService %q is available", apiService.GetName()))
+		} else {
+			header = append(header, fmt.Sprintf("# APIService %q is NOT available: %s: %s", apiService.GetName(), reason, message))
+			info.Status.Warning("APIService %q isn't available (%s: %s), requests involving its API, including the discovery, "+
+				"can fail", apiService.GetName(), reason, message)
+		}
+
+		if info.redacts(RedactCertificates) {
+			if _, found, _ := unstructured.NestedString(apiService.Object, "spec", "caBundle"); found {
+				_ = unstructured.SetNestedField(apiService.Object, redactedCABundle, "spec", "caBundle")
+			}
+		}
+
+		items = append(items, apiService.Object)
+	}
+
+	info.Status.Success("Found %d APIServices which could affect Submariner resources", len(items))
+
+	if len(items) == 0 {
+		return
+	}
+
+	if err := writeAPIServices(info, header, items); err != nil {
+		info.Status.Failure("Error writing the APIServices: %v", err)
+	}
+}
+
+// apiServiceAvailability returns whether the APIService's Available condition is true, with the condition's reason and
+// message otherwise.
+func apiServiceAvailability(apiService *unstructured.Unstructured) (bool, string, string) {
+	conditions, _, _ := unstructured.NestedSlice(apiService.Object, "status", "conditions")
+
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Available" {
+			continue
+		}
+
+		reason, _ := condition["reason"].(string)
+		message, _ := condition["message"].(string)
+
+		return condition["status"] == string(metav1.ConditionTrue), reason, message
+	}
+
+	return false, "NoCondition", "the Available condition isn't set"
+}
+
+func writeAPIServices(info *Info, header []string, items []interface{}) error {
+	data, err := yaml.Marshal(items)
+	if err != nil {
+		return errors.Wrap(err, "error marshaling to YAML")
+	}
+
+	fileContent := strings.Join(header, "\n") + "\n" + scrubSensitiveData(info, string(data))
+
+	if err := os.WriteFile(filepath.Join(info.DirName, apiServicesFileName), []byte(fileContent), 0o600); err != nil {
+		return errors.Wrapf(err, "error writing to file %s", apiServicesFileName)
+	}
+
+	info.addArtifact(apiServicesFileName, info.redactsAny())
+
+	info.Summary.Resources = append(info.Summary.Resources, ResourceInfo{
+		Name:     "APIServices",
+		Type:     apiServicesGVR.Resource,
+		FileName: apiServicesFileName,
+	})
+
+	return nil
+}
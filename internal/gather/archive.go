@@ -0,0 +1,101 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+const ArchiveExtension = ".tar.gz"
+
+// Archive writes the contents of the given directory to a gzip-compressed tar file, streaming the files
+// one at a time. The archive entries are rooted at the directory's base name.
+func Archive(directory, archiveFile string) (err error) {
+	file, err := os.Create(archiveFile)
+	if err != nil {
+		return errors.Wrapf(err, "error creating archive %q", archiveFile)
+	}
+
+	defer func() {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = errors.Wrapf(closeErr, "error closing archive %q", archiveFile)
+		}
+	}()
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	root := filepath.Dir(filepath.Clean(directory))
+
+	err = filepath.Walk(directory, func(path string, fileInfo os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		return addToArchive(tarWriter, root, path, fileInfo)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "error archiving %q", directory)
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return errors.Wrapf(err, "error finalizing archive %q", archiveFile)
+	}
+
+	return errors.Wrapf(gzipWriter.Close(), "error finalizing archive %q", archiveFile)
+}
+
+func addToArchive(tarWriter *tar.Writer, root, path string, fileInfo os.FileInfo) error {
+	header, err := tar.FileInfoHeader(fileInfo, "")
+	if err != nil {
+		return errors.Wrapf(err, "error creating archive header for %q", path)
+	}
+
+	name, err := filepath.Rel(root, path)
+	if err != nil {
+		return errors.Wrapf(err, "error determining the archive name of %q", path)
+	}
+
+	header.Name = filepath.ToSlash(name)
+
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return errors.Wrapf(err, "error writing archive header for %q", path)
+	}
+
+	if !fileInfo.Mode().IsRegular() {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "error opening %q", path)
+	}
+
+	defer file.Close()
+
+	_, err = io.Copy(tarWriter, file)
+
+	return errors.Wrapf(err, "error archiving %q", path)
+}
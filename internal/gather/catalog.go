@@ -0,0 +1,82 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"sort"
+
+	"github.com/submariner-io/subctl/internal/component"
+)
+
+// Description describes what a gather module or data type collects.
+type Description struct {
+	Name        string
+	Description string
+}
+
+var moduleDescriptions = map[string]string{
+	component.Connectivity: "the gateway, route agent, globalnet and network plugin syncer pods, the Endpoint, Cluster, " +
+		"Gateway and globalnet resources with a readable summary of the Endpoints and connections, the cable driver and network " +
+		"plugin state, and optionally the gateway nodes' datapath rules",
+	component.ServiceDiscovery: "the Lighthouse and CoreDNS pods, the ServiceExports, ServiceImports, EndpointSlices, " +
+		"and the DNS configuration; the DNS artifacts are co-located in a \"dns\" sub-directory",
+	component.Broker: "the Endpoints, Clusters, EndpointSlices and ServiceImports synchronized through the broker",
+	component.Operator: "the operator pod, the Submariner, ServiceDiscovery and component deployment resources, the " +
+		"leader election leases, the NetworkPolicies, admission webhooks and APIServices, with their availability, which could " +
+		"affect them, and the persistent volume claims, volumes, storage classes and volume events in a \"storage\" " +
+		"sub-directory if any volumes are claimed",
+	CNI: "the network plugin's own configuration resources, and a network summary of the pod, service and DNS service " +
+		"addresses and the nodes' interface MTUs",
+	Events: "the events concerning objects in the Submariner namespaces or Submariner resources, as a chronological timeline, " +
+		"with the warnings flagged and also listed separately, in an \"events\" sub-directory",
+	Monitoring: "the ServiceMonitors, PodMonitors and PrometheusRules, when the monitoring CRDs are installed, and the dashboard " +
+		"ConfigMaps in the Submariner namespaces; the monitoring artifacts are co-located in a \"monitoring\" sub-directory",
+}
+
+var typeDescriptions = map[string]string{
+	Logs: "the logs of the component pods, including the previous container instances of the pods which restarted; " +
+		"not constrained by the label selector",
+	Resources: "the Kubernetes resources, restricted to those matching the label selector if given, and the node-level " +
+		"command outputs",
+	Metrics:  "the Prometheus metrics exposed by the component pods; not constrained by the label selector",
+	Diagnose: "the structured results of the diagnose checks which don't schedule pods; not constrained by the label selector",
+}
+
+// Modules returns the descriptions of the available gather modules, sorted by name.
+func Modules() []Description {
+	return describe(moduleDescriptions)
+}
+
+// Types returns the descriptions of the available gather data types, sorted by name.
+func Types() []Description {
+	return describe(typeDescriptions)
+}
+
+func describe(descriptions map[string]string) []Description {
+	result := make([]Description, 0, len(descriptions))
+	for name, description := range descriptions {
+		result = append(result, Description{Name: name, Description: description})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
@@ -0,0 +1,164 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/submariner-io/submariner-operator/pkg/discovery/network"
+	"github.com/submariner-io/submariner/pkg/cni"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const flannelAnnotationPrefix = "flannel.alpha.coreos.com/"
+
+var (
+	calicoIPPools         = schema.GroupVersionResource{Group: "crd.projectcalico.org", Version: "v1", Resource: "ippools"}
+	ocpNetworkConfigs     = schema.GroupVersionResource{Group: "config.openshift.io", Version: "v1", Resource: "networks"}
+	ocpNetworkOperators   = schema.GroupVersionResource{Group: "operator.openshift.io", Version: "v1", Resource: "networks"}
+	ocpSDNHostSubnets     = schema.GroupVersionResource{Group: "network.openshift.io", Version: "v1", Resource: "hostsubnets"}
+	ocpSDNClusterNetworks = schema.GroupVersionResource{Group: "network.openshift.io", Version: "v1", Resource: "clusternetworks"}
+)
+
+//nolint:gocritic // hugeParam: info - purposely passed by value.
+func gatherCNI(dataType string, info Info) bool {
+	switch dataType {
+	case Resources:
+		networkPlugin := detectNetworkPlugin(&info)
+
+		info.Status.Success("Detected network plugin %q", networkPlugin)
+
+		gatherIfServed(&info, ocpNetworkConfigs, corev1.NamespaceAll)
+		gatherIfServed(&info, ocpNetworkOperators, corev1.NamespaceAll)
+		gatherNetworkSummary(&info)
+
+		switch networkPlugin {
+		case cni.Calico:
+			gatherIfServed(&info, calicoIPPools, corev1.NamespaceAll)
+			gatherConfigMapsByName(&info, "calico-config")
+		case cni.OVNKubernetes:
+			gatherConfigMapsByName(&info, "ovnkube-config")
+			gatherConfigMapsByName(&info, "ovn-config")
+		case cni.Flannel, cni.CanalFlannel:
+			gatherConfigMapsByName(&info, "kube-flannel-cfg")
+			gatherConfigMapsByName(&info, "canal-config")
+			gatherFlannelSubnetLeases(&info)
+		case cni.OpenShiftSDN:
+			gatherIfServed(&info, ocpSDNClusterNetworks, corev1.NamespaceAll)
+			gatherIfServed(&info, ocpSDNHostSubnets, corev1.NamespaceAll)
+		default:
+			info.Status.Warning("No CNI-specific data is gathered for network plugin %q", networkPlugin)
+		}
+	default:
+		return false
+	}
+
+	return true
+}
+
+func detectNetworkPlugin(info *Info) string {
+	if info.Submariner != nil && info.Submariner.Status.NetworkPlugin != "" {
+		return info.Submariner.Status.NetworkPlugin
+	}
+
+	clusterNetwork, err := network.Discover(context.TODO(), info.ClientProducer.ForGeneral(), info.OperatorNamespace())
+	if err != nil || clusterNetwork == nil {
+		info.Status.Warning("Unable to detect the network plugin: %v", err)
+		return typeUnknown
+	}
+
+	return clusterNetwork.NetworkPlugin
+}
+
+// isResourceServed returns true if the API server serves the given resource; this is used
+// to skip resources whose CRDs aren't installed.
+func isResourceServed(info *Info, ofType schema.GroupVersionResource) bool {
+	resources, err := info.ClientProducer.ForKubernetes().Discovery().ServerResourcesForGroupVersion(ofType.GroupVersion().String())
+	if err != nil {
+		return false
+	}
+
+	for i := range resources.APIResources {
+		if resources.APIResources[i].Name == ofType.Resource {
+			return true
+		}
+	}
+
+	return false
+}
+
+func gatherIfServed(info *Info, ofType schema.GroupVersionResource, namespace string) {
+	if isResourceServed(info, ofType) {
+		ResourcesToYAMLFile(info, ofType, namespace, metav1.ListOptions{})
+	}
+}
+
+func gatherConfigMapsByName(info *Info, name string) {
+	gatherConfigMaps(info, corev1.NamespaceAll, metav1.ListOptions{FieldSelector: fields.Set(map[string]string{
+		"metadata.name": name,
+	}).String()})
+}
+
+// gatherFlannelSubnetLeases records the subnet leases which flannel stores on the nodes, when using the Kubernetes subnet manager.
+func gatherFlannelSubnetLeases(info *Info) {
+	nodes, err := listNodes(info, metav1.ListOptions{})
+	if err != nil {
+		info.Status.Failure("Failed to gather the flannel subnet leases: %s", err)
+		return
+	}
+
+	var leases strings.Builder
+
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+
+		fmt.Fprintf(&leases, "%s podCIDR=%s", node.Name, node.Spec.PodCIDR)
+
+		keys := make([]string, 0, len(node.Annotations))
+
+		for key := range node.Annotations {
+			if strings.HasPrefix(key, flannelAnnotationPrefix) {
+				keys = append(keys, key)
+			}
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			fmt.Fprintf(&leases, " %s=%s", strings.TrimPrefix(key, flannelAnnotationPrefix), node.Annotations[key])
+		}
+
+		leases.WriteString("\n")
+	}
+
+	fileName, err := writeLogToFile(leases.String(), "flannel-subnet-leases", info, ".log")
+	if err != nil {
+		info.Status.Failure("Error writing the flannel subnet leases: %s", err)
+		return
+	}
+
+	info.addArtifact(fileName, false)
+	info.Status.Success("Found subnet leases for %d nodes", len(nodes.Items))
+}
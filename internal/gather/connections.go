@@ -0,0 +1,208 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	submarinerv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const connectionSummaryFileName = "connections-summary.json"
+
+// ConnectionSummary is a readable digest of the Endpoints and of the Gateways' connections, written alongside the raw
+// resources.
+type ConnectionSummary struct {
+	Endpoints   []EndpointDetails   `json:"endpoints"`
+	Connections []ConnectionDetails `json:"connections"`
+}
+
+// EndpointDetails are the fields of an Endpoint relevant to connectivity, with its backend configuration decoded.
+type EndpointDetails struct {
+	Name              string   `json:"name"`
+	ClusterID         string   `json:"clusterID"`
+	Hostname          string   `json:"hostname"`
+	Backend           string   `json:"backend"`
+	PublicIP          string   `json:"publicIP"`
+	PrivateIP         string   `json:"privateIP"`
+	HealthCheckIP     string   `json:"healthCheckIP,omitempty"`
+	NATEnabled        bool     `json:"natEnabled"`
+	Subnets           []string `json:"subnets"`
+	UDPPort           string   `json:"udpPort,omitempty"`
+	NATTDiscoveryPort string   `json:"nattDiscoveryPort,omitempty"`
+	// PublicIPSource is how the public IP is resolved, e.g. "ipv4", "lb", "api" or "dns", and PublicIPSourceValue its argument.
+	PublicIPSource      string `json:"publicIPSource,omitempty"`
+	PublicIPSourceValue string `json:"publicIPSourceValue,omitempty"`
+	UsingLoadBalancer   bool   `json:"usingLoadBalancer,omitempty"`
+	PreferredServer     bool   `json:"preferredServer,omitempty"`
+	// BackendConfigErrors lists the backend configuration values which couldn't be decoded.
+	BackendConfigErrors []string `json:"backendConfigErrors,omitempty"`
+}
+
+// ConnectionDetails describe a connection from a gateway to a remote cluster.
+type ConnectionDetails struct {
+	Gateway         string `json:"gateway"`
+	HAStatus        string `json:"haStatus"`
+	LocalClusterID  string `json:"localClusterID"`
+	RemoteClusterID string `json:"remoteClusterID"`
+	RemoteHostname  string `json:"remoteHostname"`
+	Status          string `json:"status"`
+	StatusMessage   string `json:"statusMessage,omitempty"`
+	Backend         string `json:"backend"`
+	UsingIP         string `json:"usingIP"`
+	UsingNAT        bool   `json:"usingNAT"`
+	RemotePublicIP  string `json:"remotePublicIP"`
+	RemotePrivateIP string `json:"remotePrivateIP"`
+	AverageRTT      string `json:"averageRTT,omitempty"`
+}
+
+// gatherConnectionSummary writes a readable summary of the Endpoints and the Gateways' connections, sparing the decoding of
+// the raw resources when triaging connection problems.
+func gatherConnectionSummary(info *Info) {
+	summary := ConnectionSummary{Endpoints: []EndpointDetails{}, Connections: []ConnectionDetails{}}
+
+	for _, namespace := range info.namespaces {
+		if err := summarizeEndpoints(info, namespace, &summary); err != nil {
+			info.Status.Failure("Error summarizing the Endpoints: %v", err)
+		}
+
+		if err := summarizeConnections(info, namespace, &summary); err != nil {
+			info.Status.Failure("Error summarizing the Gateway connections: %v", err)
+		}
+	}
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		info.Status.Failure("Error marshalling the connection summary: %v", err)
+		return
+	}
+
+	fileName := filepath.Join(info.subDir, connectionSummaryFileName)
+
+	err = os.WriteFile(filepath.Join(info.DirName, fileName), []byte(scrubSensitiveData(info, string(data))), 0o600)
+	if err != nil {
+		info.Status.Failure("Error writing the connection summary to %q: %v", fileName, err)
+		return
+	}
+
+	info.addArtifact(fileName, info.redactsAny())
+	info.Status.Success("Summarized %d Endpoints and %d connections in %q", len(summary.Endpoints), len(summary.Connections),
+		fileName)
+}
+
+func summarizeEndpoints(info *Info, namespace string, summary *ConnectionSummary) error {
+	endpoints := &submarinerv1.EndpointList{}
+
+	err := info.ClientProducer.ForGeneral().List(context.TODO(), endpoints, controllerClient.InNamespace(namespace))
+	if err != nil {
+		return errors.Wrapf(err, "error listing the Endpoints in namespace %q", namespace)
+	}
+
+	for i := range endpoints.Items {
+		summary.Endpoints = append(summary.Endpoints, endpointDetails(endpoints.Items[i].Name, &endpoints.Items[i].Spec))
+	}
+
+	return nil
+}
+
+func endpointDetails(name string, spec *submarinerv1.EndpointSpec) EndpointDetails {
+	details := EndpointDetails{
+		Name:              name,
+		ClusterID:         spec.ClusterID,
+		Hostname:          spec.Hostname,
+		Backend:           spec.Backend,
+		PublicIP:          spec.PublicIP,
+		PrivateIP:         spec.PrivateIP,
+		HealthCheckIP:     spec.HealthCheckIP,
+		NATEnabled:        spec.NATEnabled,
+		Subnets:           spec.Subnets,
+		UDPPort:           spec.BackendConfig[submarinerv1.UDPPortConfig],
+		NATTDiscoveryPort: spec.BackendConfig[submarinerv1.NATTDiscoveryPortConfig],
+	}
+
+	for _, configName := range []string{submarinerv1.UDPPortConfig, submarinerv1.NATTDiscoveryPortConfig} {
+		if _, err := spec.GetBackendPort(configName, 0); err != nil {
+			details.BackendConfigErrors = append(details.BackendConfigErrors, err.Error())
+		}
+	}
+
+	if publicIP := spec.BackendConfig[submarinerv1.PublicIP]; publicIP != "" {
+		details.PublicIPSource, details.PublicIPSourceValue, _ = strings.Cut(publicIP, ":")
+	}
+
+	details.UsingLoadBalancer = backendBool(spec, submarinerv1.UsingLoadBalancer, &details)
+	details.PreferredServer = backendBool(spec, submarinerv1.PreferredServerConfig, &details)
+
+	return details
+}
+
+func backendBool(spec *submarinerv1.EndpointSpec, configName string, details *EndpointDetails) bool {
+	value, err := spec.GetBackendBool(configName, nil)
+	if err != nil {
+		details.BackendConfigErrors = append(details.BackendConfigErrors, err.Error())
+	}
+
+	return value != nil && *value
+}
+
+func summarizeConnections(info *Info, namespace string, summary *ConnectionSummary) error {
+	gateways := &submarinerv1.GatewayList{}
+
+	err := info.ClientProducer.ForGeneral().List(context.TODO(), gateways, controllerClient.InNamespace(namespace))
+	if err != nil {
+		return errors.Wrapf(err, "error listing the Gateways in namespace %q", namespace)
+	}
+
+	for i := range gateways.Items {
+		status := &gateways.Items[i].Status
+
+		for j := range status.Connections {
+			connection := &status.Connections[j]
+
+			details := ConnectionDetails{
+				Gateway:         gateways.Items[i].Name,
+				HAStatus:        string(status.HAStatus),
+				LocalClusterID:  status.LocalEndpoint.ClusterID,
+				RemoteClusterID: connection.Endpoint.ClusterID,
+				RemoteHostname:  connection.Endpoint.Hostname,
+				Status:          string(connection.Status),
+				StatusMessage:   connection.StatusMessage,
+				Backend:         connection.Endpoint.Backend,
+				UsingIP:         connection.UsingIP,
+				UsingNAT:        connection.UsingNAT,
+				RemotePublicIP:  connection.Endpoint.PublicIP,
+				RemotePrivateIP: connection.Endpoint.PrivateIP,
+			}
+
+			if connection.LatencyRTT != nil {
+				details.AverageRTT = connection.LatencyRTT.Average
+			}
+
+			summary.Connections = append(summary.Connections, details)
+		}
+	}
+
+	return nil
+}
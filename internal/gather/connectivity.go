@@ -32,6 +32,8 @@ const (
 	addonPodLabel               = "app=submariner-addon"
 	ovnMasterPodLabelOCP        = "app=ovnkube-master"
 	ovnMasterPodLabelGeneric    = "name=ovnkube-master"
+	ovnNodePodLabelOCP          = "app=ovnkube-node"
+	ovnNodePodLabelGeneric      = "name=ovnkube-node"
 )
 
 func gatherGatewayPodLogs(info *Info) {
@@ -0,0 +1,77 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"path/filepath"
+
+	"github.com/submariner-io/admiral/pkg/reporter"
+)
+
+// ModuleCounts summarizes the data gathered for a module and type.
+type ModuleCounts struct {
+	Module    string
+	Type      string
+	Files     int
+	Bytes     int64
+	PodLogs   int
+	Resources int
+}
+
+// recordCounts records the counts for the module and type being gathered, from the artifacts and pod logs added
+// since the given positions in the summary.
+func (info *Info) recordCounts(artifactsBefore, podLogsBefore int) {
+	counts := ModuleCounts{
+		Module:  info.module,
+		Type:    info.dataType,
+		PodLogs: len(info.Summary.PodLogs) - podLogsBefore,
+	}
+
+	for _, artifact := range info.Summary.Artifacts[artifactsBefore:] {
+		counts.Files++
+		counts.Bytes += artifact.Size
+
+		if filepath.Ext(artifact.FileName) == ".yaml" {
+			counts.Resources++
+		}
+	}
+
+	info.Summary.Counts = append(info.Summary.Counts, counts)
+}
+
+func reportCounts(info *Info, status reporter.Interface) {
+	status.Start("Summary of the data gathered from cluster %q", info.ClusterName)
+	defer status.End()
+
+	status.Success("%-18s %-10s %6s %12s %9s %10s", "MODULE", "TYPE", "FILES", "BYTES", "POD LOGS", "RESOURCES")
+
+	for i := range info.Summary.Counts {
+		counts := &info.Summary.Counts[i]
+
+		status.Success("%-18s %-10s %6d %12d %9d %10d", counts.Module, counts.Type, counts.Files, counts.Bytes,
+			counts.PodLogs, counts.Resources)
+	}
+
+	for i := range info.Summary.Counts {
+		if info.Summary.Counts[i].Files == 0 {
+			status.Warning("No files were gathered for %s %s, the component may not be deployed", info.Summary.Counts[i].Module,
+				info.Summary.Counts[i].Type)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	lhconstants "github.com/submariner-io/lighthouse/pkg/constants"
+	"github.com/submariner-io/subctl/internal/gvr"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	submarinerv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+	mcsv1a1 "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+)
+
+const crossReferenceFileName = "broker-cross-reference.json"
+
+var (
+	serviceExportsGVR = gvr.FromMetaGroupVersion(mcsv1a1.GroupVersion, "serviceexports")
+	serviceImportsGVR = gvr.FromMetaGroupVersion(mcsv1a1.GroupVersion, "serviceimports")
+)
+
+// BrokerCrossReference correlates the resources synchronized through the broker with each cluster's own view.
+type BrokerCrossReference struct {
+	BrokerCluster string                   `json:"brokerCluster"`
+	Clusters      []ClusterCrossReference  `json:"clusters"`
+	Mismatches    []CrossReferenceMismatch `json:"mismatches"`
+}
+
+// ClusterCrossReference records what the broker holds for a cluster, and what the cluster exports and imports.
+type ClusterCrossReference struct {
+	Cluster            string   `json:"cluster"`
+	ClusterID          string   `json:"clusterID"`
+	BrokerNamespace    string   `json:"brokerNamespace"`
+	RegisteredOnBroker bool     `json:"registeredOnBroker"`
+	BrokerEndpoints    []string `json:"brokerEndpoints"`
+	ServiceExports     []string `json:"serviceExports"`
+	BrokerImports      []string `json:"brokerImports"`
+	ServiceImports     []string `json:"serviceImports"`
+}
+
+// CrossReferenceMismatch is a resource present on one side, the broker or a cluster, but missing on the other.
+type CrossReferenceMismatch struct {
+	Cluster     string `json:"cluster"`
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// brokerResources are the resources held in a broker namespace.
+type brokerResources struct {
+	clusters       []submarinerv1.Cluster
+	endpoints      []submarinerv1.Endpoint
+	serviceImports []unstructured.Unstructured
+}
+
+// CrossReferenceBroker correlates the Clusters, Endpoints and ServiceImports on the broker cluster with the Endpoints,
+// ServiceExports and ServiceImports of each of the given clusters, and writes the result, including the mismatches, to the
+// cross-reference file in the gather directory. The broker namespace of each cluster is taken from its Submariner or
+// ServiceDiscovery resource.
+func CrossReferenceBroker(brokerInfo *cluster.Info, clusterInfos []*cluster.Info, directory string, status reporter.Interface) error {
+	status.Start("Cross-referencing the broker resources on cluster %q with the clusters", brokerInfo.Name)
+	defer status.End()
+
+	crossReference := BrokerCrossReference{
+		BrokerCluster: brokerInfo.Name,
+		Clusters:      []ClusterCrossReference{},
+		Mismatches:    []CrossReferenceMismatch{},
+	}
+	brokerNamespaces := map[string]*brokerResources{}
+
+	for _, clusterInfo := range clusterInfos {
+		clusterID, brokerNamespace := brokerSettings(clusterInfo)
+		if clusterID == "" {
+			status.Warning("Submariner isn't installed on cluster %q, it can't be cross-referenced", clusterInfo.Name)
+			continue
+		}
+
+		resources, found := brokerNamespaces[brokerNamespace]
+		if !found {
+			var err error
+
+			resources, err = listBrokerResources(brokerInfo, brokerNamespace)
+			if err != nil {
+				return status.Error(err, "Error listing the broker resources in namespace %q", brokerNamespace)
+			}
+
+			brokerNamespaces[brokerNamespace] = resources
+		}
+
+		reference, mismatches, err := crossReferenceCluster(clusterInfo, clusterID, brokerNamespace, resources)
+		if err != nil {
+			return status.Error(err, "Error cross-referencing cluster %q", clusterInfo.Name)
+		}
+
+		crossReference.Clusters = append(crossReference.Clusters, reference)
+		crossReference.Mismatches = append(crossReference.Mismatches, mismatches...)
+	}
+
+	for i := range crossReference.Mismatches {
+		mismatch := &crossReference.Mismatches[i]
+		status.Warning("Cluster %q, %s %q: %s", mismatch.Cluster, mismatch.Kind, mismatch.Name, mismatch.Description)
+	}
+
+	data, err := json.MarshalIndent(crossReference, "", "  ")
+	if err != nil {
+		return status.Error(err, "Error marshalling the broker cross-reference")
+	}
+
+	fileName := filepath.Join(directory, crossReferenceFileName)
+
+	if err := os.WriteFile(fileName, data, 0o600); err != nil {
+		return status.Error(err, "Error writing the broker cross-reference to %q", fileName)
+	}
+
+	status.Success("Cross-referenced %d cluster(s), finding %d mismatch(es), in %q", len(crossReference.Clusters),
+		len(crossReference.Mismatches), fileName)
+
+	return nil
+}
+
+func brokerSettings(clusterInfo *cluster.Info) (string, string) {
+	if clusterInfo.Submariner != nil {
+		return clusterInfo.Submariner.Spec.ClusterID, clusterInfo.Submariner.Spec.BrokerK8sRemoteNamespace
+	}
+
+	if clusterInfo.ServiceDiscovery != nil {
+		return clusterInfo.ServiceDiscovery.Spec.ClusterID, clusterInfo.ServiceDiscovery.Spec.BrokerK8sRemoteNamespace
+	}
+
+	return "", ""
+}
+
+func listBrokerResources(brokerInfo *cluster.Info, namespace string) (*brokerResources, error) {
+	clusters := &submarinerv1.ClusterList{}
+
+	err := brokerInfo.ClientProducer.ForGeneral().List(context.TODO(), clusters, controllerClient.InNamespace(namespace))
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the Clusters")
+	}
+
+	endpoints := &submarinerv1.EndpointList{}
+
+	err = brokerInfo.ClientProducer.ForGeneral().List(context.TODO(), endpoints, controllerClient.InNamespace(namespace))
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the Endpoints")
+	}
+
+	serviceImports, err := brokerInfo.ClientProducer.ForDynamic().Resource(serviceImportsGVR).Namespace(namespace).List(
+		context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the ServiceImports")
+	}
+
+	return &brokerResources{clusters: clusters.Items, endpoints: endpoints.Items, serviceImports: serviceImports.Items}, nil
+}
+
+func crossReferenceCluster(clusterInfo *cluster.Info, clusterID, brokerNamespace string, resources *brokerResources,
+) (ClusterCrossReference, []CrossReferenceMismatch, error) {
+	reference := ClusterCrossReference{
+		Cluster:         clusterInfo.Name,
+		ClusterID:       clusterID,
+		BrokerNamespace: brokerNamespace,
+		BrokerEndpoints: []string{},
+		ServiceExports:  []string{},
+		BrokerImports:   []string{},
+		ServiceImports:  []string{},
+	}
+	mismatches := []CrossReferenceMismatch{}
+
+	mismatch := func(kind, name, format string, args ...interface{}) {
+		mismatches = append(mismatches, CrossReferenceMismatch{
+			Cluster: clusterInfo.Name, Kind: kind, Name: name, Description: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if clusterInfo.Submariner != nil {
+		for i := range resources.clusters {
+			if resources.clusters[i].Spec.ClusterID == clusterID {
+				reference.RegisteredOnBroker = true
+			}
+		}
+
+		if !reference.RegisteredOnBroker {
+			mismatch("Cluster", clusterID, "the cluster isn't registered on the broker")
+		}
+
+		localCables, err := endpointCables(clusterInfo)
+		if err != nil {
+			return reference, nil, err
+		}
+
+		for i := range resources.endpoints {
+			spec := &resources.endpoints[i].Spec
+
+			if spec.ClusterID == clusterID {
+				reference.BrokerEndpoints = append(reference.BrokerEndpoints, spec.CableName)
+			}
+
+			if !localCables.Has(spec.CableName) {
+				mismatch("Endpoint", spec.CableName, "the Endpoint of cluster %q is on the broker but not on the cluster", spec.ClusterID)
+			}
+		}
+	}
+
+	if clusterInfo.ServiceDiscovery != nil {
+		if err := crossReferenceServices(clusterInfo, clusterID, resources, &reference, mismatch); err != nil {
+			return reference, nil, err
+		}
+	}
+
+	return reference, mismatches, nil
+}
+
+// endpointCables returns the cable names of the Endpoints known to the given cluster.
+func endpointCables(clusterInfo *cluster.Info) (sets.Set[string], error) {
+	endpoints := &submarinerv1.EndpointList{}
+
+	err := clusterInfo.ClientProducer.ForGeneral().List(context.TODO(), endpoints,
+		controllerClient.InNamespace(clusterInfo.Submariner.Namespace))
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the Endpoints")
+	}
+
+	cables := sets.New[string]()
+	for i := range endpoints.Items {
+		cables.Insert(endpoints.Items[i].Spec.CableName)
+	}
+
+	return cables, nil
+}
+
+func crossReferenceServices(clusterInfo *cluster.Info, clusterID string, resources *brokerResources,
+	reference *ClusterCrossReference, mismatch func(kind, name, format string, args ...interface{}),
+) error {
+	serviceExports, err := clusterInfo.ClientProducer.ForDynamic().Resource(serviceExportsGVR).Namespace(corev1.NamespaceAll).List(
+		context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "error listing the ServiceExports")
+	}
+
+	serviceImports, err := clusterInfo.ClientProducer.ForDynamic().Resource(serviceImportsGVR).
+		Namespace(clusterInfo.ServiceDiscovery.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "error listing the ServiceImports")
+	}
+
+	exported := sets.New[string]()
+
+	for i := range serviceExports.Items {
+		name := serviceExports.Items[i].GetNamespace() + "/" + serviceExports.Items[i].GetName()
+		exported.Insert(name)
+		reference.ServiceExports = append(reference.ServiceExports, name)
+	}
+
+	imported := sets.New[string]()
+
+	for i := range serviceImports.Items {
+		imported.Insert(serviceImports.Items[i].GetName())
+		reference.ServiceImports = append(reference.ServiceImports, serviceImports.Items[i].GetName())
+	}
+
+	exportedToBroker := sets.New[string]()
+
+	for i := range resources.serviceImports {
+		serviceImport := &resources.serviceImports[i]
+		labels := serviceImport.GetLabels()
+
+		if labels[lhconstants.MCSLabelSourceCluster] == clusterID {
+			service := labels[lhconstants.LabelSourceNamespace] + "/" + labels[mcsv1a1.LabelServiceName]
+			exportedToBroker.Insert(service)
+			reference.BrokerImports = append(reference.BrokerImports, serviceImport.GetName())
+
+			if !exported.Has(service) {
+				mismatch("ServiceImport", serviceImport.GetName(),
+					"the service %q is imported on the broker from the cluster, which doesn't export it", service)
+			}
+		}
+
+		if !imported.Has(serviceImport.GetName()) {
+			mismatch("ServiceImport", serviceImport.GetName(), "the ServiceImport from cluster %q is on the broker but not on the cluster",
+				labels[lhconstants.MCSLabelSourceCluster])
+		}
+	}
+
+	for _, service := range sets.List(exported.Difference(exportedToBroker)) {
+		mismatch("ServiceExport", service, "the service is exported by the cluster, but isn't imported on the broker")
+	}
+
+	return nil
+}
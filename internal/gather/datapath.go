@@ -0,0 +1,96 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"context"
+
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/internal/pods"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// The diagnostic pod sleeps while the commands are executed in it, it is deleted as soon as they complete.
+const datapathPodCommand = "sleep 600"
+
+var datapathCmds = map[string]string{
+	"iptables-save":            "iptables-save",
+	"iptables-save-submariner": "iptables-save | grep -i submariner",
+}
+
+// These commands aren't available everywhere, their failures are ignored.
+var optionalDatapathCmds = map[string]string{
+	"ip6tables-save": "ip6tables-save",
+	"nft-ruleset":    "nft list ruleset",
+}
+
+// gatherGatewayNodeDatapath schedules a diagnostic pod on each gateway node to dump its packet filtering rules.
+func gatherGatewayNodeDatapath(info *Info) {
+	selector := labels.SelectorFromSet(map[string]string{constants.SubmarinerGatewayLabel: constants.TrueLabel})
+
+	nodes, err := info.ClientProducer.ForKubernetes().CoreV1().Nodes().List(context.TODO(),
+		metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		info.Status.Failure("Error listing the gateway nodes: %v", err)
+		return
+	}
+
+	repositoryInfo, err := info.GetImageRepositoryInfo()
+	if err != nil {
+		info.Status.Failure("Error determining the repository information: %v", err)
+		return
+	}
+
+	info.Status.Success("Dumping the datapath rules from %d gateway nodes", len(nodes.Items))
+
+	for i := range nodes.Items {
+		nodeName := nodes.Items[i].Name
+
+		scheduled, err := pods.Schedule(&pods.Config{
+			Name:      "gather-datapath",
+			ClientSet: info.ClientProducer.ForKubernetes(),
+			Scheduling: pods.Scheduling{
+				ScheduleOn: pods.CustomNode, NodeName: nodeName,
+				Networking: pods.HostNetworking,
+			},
+			Namespace:           info.OperatorNamespace(),
+			Command:             datapathPodCommand,
+			ImageRepositoryInfo: *repositoryInfo,
+		})
+		if err != nil {
+			info.Status.Failure("Error scheduling the diagnostic pod on node %q: %v", nodeName, err)
+			continue
+		}
+
+		dumpDatapath(info, scheduled)
+	}
+}
+
+func dumpDatapath(info *Info, scheduled *pods.Scheduled) {
+	defer scheduled.Delete()
+
+	for name, cmd := range datapathCmds {
+		logCmdOutput(info, scheduled.Pod, cmd, name, false)
+	}
+
+	for name, cmd := range optionalDatapathCmds {
+		logCmdOutput(info, scheduled.Pod, cmd, name, true)
+	}
+}
@@ -0,0 +1,174 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/component"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	"github.com/submariner-io/subctl/pkg/diagnose"
+)
+
+type diagnoseCheck struct {
+	name string
+	run  func(*cluster.Info, string, reporter.Interface) error
+}
+
+// The diagnose checks run for each module; those which schedule pods on the cluster aren't included.
+var diagnoseChecks = map[string][]diagnoseCheck{
+	component.Connectivity: {
+		{name: "k8s-version", run: diagnose.K8sVersion},
+		{name: "cni", run: diagnose.CNIConfig},
+		{name: "connections", run: diagnose.Connections},
+		{name: "globalnet", run: diagnose.GlobalnetConfig},
+	},
+	component.ServiceDiscovery: {
+		{name: "service-discovery", run: diagnose.ServiceDiscovery},
+	},
+	component.Operator: {
+		{name: "deployments", run: diagnose.Deployments},
+	},
+}
+
+// DiagnoseStep is an operation performed by a diagnose check, with the outcomes it reported.
+type DiagnoseStep struct {
+	Description string   `json:"description"`
+	Successes   []string `json:"successes,omitempty"`
+	Warnings    []string `json:"warnings,omitempty"`
+	Failures    []string `json:"failures,omitempty"`
+}
+
+// DiagnoseResult is the structured result of a diagnose check.
+type DiagnoseResult struct {
+	Check  string         `json:"check"`
+	Passed bool           `json:"passed"`
+	Error  string         `json:"error,omitempty"`
+	Steps  []DiagnoseStep `json:"steps"`
+}
+
+// diagnoseRecorder records what a diagnose check reports, instead of displaying it.
+type diagnoseRecorder struct {
+	result *DiagnoseResult
+}
+
+func (r *diagnoseRecorder) current() *DiagnoseStep {
+	if len(r.result.Steps) == 0 {
+		r.result.Steps = append(r.result.Steps, DiagnoseStep{})
+	}
+
+	return &r.result.Steps[len(r.result.Steps)-1]
+}
+
+func (r *diagnoseRecorder) Start(message string, args ...interface{}) {
+	r.result.Steps = append(r.result.Steps, DiagnoseStep{Description: fmt.Sprintf(message, args...)})
+}
+
+func (r *diagnoseRecorder) Success(message string, args ...interface{}) {
+	if message != "" {
+		r.current().Successes = append(r.current().Successes, fmt.Sprintf(message, args...))
+	}
+}
+
+func (r *diagnoseRecorder) Failure(message string, args ...interface{}) {
+	if message != "" {
+		r.current().Failures = append(r.current().Failures, fmt.Sprintf(message, args...))
+	}
+}
+
+func (r *diagnoseRecorder) Warning(message string, args ...interface{}) {
+	if message != "" {
+		r.current().Warnings = append(r.current().Warnings, fmt.Sprintf(message, args...))
+	}
+}
+
+func (r *diagnoseRecorder) End() {
+}
+
+// gatherDiagnoseResults runs the module's diagnose checks and saves their results. A failing check is recorded, and
+// the remaining checks still run.
+func gatherDiagnoseResults(info *Info, module string) bool {
+	checks, found := diagnoseChecks[module]
+	if !found {
+		return false
+	}
+
+	results := make([]DiagnoseResult, 0, len(checks))
+
+	for _, check := range checks {
+		result := runDiagnoseCheck(info, check)
+
+		if result.Passed {
+			info.Status.Success("The %q diagnose check passed", check.name)
+		} else {
+			info.Status.Warning("The %q diagnose check failed", check.name)
+		}
+
+		results = append(results, result)
+	}
+
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		info.Status.Failure("Error marshalling the diagnose results: %v", err)
+		return true
+	}
+
+	fileName := filepath.Join(info.subDir, escapeFileName("diagnose_"+module)+".json")
+
+	err = os.WriteFile(filepath.Join(info.DirName, fileName), []byte(scrubSensitiveData(info, string(data))), 0o600)
+	if err != nil {
+		info.Status.Failure("Error writing the diagnose results to %q: %v", fileName, err)
+		return true
+	}
+
+	info.addArtifact(fileName, info.redactsAny())
+
+	return true
+}
+
+func runDiagnoseCheck(info *Info, check diagnoseCheck) (result DiagnoseResult) {
+	result = DiagnoseResult{Check: check.name, Steps: []DiagnoseStep{}}
+	recorder := &diagnoseRecorder{result: &result}
+
+	defer func() {
+		if r := recover(); r != nil {
+			result.Passed = false
+			result.Error = fmt.Sprintf("the check panicked: %v", r)
+		}
+	}()
+
+	err := check.run(&info.Info, info.OperatorNamespace(), &reporter.Adapter{Basic: recorder})
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	result.Passed = err == nil
+
+	for i := range result.Steps {
+		if len(result.Steps[i].Failures) > 0 {
+			result.Passed = false
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,261 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// ObjectRef identifies a gathered resource.
+type ObjectRef struct {
+	Cluster   string `json:"cluster"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// ObjectChange describes a resource present in both gather runs whose content differs.
+type ObjectChange struct {
+	ObjectRef
+	// Fields are the paths of the fields which were added, removed or modified, e.g. "spec.cableName".
+	Fields []string `json:"fields"`
+}
+
+// DiffResult lists the differences between the resources gathered in two runs.
+type DiffResult struct {
+	Added    []ObjectRef    `json:"added"`
+	Removed  []ObjectRef    `json:"removed"`
+	Modified []ObjectChange `json:"modified"`
+}
+
+// volatileFields change without any meaningful change to the resources, they're ignored when comparing.
+var volatileFields = [][]string{
+	{"metadata", "resourceVersion"},
+	{"metadata", "managedFields"},
+}
+
+// volatileConditionFields are ignored in each of the resources' status conditions.
+var volatileConditionFields = []string{"lastTransitionTime", "lastHeartbeatTime", "lastUpdateTime"}
+
+// Diff compares the resources gathered in the before and after directories, as written by the gather command.
+func Diff(beforeDir, afterDir string) (*DiffResult, error) {
+	before, err := loadGatheredObjects(beforeDir)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := loadGatheredObjects(afterDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DiffResult{
+		Added:    []ObjectRef{},
+		Removed:  []ObjectRef{},
+		Modified: []ObjectChange{},
+	}
+
+	for ref, afterObj := range after {
+		beforeObj, found := before[ref]
+		if !found {
+			result.Added = append(result.Added, ref)
+			continue
+		}
+
+		if fields := diffFields("", beforeObj, afterObj); len(fields) > 0 {
+			result.Modified = append(result.Modified, ObjectChange{ObjectRef: ref, Fields: fields})
+		}
+	}
+
+	for ref := range before {
+		if _, found := after[ref]; !found {
+			result.Removed = append(result.Removed, ref)
+		}
+	}
+
+	sortRefs(result.Added)
+	sortRefs(result.Removed)
+	sort.Slice(result.Modified, func(i, j int) bool {
+		return refLess(&result.Modified[i].ObjectRef, &result.Modified[j].ObjectRef)
+	})
+
+	return result, nil
+}
+
+// IsEmpty returns true if no differences were found.
+func (d *DiffResult) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// Write writes a human-readable description of the differences.
+func (d *DiffResult) Write(w io.Writer) {
+	if d.IsEmpty() {
+		fmt.Fprintln(w, "No differences found")
+		return
+	}
+
+	for i := range d.Added {
+		fmt.Fprintf(w, "+ %s\n", d.Added[i].String())
+	}
+
+	for i := range d.Removed {
+		fmt.Fprintf(w, "- %s\n", d.Removed[i].String())
+	}
+
+	for i := range d.Modified {
+		fmt.Fprintf(w, "~ %s: %s\n", d.Modified[i].ObjectRef.String(), strings.Join(d.Modified[i].Fields, ", "))
+	}
+}
+
+func (r ObjectRef) String() string {
+	name := r.Name
+	if r.Namespace != "" {
+		name = r.Namespace + "/" + r.Name
+	}
+
+	return fmt.Sprintf("[%s] %s %s", r.Cluster, r.Kind, name)
+}
+
+// loadGatheredObjects reads the resources gathered in the given directory, keyed by cluster and identity.
+func loadGatheredObjects(dir string) (map[ObjectRef]map[string]interface{}, error) {
+	objects := map[ObjectRef]map[string]interface{}{}
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() || filepath.Ext(path) != ".yaml" {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "error reading %q", path)
+		}
+
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal(data, &obj.Object); err != nil {
+			return errors.Wrapf(err, "error parsing %q", path)
+		}
+
+		if obj.GetKind() == "" || obj.GetName() == "" {
+			return nil
+		}
+
+		// Each cluster's data is gathered in its own sub-directory, possibly in nested sub-directories
+		rel, err := filepath.Rel(dir, filepath.Dir(path))
+		if err != nil {
+			return errors.Wrapf(err, "error determining the cluster for %q", path)
+		}
+
+		for _, field := range volatileFields {
+			unstructured.RemoveNestedField(obj.Object, field...)
+		}
+
+		removeVolatileConditionFields(obj)
+
+		objects[ObjectRef{
+			Cluster:   strings.SplitN(filepath.ToSlash(rel), "/", 2)[0],
+			Kind:      obj.GetKind(),
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+		}] = obj.Object
+
+		return nil
+	})
+
+	return objects, errors.Wrapf(err, "error reading the gathered data in %q", dir)
+}
+
+func removeVolatileConditionFields(obj *unstructured.Unstructured) {
+	conditions, found, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if !found || err != nil {
+		return
+	}
+
+	for _, condition := range conditions {
+		if fields, ok := condition.(map[string]interface{}); ok {
+			for _, field := range volatileConditionFields {
+				delete(fields, field)
+			}
+		}
+	}
+
+	_ = unstructured.SetNestedSlice(obj.Object, conditions, "status", "conditions")
+}
+
+// diffFields returns the paths of the fields which differ between before and after, recursing into maps.
+func diffFields(path string, before, after interface{}) []string {
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+
+	if !beforeIsMap || !afterIsMap {
+		if reflect.DeepEqual(before, after) {
+			return nil
+		}
+
+		return []string{path}
+	}
+
+	fields := []string{}
+
+	for key, beforeValue := range beforeMap {
+		fields = append(fields, diffFields(joinPath(path, key), beforeValue, afterMap[key])...)
+	}
+
+	for key, afterValue := range afterMap {
+		if _, found := beforeMap[key]; !found {
+			fields = append(fields, diffFields(joinPath(path, key), nil, afterValue)...)
+		}
+	}
+
+	sort.Strings(fields)
+
+	return fields
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+func sortRefs(refs []ObjectRef) {
+	sort.Slice(refs, func(i, j int) bool {
+		return refLess(&refs[i], &refs[j])
+	})
+}
+
+func refLess(a, b *ObjectRef) bool {
+	return a.String() < b.String()
+}
@@ -0,0 +1,56 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"os"
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// The DNS artifacts are co-located in this sub-directory of the cluster's directory.
+const dnsSubDir = "dns"
+
+// gatherDNS gathers what's needed to debug the service discovery DNS resolution: the CoreDNS configuration forwarding
+// the cluster set domain to Lighthouse, the Lighthouse CoreDNS deployment, the logs of both DNS servers, and the
+// ServiceExports and ServiceImports being resolved.
+func gatherDNS(info *Info, dataType string) {
+	if err := os.MkdirAll(filepath.Join(info.DirName, dnsSubDir), 0o700); err != nil {
+		info.Status.Failure("Error creating the DNS sub-directory: %v", err)
+		return
+	}
+
+	info.subDir = dnsSubDir
+	defer func() {
+		info.subDir = ""
+	}()
+
+	switch dataType {
+	case Logs:
+		gatherLighthouseCoreDNSPodLogs(info)
+		gatherCoreDNSPodLogs(info)
+	case Resources:
+		gatherConfigMapCoreDNS(info)
+		gatherConfigMapLighthouseDNS(info, info.ServiceDiscovery.Namespace)
+		gatherLighthouseCoreDNSDeployment(info, info.ServiceDiscovery.Namespace)
+		gatherServiceExports(info, corev1.NamespaceAll)
+		gatherServiceImports(info, corev1.NamespaceAll)
+	}
+}
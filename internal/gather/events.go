@@ -0,0 +1,168 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Events is the module gathering the events concerning Submariner as a timeline.
+const Events = "events"
+
+// The events artifacts are co-located in this sub-directory of the cluster's directory.
+const eventsSubDir = "events"
+
+const (
+	eventsTimelineFileName = "timeline.txt"
+	eventsWarningsFileName = "warnings.txt"
+)
+
+// The API groups of the Submariner and Lighthouse resources, and of the multicluster services API implemented by Lighthouse;
+// the events concerning their resources are relevant in any namespace.
+var submarinerGroups = []string{"submariner.io", "multicluster.x-k8s.io"}
+
+//nolint:gocritic // hugeParam: info - purposely passed by value.
+func gatherEvents(dataType string, info Info) bool {
+	switch dataType {
+	case Resources:
+		gatherEventsTimeline(&info)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// gatherEventsTimeline writes the events concerning objects in the Submariner namespaces, or Submariner resources in any
+// namespace, in chronological order to a timeline file; the warnings are also written to their own file, to be triaged
+// first. The events aren't labeled, the user's label selector doesn't constrain them.
+func gatherEventsTimeline(info *Info) {
+	list, err := info.ClientProducer.ForKubernetes().CoreV1().Events(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		info.Status.Failure("Error listing the events: %v", err)
+		return
+	}
+
+	namespaces := sets.New(info.namespaces...)
+	events := []*corev1.Event{}
+
+	for i := range list.Items {
+		if namespaces.Has(list.Items[i].InvolvedObject.Namespace) || isSubmarinerObject(&list.Items[i].InvolvedObject) {
+			events = append(events, &list.Items[i])
+		}
+	}
+
+	if len(events) == 0 {
+		info.Status.Success("Found no events concerning Submariner")
+		return
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+
+	if err := os.MkdirAll(filepath.Join(info.DirName, eventsSubDir), 0o700); err != nil {
+		info.Status.Failure("Error creating the events sub-directory: %v", err)
+		return
+	}
+
+	warnings := []*corev1.Event{}
+
+	for _, event := range events {
+		if event.Type == corev1.EventTypeWarning {
+			warnings = append(warnings, event)
+		}
+	}
+
+	writeEventsTimeline(info, eventsTimelineFileName, events)
+
+	if len(warnings) > 0 {
+		writeEventsTimeline(info, eventsWarningsFileName, warnings)
+	}
+
+	info.Status.Success("Found %d events concerning Submariner, including %d warnings", len(events), len(warnings))
+}
+
+func isSubmarinerObject(object *corev1.ObjectReference) bool {
+	group := schema.FromAPIVersionAndKind(object.APIVersion, object.Kind).Group
+
+	for _, submarinerGroup := range submarinerGroups {
+		if group == submarinerGroup || strings.HasSuffix(group, "."+submarinerGroup) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// eventTime returns the last time the event occurred; the fields set depend on the API used to report it.
+func eventTime(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	}
+
+	return event.CreationTimestamp.Time
+}
+
+// writeEventsTimeline writes the given events, one per line, with the warnings flagged.
+func writeEventsTimeline(info *Info, fileName string, events []*corev1.Event) {
+	var timeline strings.Builder
+
+	for _, event := range events {
+		flag := " "
+		if event.Type == corev1.EventTypeWarning {
+			flag = "!"
+		}
+
+		count := ""
+		if event.Count > 1 {
+			count = fmt.Sprintf(" (x%d)", event.Count)
+		}
+
+		fmt.Fprintf(&timeline, "%s %s %-7s %s %s/%s: %s%s: %s\n", flag, eventTime(event).UTC().Format(time.RFC3339), event.Type,
+			event.InvolvedObject.Kind, event.InvolvedObject.Namespace, event.InvolvedObject.Name, event.Reason, count,
+			strings.TrimSpace(event.Message))
+	}
+
+	name := filepath.Join(eventsSubDir, fileName)
+
+	err := os.WriteFile(filepath.Join(info.DirName, name), []byte(scrubSensitiveData(info, timeline.String())), 0o600)
+	if err != nil {
+		info.Status.Failure("Error writing the events to %q: %v", name, err)
+		return
+	}
+
+	info.addArtifact(name, info.redactsAny())
+}
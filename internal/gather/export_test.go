@@ -0,0 +1,54 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// GatherModules gathers the selected modules and types in the given directory, with gatherFunc standing in for the modules'
+// gather functions: it's called with the module and type being gathered, and returns the names of the files to write.
+//
+//nolint:gocritic // hugeParam: options - purposely passed by value.
+func GatherModules(dir string, options Options, gatherFunc func(module, dataType string) []string) {
+	saved := gatherFuncs
+	defer func() { gatherFuncs = saved }()
+
+	gatherFuncs = map[string]func(string, Info) bool{}
+
+	for _, module := range options.Modules {
+		module := module
+
+		gatherFuncs[module] = func(dataType string, info Info) bool {
+			for _, fileName := range gatherFunc(module, dataType) {
+				if err := os.WriteFile(filepath.Join(info.DirName, fileName), []byte(module+" "+dataType), 0o600); err != nil {
+					panic(err)
+				}
+
+				info.addArtifact(fileName, false)
+			}
+
+			return true
+		}
+	}
+
+	gatherModules(&Info{ClusterName: "test", DirName: dir, Summary: &Summary{}, writer: io.Discard}, options)
+}
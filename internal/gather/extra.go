@@ -0,0 +1,199 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/admiral/pkg/util"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// The extra resources are co-located in this sub-directory of the cluster's directory, and their artifacts are recorded
+// under this pseudo-module.
+const extraSubDir = "extra"
+
+// ExtraResource is a kind of resource, outside the built-in modules, which is gathered as requested by the user.
+type ExtraResource struct {
+	schema.GroupVersionKind
+	// Namespace restricts the gathering to the given namespace; by default, all the namespaces are gathered.
+	Namespace string
+}
+
+func (r *ExtraResource) String() string {
+	description := r.GroupVersionKind.String()
+	if r.Namespace != "" {
+		description += " in namespace " + r.Namespace
+	}
+
+	return description
+}
+
+// ParseExtraResource parses a group/version/kind[:namespace] specification; the group is empty for the core API group,
+// which can also be specified as version/kind.
+func ParseExtraResource(value string) (ExtraResource, error) {
+	gvk, namespace, _ := strings.Cut(value, ":")
+	parts := strings.Split(gvk, "/")
+
+	if len(parts) == 2 {
+		parts = append([]string{""}, parts...)
+	}
+
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return ExtraResource{}, fmt.Errorf("invalid extra resource %q, expected group/version/kind[:namespace]", value)
+	}
+
+	if strings.Contains(value, ":") && namespace == "" {
+		return ExtraResource{}, fmt.Errorf("invalid extra resource %q, the namespace after the colon is empty", value)
+	}
+
+	return ExtraResource{
+		GroupVersionKind: schema.GroupVersionKind{Group: parts[0], Version: parts[1], Kind: parts[2]},
+		Namespace:        namespace,
+	}, nil
+}
+
+// gatherExtraResources gathers the given kinds, after checking that the cluster serves them and that the user is allowed to
+// list them; the kinds which fail either check are skipped with a warning.
+func gatherExtraResources(info *Info, resources []ExtraResource) {
+	if len(resources) == 0 {
+		return
+	}
+
+	gatherPseudoModule(info, extraSubDir, func() {
+		info.Status.Start("Gathering %d extra resource kind(s)", len(resources))
+		defer info.Status.End()
+
+		restMapper, err := util.BuildRestMapper(info.RestConfig)
+		if err != nil {
+			info.Status.Failure("Error discovering the resources served by the cluster: %v", err)
+			return
+		}
+
+		if err := os.MkdirAll(filepath.Join(info.DirName, extraSubDir), 0o700); err != nil {
+			info.Status.Failure("Error creating the extra resources sub-directory: %v", err)
+			return
+		}
+
+		info.subDir = extraSubDir
+
+		for i := range resources {
+			gatherExtraResource(info, restMapper, &resources[i])
+		}
+	})
+}
+
+// gatherPseudoModule runs the given function as the given pseudo-module, outside the selectable modules; its artifacts,
+// counts and failures are recorded as a module's resources.
+func gatherPseudoModule(info *Info, module string, gather func()) {
+	recorder := &failureRecorder{Basic: info.newReporter()}
+	info.Status = &reporter.Adapter{Basic: recorder}
+	info.module = module
+	info.dataType = Resources
+	artifactsBefore, podLogsBefore := len(info.Summary.Artifacts), len(info.Summary.PodLogs)
+
+	defer func() {
+		info.recordCounts(artifactsBefore, podLogsBefore)
+
+		if len(recorder.failures) > 0 {
+			info.Summary.Failures = append(info.Summary.Failures, ModuleFailure{
+				Module:   module,
+				Type:     Resources,
+				Failures: recorder.failures,
+			})
+		}
+
+		info.module = ""
+		info.dataType = ""
+		info.subDir = ""
+	}()
+
+	gather()
+}
+
+func gatherExtraResource(info *Info, restMapper meta.RESTMapper, resource *ExtraResource) {
+	mapping, err := restMapper.RESTMapping(resource.GroupKind(), resource.Version)
+	if meta.IsNoMatchError(err) {
+		info.Status.Warning("Skipping %s, which isn't served by the cluster", resource.GroupVersionKind)
+		return
+	}
+
+	if err != nil {
+		info.Status.Failure("Error looking up %s: %v", resource.GroupVersionKind, err)
+		return
+	}
+
+	namespace := resource.Namespace
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace && namespace != "" {
+		info.Status.Warning("%s isn't namespaced, ignoring the namespace %q", resource.GroupVersionKind, namespace)
+
+		namespace = metav1.NamespaceAll
+	}
+
+	allowed, err := canList(info, mapping.Resource, namespace)
+	if err != nil {
+		info.Status.Failure("Error checking the permission to list %s: %v", mapping.Resource.GroupResource(), err)
+		return
+	}
+
+	if !allowed {
+		where := "across all namespaces"
+		if namespace != "" {
+			where = fmt.Sprintf("in namespace %q", namespace)
+		}
+
+		info.Status.Warning("Skipping %s: you don't have permission to list %s %s", resource.GroupVersionKind,
+			mapping.Resource.GroupResource(), where)
+
+		return
+	}
+
+	ResourcesToYAMLFile(info, mapping.Resource, namespace, metav1.ListOptions{})
+}
+
+// canList returns whether the user is allowed to list the given resource in the namespace, or all namespaces if empty.
+func canList(info *Info, resource schema.GroupVersionResource, namespace string) (bool, error) {
+	review, err := info.ClientProducer.ForKubernetes().AuthorizationV1().SelfSubjectAccessReviews().Create(context.TODO(),
+		&authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: namespace,
+					Verb:      "list",
+					Group:     resource.Group,
+					Version:   resource.Version,
+					Resource:  resource.Resource,
+				},
+			},
+		}, metav1.CreateOptions{})
+	if err != nil {
+		return false, errors.Wrap(err, "error creating the access review")
+	}
+
+	return review.Status.Allowed, nil
+}
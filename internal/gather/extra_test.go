@@ -0,0 +1,56 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/internal/gather"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("ParseExtraResource", func() {
+	DescribeTable("should parse valid specifications",
+		func(value string, expected gather.ExtraResource) {
+			Expect(gather.ParseExtraResource(value)).To(Equal(expected))
+		},
+		Entry("with a group and namespace", "networking.istio.io/v1beta1/Gateway:istio-system", gather.ExtraResource{
+			GroupVersionKind: schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "Gateway"},
+			Namespace:        "istio-system",
+		}),
+		Entry("without a namespace", "networking.k8s.io/v1/IngressClass", gather.ExtraResource{
+			GroupVersionKind: schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "IngressClass"},
+		}),
+		Entry("in the core group", "v1/ConfigMap:kube-system", gather.ExtraResource{
+			GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+			Namespace:        "kube-system",
+		}),
+	)
+
+	DescribeTable("should reject invalid specifications",
+		func(value string) {
+			_, err := gather.ParseExtraResource(value)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("with only a kind", "Gateway"),
+		Entry("with too many parts", "a/b/c/d"),
+		Entry("without a kind", "networking.k8s.io/v1/"),
+		Entry("with an empty namespace", "v1/ConfigMap:"),
+	)
+})
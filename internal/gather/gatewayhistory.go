@@ -0,0 +1,119 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	submarinerv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const DefaultGatewayHistoryInterval = 5 * time.Second
+
+// GatewayStatusSample is a Gateway's status observed at a given time.
+type GatewayStatusSample struct {
+	Timestamp time.Time                  `json:"timestamp"`
+	Status    submarinerv1.GatewayStatus `json:"status"`
+}
+
+// gatherGatewayStatusHistory polls the Gateways during the configured window and writes each Gateway's sequence of
+// statuses, allowing intermittent connection changes to be traced.
+func gatherGatewayStatusHistory(info *Info) {
+	interval := info.gatewayHistoryInterval
+	if interval <= 0 {
+		interval = DefaultGatewayHistoryInterval
+	}
+
+	info.Status.Success("Recording the Gateways' status every %s for %s", interval, info.gatewayHistoryWindow)
+
+	history := map[controllerClient.ObjectKey][]GatewayStatusSample{}
+	deadline := time.Now().Add(info.gatewayHistoryWindow)
+
+	for {
+		if err := sampleGatewayStatuses(info, history); err != nil {
+			info.Status.Failure("Error listing the Gateways: %v", err)
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			break
+		}
+
+		time.Sleep(interval)
+	}
+
+	keys := make([]controllerClient.ObjectKey, 0, len(history))
+	for key := range history {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	for _, key := range keys {
+		if err := writeGatewayStatusHistory(info, key, history[key]); err != nil {
+			info.Status.Failure("Error writing the status history of Gateway %q: %v", key, err)
+		}
+	}
+}
+
+func sampleGatewayStatuses(info *Info, history map[controllerClient.ObjectKey][]GatewayStatusSample) error {
+	for _, namespace := range info.namespaces {
+		gateways := &submarinerv1.GatewayList{}
+
+		err := info.ClientProducer.ForGeneral().List(context.TODO(), gateways, controllerClient.InNamespace(namespace))
+		if err != nil {
+			return errors.Wrapf(err, "error listing the Gateways in namespace %q", namespace)
+		}
+
+		now := time.Now().UTC()
+
+		for i := range gateways.Items {
+			key := controllerClient.ObjectKeyFromObject(&gateways.Items[i])
+			history[key] = append(history[key], GatewayStatusSample{Timestamp: now, Status: gateways.Items[i].Status})
+		}
+	}
+
+	return nil
+}
+
+func writeGatewayStatusHistory(info *Info, key controllerClient.ObjectKey, samples []GatewayStatusSample) error {
+	data, err := yaml.Marshal(samples)
+	if err != nil {
+		return errors.Wrap(err, "error marshaling to YAML")
+	}
+
+	name := escapeFileName("gateways-history_"+key.Namespace+"_"+key.Name) + ".yaml"
+
+	err = os.WriteFile(filepath.Join(info.DirName, name), []byte(scrubSensitiveData(info, string(data))), 0o600)
+	if err != nil {
+		return errors.Wrapf(err, "error writing to file %s", name)
+	}
+
+	info.addArtifact(name, info.redactsAny())
+
+	return nil
+}
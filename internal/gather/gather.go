@@ -22,11 +22,13 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/submariner-io/admiral/pkg/reporter"
-	"github.com/submariner-io/subctl/internal/cli"
 	"github.com/submariner-io/subctl/internal/component"
 	"github.com/submariner-io/subctl/internal/constants"
 	"github.com/submariner-io/subctl/internal/exit"
@@ -38,41 +40,78 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/rest"
 	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type Options struct {
-	Directory            string
+	Directory string
+	// IncludeSensitiveData disables the redaction of all the sensitive data, unless Redact is set.
 	IncludeSensitiveData bool
-	Modules              []string
-	Types                []string
+	// Redact, if set, lists the categories of sensitive data to redact, overriding IncludeSensitiveData.
+	Redact          []string
+	Modules         []string
+	Types           []string
+	OutputFormat    string
+	Archive         bool
+	RemoveDirectory bool
+	Since           time.Time
+	Until           time.Time
+	// DumpDatapath enables scheduling a diagnostic pod on each gateway node to dump its packet filtering rules.
+	DumpDatapath bool
+	// PreviousLogs enables gathering the logs of the previous container instances of the pods which restarted.
+	PreviousLogs bool
+	// GatewayHistoryWindow, when positive, enables polling the Gateways' status during the given duration, every
+	// GatewayHistoryInterval (by default DefaultGatewayHistoryInterval), to record how their connections change.
+	GatewayHistoryWindow   time.Duration
+	GatewayHistoryInterval time.Duration
+	// Namespaces are scanned in addition to the detected Submariner namespaces.
+	Namespaces []string
+	// ExtraResources are kinds gathered in addition to the modules' resources.
+	ExtraResources []ExtraResource
+	// Selector, if set, is a label selector restricting the gathered resources, including the extra resources, to those
+	// it matches. It doesn't constrain the pod logs, metrics and diagnose results, nor the node-level command outputs.
+	Selector string
+	// Resume skips the modules and types which were fully collected by a previous run in the same directory, as recorded
+	// in its manifest; the manifest is kept up to date while gathering, so this also applies to interrupted runs.
+	// The manifest is always written when resuming.
+	Resume bool
+	// Sink, if set, receives each artifact as it's produced, in addition to the local directory.
+	Sink Sink
+	// QPS and Burst limit the rate of the API requests made while gathering, by default DefaultQPS and DefaultBurst.
+	QPS   float32
+	Burst int
+	// Writer receives the progress output; if nil, the standard output and error streams are used.
+	Writer io.Writer
 }
 
 const (
 	Logs      = "logs"
 	Resources = "resources"
+	Metrics   = "metrics"
+	Diagnose  = "diagnose"
 )
 
-var AllModules = sets.New(component.Connectivity, component.ServiceDiscovery, component.Broker, component.Operator)
+// CNI is the module gathering the network plugin's own configuration.
+const CNI = "cni"
 
-var AllTypes = sets.New(Logs, Resources)
+var AllModules = sets.KeySet(moduleDescriptions)
+
+var AllTypes = sets.KeySet(typeDescriptions)
 
 var gatherFuncs = map[string]func(string, Info) bool{
 	component.Connectivity:     gatherConnectivity,
 	component.ServiceDiscovery: gatherDiscovery,
 	component.Broker:           gatherBroker,
 	component.Operator:         gatherOperator,
+	CNI:                        gatherCNI,
+	Monitoring:                 gatherMonitoring,
+	Events:                     gatherEvents,
 }
 
 func Data(clusterInfo *cluster.Info, status reporter.Interface, options Options) error {
-	var warningsBuf bytes.Buffer
-
-	rest.SetDefaultWarningHandler(rest.NewWarningWriter(&warningsBuf, rest.WarningWriterOptions{
-		Deduplicate: true,
-	}))
-
 	// concatenate the name of the cluster with the root gather directory
 	options.Directory = filepath.Join(options.Directory, clusterInfo.Name)
 
@@ -83,41 +122,165 @@ func Data(clusterInfo *cluster.Info, status reporter.Interface, options Options)
 		}
 	}
 
-	gatherDataByCluster(clusterInfo, options)
+	info := Info{
+		Info:                   *clusterInfo,
+		ClusterName:            clusterInfo.Name,
+		DirName:                options.Directory,
+		redaction:              redactionPolicy(&options),
+		Summary:                &Summary{},
+		writer:                 options.Writer,
+		since:                  options.Since,
+		until:                  options.Until,
+		dumpDatapath:           options.DumpDatapath,
+		previousLogs:           options.PreviousLogs,
+		sink:                   options.Sink,
+		gatewayHistoryWindow:   options.GatewayHistoryWindow,
+		gatewayHistoryInterval: options.GatewayHistoryInterval,
+		secretRefs:             map[types.NamespacedName]sets.Set[string]{},
+	}
 
-	fmt.Printf("Files are stored under directory %q\n", options.Directory)
+	if err := ValidateSelector(options.Selector); err != nil {
+		return status.Error(err, "Error gathering data from cluster %q", info.ClusterName)
+	}
+
+	info.selector = options.Selector
 
-	warnings := warningsBuf.String()
-	if warnings != "" {
-		fmt.Printf("\nEncountered following Kubernetes warnings while running:\n%s", warnings)
+	if err := applyRateLimit(&info, &options); err != nil {
+		return status.Error(err, "Error configuring the API rate limit for cluster %q", info.ClusterName)
 	}
 
+	info.namespaces = resolveNamespaces(&info, options.Namespaces, status)
+
+	gatherDataByCluster(&info, options)
+
+	reportCounts(&info, status)
+
+	if throttled := info.throttled.Load(); throttled > 0 {
+		status.Warning("The API server of cluster %q throttled %d request(s), which were retried after backing off; "+
+			"consider gathering with a lower --qps", info.ClusterName, throttled)
+	}
+
+	if options.Sink != nil && len(info.Summary.SinkFailures) > 0 {
+		status.Warning("%d artifact(s) from cluster %q couldn't be streamed to the sink, they're only available locally",
+			len(info.Summary.SinkFailures), info.ClusterName)
+	}
+
+	fmt.Fprintf(info.stdout(), "Files are stored under directory %q\n", options.Directory)
+
 	return nil
 }
 
-func gatherDataByCluster(clusterInfo *cluster.Info, options Options) {
-	clusterName := clusterInfo.Name
+// CaptureWarnings redirects the Kubernetes API warnings to the returned buffer, deduplicating them.
+// This affects all clients in the process.
+func CaptureWarnings() *bytes.Buffer {
+	warningsBuf := &bytes.Buffer{}
+
+	rest.SetDefaultWarningHandler(rest.NewWarningWriter(&syncWriter{writer: warningsBuf}, rest.WarningWriterOptions{
+		Deduplicate: true,
+	}))
+
+	return warningsBuf
+}
+
+// syncWriter serializes writes to the underlying writer.
+type syncWriter struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
 
-	fmt.Printf("Gathering information from cluster %q\n", clusterName)
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
 
-	info := Info{
-		Info:                 *clusterInfo,
-		ClusterName:          clusterName,
-		DirName:              options.Directory,
-		IncludeSensitiveData: options.IncludeSensitiveData,
-		Summary:              &Summary{},
+	return w.writer.Write(p) //nolint:wrapcheck // No need to wrap here
+}
+
+//nolint:gocritic // hugeParam: options - purposely passed by value.
+func gatherDataByCluster(info *Info, options Options) {
+	fmt.Fprintf(info.stdout(), "Gathering information from cluster %q\n", info.ClusterName)
+
+	gatherModules(info, options)
+
+	gatherExtraResources(info, options.ExtraResources)
+
+	gatherSecretReferences(info)
+
+	gatherClusterSummary(info)
+
+	if options.OutputFormat == OutputJSON || options.Resume {
+		if err := writeManifest(info, options.Modules, options.Types); err != nil {
+			fmt.Fprintln(info.stdout(), err)
+		}
+	} else {
+		removeManifest(info)
+	}
+}
+
+// gatherModules gathers each selected module and type, reusing those collected by a previous run when resuming. The
+// manifest is saved as each of them completes, even if it wasn't requested, so that an interrupted gather leaves a record
+// of what it collected; an unrequested manifest is removed once the gather finishes.
+//
+//nolint:gocritic // hugeParam: options - purposely passed by value.
+func gatherModules(info *Info, options Options) {
+	// Only the selected modules and types are counted
+	total := len(options.Modules) * len(options.Types)
+	completed := 0
+
+	var reusable map[moduleType][]ArtifactInfo
+	if options.Resume {
+		reusable = loadReusableArtifacts(info)
 	}
 
 	for _, module := range options.Modules {
 		for _, dataType := range options.Types {
-			info.Status = cli.NewReporter()
-			info.Status.Start("Gathering %s %s", module, dataType)
-			gatherFuncs[module](dataType, info)
-			info.Status.End()
+			recorder := &failureRecorder{Basic: info.newReporter()}
+			info.Status = &reporter.Adapter{Basic: recorder}
+			info.module = module
+			info.dataType = dataType
+			artifactsBefore, podLogsBefore := len(info.Summary.Artifacts), len(info.Summary.PodLogs)
+
+			if artifacts, found := reusable[moduleType{module: module, dataType: dataType}]; found {
+				info.Summary.Artifacts = append(info.Summary.Artifacts, artifacts...)
+				info.recordCounts(artifactsBefore, podLogsBefore)
+
+				completed++
+				info.Status.Start("Gathering %s %s", module, dataType)
+				info.Status.Success("Reusing %d previously collected artifacts", len(artifacts))
+				info.Status.Success("Completed %d of %d gather tasks", completed, total)
+				info.Status.End()
+			} else {
+				info.Status.Start("Gathering %s %s", module, dataType)
+
+				if dataType != Logs && info.hasLogWindow() {
+					info.Status.Warning("The log time window doesn't apply to %s, which are gathered in full", dataType)
+				}
+
+				gatherFuncs[module](dataType, *info)
+				info.recordCounts(artifactsBefore, podLogsBefore)
+
+				completed++
+				info.Status.Success("Completed %d of %d gather tasks", completed, total)
+				info.Status.End()
+
+				if len(recorder.failures) > 0 {
+					info.Summary.Failures = append(info.Summary.Failures, ModuleFailure{
+						Module:   module,
+						Type:     dataType,
+						Failures: recorder.failures,
+					})
+				}
+			}
+
+			info.Summary.Completed = append(info.Summary.Completed, CompletedTask{Module: module, Type: dataType})
+
+			if err := saveManifest(info, options.Modules, options.Types); err != nil {
+				fmt.Fprintln(info.stdout(), err)
+			}
 		}
 	}
 
-	gatherClusterSummary(&info)
+	info.module = ""
+	info.dataType = ""
 }
 
 //nolint:gocritic // hugeParam: info - purposely passed by value.
@@ -138,12 +301,32 @@ func gatherConnectivity(dataType string, info Info) bool {
 		gatherCNIResources(&info, info.Submariner.Status.NetworkPlugin)
 		gatherCableDriverResources(&info, info.Submariner.Spec.CableDriver)
 		gatherOVNResources(&info, info.Submariner.Status.NetworkPlugin)
-		gatherEndpoints(&info, info.Submariner.Spec.Namespace)
-		gatherClusters(&info, info.Submariner.Spec.Namespace)
-		gatherGateways(&info, info.Submariner.Spec.Namespace)
+
+		for _, namespace := range info.namespaces {
+			gatherEndpoints(&info, namespace)
+			gatherClusters(&info, namespace)
+			gatherGateways(&info, namespace)
+		}
+
+		gatherConnectionSummary(&info)
+
 		gatherClusterGlobalEgressIPs(&info)
 		gatherGlobalEgressIPs(&info)
 		gatherGlobalIngressIPs(&info)
+
+		if info.dumpDatapath {
+			gatherGatewayNodeDatapath(&info)
+		}
+
+		if info.gatewayHistoryWindow > 0 {
+			gatherGatewayStatusHistory(&info)
+		}
+	case Metrics:
+		gatherPodMetrics(&info, gatewayPodLabel, gatewayMetricsPort)
+		gatherPodMetrics(&info, routeagentPodLabel, 0)
+		gatherPodMetrics(&info, globalnetPodLabel, globalnetMetricsPort)
+	case Diagnose:
+		return gatherDiagnoseResults(&info, component.Connectivity)
 	default:
 		return false
 	}
@@ -161,14 +344,13 @@ func gatherDiscovery(dataType string, info Info) bool {
 	switch dataType {
 	case Logs:
 		gatherServiceDiscoveryPodLogs(&info)
-		gatherCoreDNSPodLogs(&info)
+		gatherDNS(&info, dataType)
 	case Resources:
-		gatherServiceExports(&info, corev1.NamespaceAll)
-		gatherServiceImports(&info, corev1.NamespaceAll)
 		gatherEndpointSlices(&info, corev1.NamespaceAll)
-		gatherConfigMapLighthouseDNS(&info, info.ServiceDiscovery.Namespace)
-		gatherConfigMapCoreDNS(&info)
 		gatherLabeledServices(&info, internalSvcLabel)
+		gatherDNS(&info, dataType)
+	case Diagnose:
+		return gatherDiagnoseResults(&info, component.ServiceDiscovery)
 	default:
 		return false
 	}
@@ -187,9 +369,9 @@ func gatherBroker(dataType string, info Info) bool {
 		}
 
 		if brokerRestConfig != nil {
-			info.RestConfig = brokerRestConfig
+			info.RestConfig = info.rateLimitedConfig(brokerRestConfig)
 
-			info.ClientProducer, err = client.NewProducerFromRestConfig(brokerRestConfig)
+			info.ClientProducer, err = client.NewProducerFromRestConfig(info.RestConfig)
 			if err != nil {
 				info.Status.Failure("Error creating broker client Producer: %s", err)
 				return true
@@ -232,15 +414,27 @@ func gatherOperator(dataType string, info Info) bool {
 	case Logs:
 		gatherSubmarinerOperatorPodLogs(&info)
 	case Resources:
-		gatherSubmariners(&info, info.OperatorNamespace())
-		gatherServiceDiscoveries(&info, info.OperatorNamespace())
-		gatherSubmarinerOperatorDeployment(&info, info.OperatorNamespace())
-		gatherGatewayDaemonSet(&info, info.OperatorNamespace())
-		gatherRouteAgentDaemonSet(&info, info.OperatorNamespace())
-		gatherGlobalnetDaemonSet(&info, info.OperatorNamespace())
-		gatherNetworkPluginSyncerDeployment(&info, info.OperatorNamespace())
-		gatherLighthouseAgentDeployment(&info, info.OperatorNamespace())
-		gatherLighthouseCoreDNSDeployment(&info, info.OperatorNamespace())
+		for _, namespace := range info.namespaces {
+			gatherSubmariners(&info, namespace)
+			gatherServiceDiscoveries(&info, namespace)
+			gatherSubmarinerOperatorDeployment(&info, namespace)
+			gatherGatewayDaemonSet(&info, namespace)
+			gatherRouteAgentDaemonSet(&info, namespace)
+			gatherGlobalnetDaemonSet(&info, namespace)
+			gatherNetworkPluginSyncerDeployment(&info, namespace)
+			gatherLighthouseAgentDeployment(&info, namespace)
+			gatherLighthouseCoreDNSDeployment(&info, namespace)
+			gatherNetworkPolicies(&info, namespace)
+			gatherLeases(&info, namespace)
+		}
+
+		gatherAdmissionWebhooks(&info)
+		gatherAPIServices(&info)
+		gatherStorage(&info)
+	case Metrics:
+		gatherSubmarinerOperatorPodMetrics(&info)
+	case Diagnose:
+		return gatherDiagnoseResults(&info, component.Operator)
 	default:
 		return false
 	}
@@ -24,13 +24,18 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
+// gatherPodLogs gathers the logs of the component pods matching the given label selector; the user's label selector doesn't
+// constrain them, so that the components' logs are always available.
 func gatherPodLogs(podLabelSelector string, info *Info) {
 	gatherPodLogsByContainer(podLabelSelector, "", info)
 }
@@ -47,6 +52,14 @@ func gatherPodLogsByContainer(podLabelSelector, container string, info *Info) {
 		podLogOptions := corev1.PodLogOptions{
 			Container: container,
 		}
+
+		if !info.since.IsZero() {
+			podLogOptions.SinceTime = &metav1.Time{Time: info.since}
+		}
+
+		// The API doesn't support an end time, the logs are filtered using their timestamps
+		podLogOptions.Timestamps = !info.until.IsZero()
+
 		for i := range pods.Items {
 			info.Summary.PodLogs = append(info.Summary.PodLogs, outputPodLogs(&pods.Items[i], podLogOptions, info))
 		}
@@ -85,9 +98,47 @@ func writePodLogToFile(logStream io.ReadCloser, info *Info, podName, fileExtensi
 		return "", err
 	}
 
+	logs = filterLogsUntil(logs, info.until)
 	logs = scrubSensitiveData(info, logs)
 
-	return writeLogToFile(logs, podName, info, fileExtension)
+	fileName, err := writeLogToFile(logs, podName, info, fileExtension)
+	if err == nil {
+		info.addArtifact(fileName, info.redactsAny())
+	}
+
+	return fileName, err
+}
+
+// filterLogsUntil drops the log lines timestamped after until, and strips the timestamps added to the kept lines so that the
+// logs match those gathered without a time limit; the logs must have been retrieved with timestamps. Lines without a
+// timestamp are kept as-is.
+func filterLogsUntil(logs string, until time.Time) string {
+	if until.IsZero() {
+		return logs
+	}
+
+	var filtered strings.Builder
+
+	for _, line := range strings.SplitAfter(logs, "\n") {
+		timestamp, rest, found := strings.Cut(line, " ")
+		if found {
+			if t, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+				if t.After(until) {
+					continue
+				}
+
+				line = rest
+			}
+		}
+
+		filtered.WriteString(line)
+	}
+
+	return filtered.String()
+}
+
+func (info *Info) hasLogWindow() bool {
+	return !info.since.IsZero() || !info.until.IsZero()
 }
 
 func getLogFromStream(logStream io.ReadCloser) (string, error) {
@@ -102,7 +153,7 @@ func getLogFromStream(logStream io.ReadCloser) (string, error) {
 }
 
 func writeLogToFile(data, podName string, info *Info, fileExtension string) (string, error) {
-	fileName := escapeFileName(podName) + fileExtension
+	fileName := filepath.Join(info.subDir, escapeFileName(podName)+fileExtension)
 	filePath := filepath.Join(info.DirName, fileName)
 
 	f, err := os.Create(filePath)
@@ -128,35 +179,78 @@ func findPods(clientSet kubernetes.Interface, byLabelSelector string) (*corev1.P
 	return pods, nil
 }
 
+// outputPreviousPodLog writes the logs of the previous instance of the pod's container, if it restarted; pods which never
+// restarted are recorded as such. Previous logs which are no longer available, e.g. rotated or garbage-collected, aren't
+// failures.
+//
 //nolint:gocritic // hugeParam: podLogOptions - purposely passed by value.
 func outputPreviousPodLog(pod *corev1.Pod, podLogOptions corev1.PodLogOptions, info *Info, podLogInfo *LogInfo) error {
+	podLogInfo.RestartCount = restartCount(pod, podLogOptions.Container)
+
+	if !info.previousLogs {
+		return nil
+	}
+
+	if podLogInfo.RestartCount == 0 {
+		info.Summary.NotRestartedPods = append(info.Summary.NotRestartedPods, pod.Namespace+"/"+pod.Name)
+		return nil
+	}
+
 	podLogOptions.Previous = true
 	logRequest := info.ClientProducer.ForKubernetes().CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &podLogOptions)
-	logStream, _ := logRequest.Stream(context.TODO())
 
-	// TODO: Check for error other than "no previous pods found"
+	logStream, err := logRequest.Stream(context.TODO())
+	if apierrors.IsBadRequest(err) || apierrors.IsNotFound(err) {
+		// The API server reports missing previous logs as bad requests
+		info.Status.Warning("Pod %s restarted %d time(s), but the logs of its previous instances are no longer available",
+			pod.Name, podLogInfo.RestartCount)
 
-	// if no previous pods found, logstream == nil, ignore it
-	if logStream != nil {
-		info.Status.Warning("Found logs for previous instances of pod %s", pod.Name)
+		return nil
+	}
 
-		fileName, err := writePodLogToFile(logStream, info, pod.Name, ".log.prev")
-		if err != nil {
-			return err
-		}
+	if err != nil {
+		return errors.WithMessage(err, "error opening the previous log stream")
+	}
+
+	defer logStream.Close()
 
-		podLogInfo.LogFileName = append(podLogInfo.LogFileName, fileName)
+	info.Status.Warning("Found logs for previous instances of pod %s, which restarted %d time(s)", pod.Name,
+		podLogInfo.RestartCount)
 
-		defer logStream.Close()
+	fileName, err := writePodLogToFile(logStream, info, pod.Name, ".log.prev")
+	if err != nil {
+		return err
 	}
 
-	if len(pod.Status.ContainerStatuses) > 0 {
-		podLogInfo.RestartCount = pod.Status.ContainerStatuses[0].RestartCount
-	}
+	podLogInfo.LogFileName = append(podLogInfo.LogFileName, fileName)
 
 	return nil
 }
 
+// restartCount returns the restart count of the given container, or, if none is named, of the pod's only container;
+// if the pod has several containers, the highest restart count is returned.
+func restartCount(pod *corev1.Pod, container string) int32 {
+	if container == "" && len(pod.Spec.Containers) == 1 {
+		container = pod.Spec.Containers[0].Name
+	}
+
+	count := int32(0)
+
+	for i := range pod.Status.ContainerStatuses {
+		status := &pod.Status.ContainerStatuses[i]
+
+		if status.Name == container {
+			return status.RestartCount
+		}
+
+		if container == "" && status.RestartCount > count {
+			count = status.RestartCount
+		}
+	}
+
+	return count
+}
+
 //nolint:gocritic // hugeParam: podLogOptions - purposely passed by value.
 func outputCurrentPodLog(pod *corev1.Pod, podLogOptions corev1.PodLogOptions, info *Info, podLogInfo *LogInfo) error {
 	// Running with Previous = false on the same pod
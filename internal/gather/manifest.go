@@ -0,0 +1,155 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	OutputJSON       = "json"
+	manifestFileName = "manifest.json"
+)
+
+// Manifest describes the artifacts gathered from a cluster, and the errors encountered while gathering them.
+type Manifest struct {
+	ClusterName string `json:"clusterName"`
+	// Modules and Types are the selection which was requested.
+	Modules   []string        `json:"modules,omitempty"`
+	Types     []string        `json:"types,omitempty"`
+	Artifacts []ArtifactInfo  `json:"artifacts"`
+	Errors    []ModuleFailure `json:"errors,omitempty"`
+	// Completed are the modules and types whose gathering finished, including those which produced no artifacts.
+	Completed []CompletedTask `json:"completed,omitempty"`
+	// NotRestartedPods are the pods whose previous logs weren't gathered because they never restarted.
+	NotRestartedPods []string `json:"notRestartedPods,omitempty"`
+	// RedactedCategories are the categories of sensitive data which were redacted.
+	RedactedCategories []string `json:"redactedCategories"`
+	// SinkFailures are the artifacts which couldn't be streamed to the sink.
+	SinkFailures []string `json:"sinkFailures,omitempty"`
+}
+
+type ArtifactInfo struct {
+	Module   string `json:"module,omitempty"`
+	Type     string `json:"type,omitempty"`
+	FileName string `json:"fileName"`
+	Size     int64  `json:"size"`
+	Redacted bool   `json:"redacted"`
+	// Reused is set for artifacts collected by a previous run, when resuming.
+	Reused bool `json:"reused,omitempty"`
+}
+
+type CompletedTask struct {
+	Module string `json:"module"`
+	Type   string `json:"type"`
+}
+
+type ModuleFailure struct {
+	Module   string   `json:"module"`
+	Type     string   `json:"type"`
+	Failures []string `json:"failures"`
+}
+
+// failureRecorder records the failures reported while gathering a module's data.
+type failureRecorder struct {
+	reporter.Basic
+	failures []string
+}
+
+func (r *failureRecorder) Failure(message string, args ...interface{}) {
+	if message != "" {
+		r.failures = append(r.failures, fmt.Sprintf(message, args...))
+	}
+
+	r.Basic.Failure(message, args...)
+}
+
+// addArtifact records a file written in the cluster's gather directory, for the module and type being gathered.
+func (info *Info) addArtifact(fileName string, redacted bool) {
+	artifact := ArtifactInfo{
+		Module:   info.module,
+		Type:     info.dataType,
+		FileName: fileName,
+		Redacted: redacted,
+	}
+
+	if fileInfo, err := os.Stat(filepath.Join(info.DirName, fileName)); err == nil {
+		artifact.Size = fileInfo.Size()
+	}
+
+	info.Summary.Artifacts = append(info.Summary.Artifacts, artifact)
+
+	info.streamArtifact(fileName)
+}
+
+// writeManifest writes the final manifest and streams it to the sink.
+func writeManifest(info *Info, modules, types []string) error {
+	if err := saveManifest(info, modules, types); err != nil {
+		return err
+	}
+
+	info.streamArtifact(manifestFileName)
+
+	return nil
+}
+
+// removeManifest removes the manifest saved while gathering, when none was requested.
+func removeManifest(info *Info) {
+	path := filepath.Join(info.DirName, manifestFileName)
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(info.stdout(), "Error removing the manifest %s: %v\n", path, err)
+	}
+}
+
+// saveManifest writes the manifest as it stands in the cluster's gather directory. It is rewritten as each module and type
+// completes, so that an interrupted gather can be resumed.
+func saveManifest(info *Info, modules, types []string) error {
+	manifest := Manifest{
+		ClusterName:        info.ClusterName,
+		Modules:            modules,
+		Types:              types,
+		Artifacts:          info.Summary.Artifacts,
+		Errors:             info.Summary.Failures,
+		Completed:          info.Summary.Completed,
+		NotRestartedPods:   info.Summary.NotRestartedPods,
+		SinkFailures:       info.Summary.SinkFailures,
+		RedactedCategories: sets.List(info.redaction),
+	}
+
+	data, err := json.MarshalIndent(&manifest, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "error marshalling the manifest")
+	}
+
+	path := filepath.Join(info.DirName, manifestFileName)
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return errors.Wrapf(err, "error writing the manifest to %s", path)
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const metadataFileName = "gather-metadata.json"
+
+type metadata struct {
+	// TimeZone is the zone used for the directory timestamp.
+	TimeZone string `json:"timeZone"`
+	UTC      string `json:"utc"`
+	Local    string `json:"local"`
+}
+
+// WriteMetadata records when the data was gathered in the given directory, both in UTC and in the local time zone,
+// along with the zone used to name the directory.
+func WriteMetadata(directory string, timestamp time.Time, zone *time.Location) error {
+	if err := os.MkdirAll(directory, 0o700); err != nil {
+		return errors.Wrapf(err, "error creating directory %q", directory)
+	}
+
+	zoneName, _ := timestamp.In(zone).Zone()
+
+	data, err := json.MarshalIndent(metadata{
+		TimeZone: zoneName,
+		UTC:      timestamp.UTC().Format(time.RFC3339),
+		Local:    timestamp.Local().Format(time.RFC3339),
+	}, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "error marshalling the gather metadata")
+	}
+
+	fileName := filepath.Join(directory, metadataFileName)
+
+	err = os.WriteFile(fileName, append(data, '\n'), 0o600)
+
+	return errors.Wrapf(err, "error writing file %q", fileName)
+}
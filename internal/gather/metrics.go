@@ -0,0 +1,153 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+const (
+	gatewayMetricsPort   = 32780
+	globalnetMetricsPort = 32781
+	operatorMetricsPort  = 8383
+	metricsTimeout       = 30 * time.Second
+)
+
+// gatherPodMetrics scrapes the metrics of the pods matching the label selector, using the given port unless the pod
+// declares a container port named "metrics". Pods without a known metrics port are skipped.
+func gatherPodMetrics(info *Info, podLabelSelector string, defaultPort int32) {
+	logPodInfo(info, "metrics", podLabelSelector, func(info *Info, pod *corev1.Pod) {
+		port := metricsPort(pod, defaultPort)
+		if port == 0 {
+			info.Status.Warning("Skipping pod %q which doesn't expose a metrics port", pod.Name)
+			return
+		}
+
+		metrics, err := scrapePodMetrics(info, pod, port)
+		if err != nil {
+			info.Status.Failure("Error scraping the metrics of pod %q on port %d: %v", pod.Name, port, err)
+			return
+		}
+
+		// Metrics aren't sensitive, they're stored as is
+		fileName, err := writeLogToFile(metrics, pod.Name+"_metrics", info, ".txt")
+		if err != nil {
+			info.Status.Failure("Error writing the metrics of pod %q: %v", pod.Name, err)
+			return
+		}
+
+		info.addArtifact(fileName, false)
+
+		info.Summary.Resources = append(info.Summary.Resources, ResourceInfo{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			FileName:  fileName,
+			Type:      Metrics,
+		})
+	})
+}
+
+func metricsPort(pod *corev1.Pod, defaultPort int32) int32 {
+	for i := range pod.Spec.Containers {
+		for _, port := range pod.Spec.Containers[i].Ports {
+			if strings.Contains(port.Name, "metrics") {
+				return port.ContainerPort
+			}
+		}
+	}
+
+	return defaultPort
+}
+
+func scrapePodMetrics(info *Info, pod *corev1.Pod, port int32) (string, error) {
+	transport, upgrader, err := spdy.RoundTripperFor(info.RestConfig)
+	if err != nil {
+		return "", errors.WithMessage(err, "error creating the port-forward transport")
+	}
+
+	req := info.ClientProducer.ForKubernetes().CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(pod.Namespace).Name(pod.Name).SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	stopChan := make(chan struct{})
+	readyChan := make(chan struct{})
+
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"localhost"}, []string{fmt.Sprintf("0:%d", port)},
+		stopChan, readyChan, io.Discard, io.Discard)
+	if err != nil {
+		return "", errors.WithMessage(err, "error creating the port forwarder")
+	}
+
+	errChan := make(chan error, 1)
+
+	go func() {
+		errChan <- forwarder.ForwardPorts()
+	}()
+
+	defer close(stopChan)
+
+	select {
+	case <-readyChan:
+	case err := <-errChan:
+		return "", errors.WithMessage(err, "error forwarding the metrics port")
+	case <-time.After(metricsTimeout):
+		return "", errors.New("timed out waiting for the metrics port to be forwarded")
+	}
+
+	ports, err := forwarder.GetPorts()
+	if err != nil {
+		return "", errors.WithMessage(err, "error retrieving the forwarded port")
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), metricsTimeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://localhost:%d/metrics", ports[0].Local), http.NoBody)
+	if err != nil {
+		return "", errors.WithMessage(err, "error creating the metrics request")
+	}
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return "", errors.WithMessage(err, "error retrieving the metrics")
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected response status %q", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.WithMessage(err, "error reading the metrics")
+	}
+
+	return string(body), nil
+}
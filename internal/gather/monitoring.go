@@ -0,0 +1,91 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"os"
+	"path/filepath"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Monitoring is the module gathering the monitoring stack's integration objects.
+const Monitoring = "monitoring"
+
+// The monitoring artifacts are co-located in this sub-directory of the cluster's directory.
+const monitoringSubDir = "monitoring"
+
+// The monitoring CRDs, only served when the Prometheus operator is installed.
+var monitoringResources = []schema.GroupVersionResource{
+	{Group: "monitoring.coreos.com", Version: "v1", Resource: "servicemonitors"},
+	{Group: "monitoring.coreos.com", Version: "v1", Resource: "podmonitors"},
+	{Group: "monitoring.coreos.com", Version: "v1", Resource: "prometheusrules"},
+}
+
+// The labels marking the ConfigMaps picked up as dashboards by the Grafana sidecar, and by the OpenShift console.
+var dashboardLabels = []string{"grafana_dashboard", "console.openshift.io/dashboard"}
+
+//nolint:gocritic // hugeParam: info - purposely passed by value.
+func gatherMonitoring(dataType string, info Info) bool {
+	switch dataType {
+	case Resources:
+		gatherMonitoringResources(&info)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// gatherMonitoringResources gathers the ServiceMonitors, PodMonitors and PrometheusRules, when their CRDs are installed,
+// and the dashboard ConfigMaps in the Submariner namespaces.
+func gatherMonitoringResources(info *Info) {
+	if err := os.MkdirAll(filepath.Join(info.DirName, monitoringSubDir), 0o700); err != nil {
+		info.Status.Failure("Error creating the monitoring sub-directory: %v", err)
+		return
+	}
+
+	info.subDir = monitoringSubDir
+	defer func() {
+		info.subDir = ""
+	}()
+
+	served := []schema.GroupVersionResource{}
+
+	for _, resource := range monitoringResources {
+		if isResourceServed(info, resource) {
+			served = append(served, resource)
+		}
+	}
+
+	if len(served) == 0 {
+		info.Status.Success("The monitoring CRDs aren't installed, no monitoring stack integration to gather")
+	}
+
+	for _, namespace := range info.namespaces {
+		for _, resource := range served {
+			ResourcesToYAMLFile(info, resource, namespace, metav1.ListOptions{})
+		}
+
+		for _, label := range dashboardLabels {
+			gatherConfigMaps(info, namespace, metav1.ListOptions{LabelSelector: label})
+		}
+	}
+}
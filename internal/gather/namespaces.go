@@ -0,0 +1,71 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"context"
+
+	"github.com/submariner-io/admiral/pkg/reporter"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// resolveNamespaces returns the Submariner namespaces detected in the cluster, followed by the given additional
+// namespaces, without duplicates. Additional namespaces which don't exist are skipped with a warning.
+func resolveNamespaces(info *Info, additional []string, status reporter.Interface) []string {
+	namespaces := []string{info.OperatorNamespace()}
+	seen := sets.New(namespaces...)
+
+	add := func(namespace string) {
+		if namespace != "" && !seen.Has(namespace) {
+			seen.Insert(namespace)
+			namespaces = append(namespaces, namespace)
+		}
+	}
+
+	if info.Submariner != nil {
+		add(info.Submariner.Spec.Namespace)
+	}
+
+	if info.ServiceDiscovery != nil {
+		add(info.ServiceDiscovery.Spec.Namespace)
+	}
+
+	for _, namespace := range additional {
+		if seen.Has(namespace) {
+			continue
+		}
+
+		_, err := info.ClientProducer.ForKubernetes().CoreV1().Namespaces().Get(context.TODO(), namespace, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			status.Warning("Skipping namespace %q which doesn't exist", namespace)
+			continue
+		}
+
+		if err != nil {
+			status.Warning("Skipping namespace %q which couldn't be retrieved: %v", namespace, err)
+			continue
+		}
+
+		add(namespace)
+	}
+
+	return namespaces
+}
@@ -0,0 +1,303 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/network"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	networkSummaryFileName = "network-summary.json"
+	linkMTUCmd             = "ip -o link show"
+)
+
+// The DNS services deployed by the various distributions in kube-system.
+var dnsServiceNames = []string{"kube-dns", "coredns", "rke2-coredns-rke2-coredns"}
+
+// Matches the interface name and MTU in the output of "ip -o link show", e.g.
+// "2: eth0@if9: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1450 qdisc noqueue state UP ...".
+var linkMTURegexp = regexp.MustCompile(`^\d+:\s+([^:@\s]+)(?:@[^:\s]+)?:\s+<[^>]*>\s+mtu\s+(\d+)`)
+
+// NetworkSummary records what's needed to rule in or out MTU mismatches and CIDR overlaps.
+type NetworkSummary struct {
+	NetworkPlugin string `json:"networkPlugin,omitempty"`
+	// Source describes where the CIDRs were obtained: the Submariner resource, or the network discovery.
+	Source        string        `json:"source"`
+	ClusterCIDRs  []string      `json:"clusterCIDRs"`
+	ServiceCIDRs  []string      `json:"serviceCIDRs"`
+	GlobalCIDR    string        `json:"globalCIDR,omitempty"`
+	DNSServiceIPs []string      `json:"dnsServiceIPs"`
+	Nodes         []NodeNetwork `json:"nodes"`
+	Warnings      []string      `json:"warnings,omitempty"`
+}
+
+// NodeNetwork records a node's pod CIDRs and addresses, and the MTU of its interfaces when a route agent pod runs on it.
+type NodeNetwork struct {
+	Name        string         `json:"name"`
+	PodCIDRs    []string       `json:"podCIDRs"`
+	InternalIPs []string       `json:"internalIPs"`
+	Interfaces  []InterfaceMTU `json:"interfaces,omitempty"`
+	Error       string         `json:"error,omitempty"`
+}
+
+type InterfaceMTU struct {
+	Name string `json:"name"`
+	MTU  int    `json:"mtu"`
+}
+
+// gatherNetworkSummary writes the cluster's pod, service and DNS service addresses, and each node's interface MTUs as seen
+// from the route agent pods, to the network summary file.
+func gatherNetworkSummary(info *Info) {
+	summary := NetworkSummary{DNSServiceIPs: []string{}, Nodes: []NodeNetwork{}}
+
+	summarizeClusterCIDRs(info, &summary)
+	summarizeDNSServices(info, &summary)
+
+	if err := summarizeNodes(info, &summary); err != nil {
+		info.Status.Failure("Error summarizing the node networks: %v", err)
+	}
+
+	summary.Warnings = append(summary.Warnings, cidrOverlapWarnings(&summary)...)
+	summary.Warnings = append(summary.Warnings, mtuMismatchWarnings(&summary)...)
+
+	for _, warning := range summary.Warnings {
+		info.Status.Warning("%s", warning)
+	}
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		info.Status.Failure("Error marshalling the network summary: %v", err)
+		return
+	}
+
+	fileName := filepath.Join(info.subDir, networkSummaryFileName)
+
+	err = os.WriteFile(filepath.Join(info.DirName, fileName), []byte(scrubSensitiveData(info, string(data))), 0o600)
+	if err != nil {
+		info.Status.Failure("Error writing the network summary to %q: %v", fileName, err)
+		return
+	}
+
+	info.addArtifact(fileName, info.redactsAny())
+	info.Status.Success("Summarized the network of %d nodes in %q", len(summary.Nodes), fileName)
+}
+
+func summarizeClusterCIDRs(info *Info, summary *NetworkSummary) {
+	if info.Submariner != nil && info.Submariner.Status.ClusterCIDR != "" {
+		summary.Source = "Submariner resource"
+		summary.NetworkPlugin = info.Submariner.Status.NetworkPlugin
+		summary.ClusterCIDRs = splitCIDRs(info.Submariner.Status.ClusterCIDR)
+		summary.ServiceCIDRs = splitCIDRs(info.Submariner.Status.ServiceCIDR)
+		summary.GlobalCIDR = info.Submariner.Status.GlobalCIDR
+
+		return
+	}
+
+	summary.Source = "network discovery"
+
+	clusterNetwork, err := network.Discover(context.TODO(), info.ClientProducer.ForGeneral(), info.OperatorNamespace())
+	if err != nil || clusterNetwork == nil {
+		info.Status.Warning("Unable to discover the cluster network: %v", err)
+		return
+	}
+
+	summary.NetworkPlugin = clusterNetwork.NetworkPlugin
+	summary.ClusterCIDRs = clusterNetwork.PodCIDRs
+	summary.ServiceCIDRs = clusterNetwork.ServiceCIDRs
+	summary.GlobalCIDR = clusterNetwork.GlobalCIDR
+}
+
+func splitCIDRs(cidrs string) []string {
+	return strings.Split(cidrs, ",")
+}
+
+func summarizeDNSServices(info *Info, summary *NetworkSummary) {
+	for _, name := range dnsServiceNames {
+		service, err := info.ClientProducer.ForKubernetes().CoreV1().Services(metav1.NamespaceSystem).Get(context.TODO(), name,
+			metav1.GetOptions{})
+		if err != nil {
+			continue
+		}
+
+		summary.DNSServiceIPs = append(summary.DNSServiceIPs, service.Spec.ClusterIPs...)
+	}
+}
+
+func summarizeNodes(info *Info, summary *NetworkSummary) error {
+	nodes, err := listNodes(info, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	routeAgents := map[string]*corev1.Pod{}
+
+	pods, err := findPods(info.ClientProducer.ForKubernetes(), routeagentPodLabel)
+	if err != nil {
+		info.Status.Warning("Unable to find the route agent pods, the node MTUs won't be recorded: %v", err)
+	} else {
+		for i := range pods.Items {
+			routeAgents[pods.Items[i].Spec.NodeName] = &pods.Items[i]
+		}
+	}
+
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+
+		nodeNetwork := NodeNetwork{Name: node.Name, PodCIDRs: node.Spec.PodCIDRs, InternalIPs: []string{}}
+
+		for _, address := range node.Status.Addresses {
+			if address.Type == corev1.NodeInternalIP {
+				nodeNetwork.InternalIPs = append(nodeNetwork.InternalIPs, address.Address)
+			}
+		}
+
+		if pod, found := routeAgents[node.Name]; found {
+			nodeNetwork.Interfaces, err = interfaceMTUs(info, pod)
+			if err != nil {
+				nodeNetwork.Error = err.Error()
+			}
+		} else {
+			nodeNetwork.Error = "no route agent pod runs on the node, its interface MTUs are unavailable"
+		}
+
+		summary.Nodes = append(summary.Nodes, nodeNetwork)
+	}
+
+	return nil
+}
+
+// interfaceMTUs lists the MTU of the interfaces of the node on which the given host-networked pod runs.
+func interfaceMTUs(info *Info, pod *corev1.Pod) ([]InterfaceMTU, error) {
+	stdOut, _, err := execCmdInBash(info, pod, linkMTUCmd)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error running %q on pod %q", linkMTUCmd, pod.Name)
+	}
+
+	interfaces := []InterfaceMTU{}
+
+	for _, line := range strings.Split(stdOut, "\n") {
+		match := linkMTURegexp.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		mtu, err := strconv.Atoi(match[2])
+		if err != nil {
+			continue
+		}
+
+		interfaces = append(interfaces, InterfaceMTU{Name: match[1], MTU: mtu})
+	}
+
+	return interfaces, nil
+}
+
+func cidrOverlapWarnings(summary *NetworkSummary) []string {
+	warnings := []string{}
+
+	for _, clusterCIDR := range summary.ClusterCIDRs {
+		for _, serviceCIDR := range summary.ServiceCIDRs {
+			if cidrsOverlap(clusterCIDR, serviceCIDR) {
+				warnings = append(warnings, fmt.Sprintf("The cluster CIDR %s overlaps the service CIDR %s", clusterCIDR, serviceCIDR))
+			}
+		}
+	}
+
+	for i := range summary.Nodes {
+		for _, podCIDR := range summary.Nodes[i].PodCIDRs {
+			if len(summary.ClusterCIDRs) > 0 && !cidrWithinAny(podCIDR, summary.ClusterCIDRs) {
+				warnings = append(warnings, fmt.Sprintf("The pod CIDR %s of node %q isn't within the cluster CIDRs %s", podCIDR,
+					summary.Nodes[i].Name, strings.Join(summary.ClusterCIDRs, ",")))
+			}
+		}
+	}
+
+	return warnings
+}
+
+func cidrsOverlap(first, second string) bool {
+	_, firstNet, err := net.ParseCIDR(first)
+	if err != nil {
+		return false
+	}
+
+	_, secondNet, err := net.ParseCIDR(second)
+	if err != nil {
+		return false
+	}
+
+	return firstNet.Contains(secondNet.IP) || secondNet.Contains(firstNet.IP)
+}
+
+func cidrWithinAny(cidr string, ranges []string) bool {
+	ip, _, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return true
+	}
+
+	for _, r := range ranges {
+		if _, ipNet, err := net.ParseCIDR(r); err == nil && ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mtuMismatchWarnings flags the interfaces which are present on several nodes with different MTUs.
+func mtuMismatchWarnings(summary *NetworkSummary) []string {
+	warnings := []string{}
+	interfaceMTUs := map[string]sets.Set[int]{}
+
+	for i := range summary.Nodes {
+		for _, iface := range summary.Nodes[i].Interfaces {
+			if interfaceMTUs[iface.Name] == nil {
+				interfaceMTUs[iface.Name] = sets.New[int]()
+			}
+
+			interfaceMTUs[iface.Name].Insert(iface.MTU)
+		}
+	}
+
+	for _, name := range sets.List(sets.KeySet(interfaceMTUs)) {
+		if interfaceMTUs[name].Len() < 2 {
+			continue
+		}
+
+		mtus := sets.List(interfaceMTUs[name])
+
+		warnings = append(warnings, fmt.Sprintf("The interface %q has different MTUs across the nodes: %v", name, mtus))
+	}
+
+	return warnings
+}
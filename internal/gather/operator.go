@@ -19,9 +19,15 @@ limitations under the License.
 package gather
 
 import (
+	"context"
+	"strings"
+	"time"
+
 	"github.com/submariner-io/subctl/pkg/operator/deployment"
 	submarinerOp "github.com/submariner-io/submariner-operator/api/v1alpha1"
 	"github.com/submariner-io/submariner-operator/pkg/names"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 )
@@ -75,3 +81,75 @@ func gatherSubmarinerOperatorPodLogs(info *Info) {
 		gatherPodLogs(labelSelector, info)
 	}
 }
+
+func gatherSubmarinerOperatorPodMetrics(info *Info) {
+	labelSelector, err := deployment.GetPodLabelSelector(info.ClientProducer.ForKubernetes(), info.OperatorNamespace())
+	if err != nil {
+		info.Status.Failure("Failed to obtain the operator deployment label: %s", err)
+		return
+	}
+
+	if labelSelector != "" {
+		gatherPodMetrics(info, labelSelector, operatorMetricsPort)
+	}
+}
+
+// gatherLeases gathers the leader election leases in the namespace, such as the operator's and the gateways', and warns
+// about those which have expired or whose holder isn't a running pod in the namespace.
+func gatherLeases(info *Info, namespace string) {
+	ResourcesToYAMLFile(info, coordinationv1.SchemeGroupVersion.WithResource("leases"), namespace, metav1.ListOptions{})
+
+	leases, err := info.ClientProducer.ForKubernetes().CoordinationV1().Leases(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		info.Status.Failure("Error listing the leases in namespace %q: %v", namespace, err)
+		return
+	}
+
+	if len(leases.Items) == 0 {
+		return
+	}
+
+	pods, err := info.ClientProducer.ForKubernetes().CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		info.Status.Failure("Error listing the pods in namespace %q: %v", namespace, err)
+		return
+	}
+
+	for i := range leases.Items {
+		checkLease(info, &leases.Items[i], pods.Items)
+	}
+}
+
+func checkLease(info *Info, lease *coordinationv1.Lease, pods []corev1.Pod) {
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == "" {
+		info.Status.Warning("Lease %q in namespace %q has no holder", lease.Name, lease.Namespace)
+		return
+	}
+
+	holder := *lease.Spec.HolderIdentity
+
+	if lease.Spec.RenewTime != nil && lease.Spec.LeaseDurationSeconds != nil {
+		expiry := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+		if time.Now().After(expiry) {
+			info.Status.Warning("Lease %q in namespace %q, held by %q, expired at %s", lease.Name, lease.Namespace, holder,
+				expiry.Format(time.RFC3339))
+		}
+	}
+
+	// Holder identities are typically the pod name, possibly followed by "_" and a unique identifier
+	podName, _, _ := strings.Cut(holder, "_")
+
+	for i := range pods {
+		if pods[i].Name == podName {
+			if pods[i].Status.Phase != corev1.PodRunning {
+				info.Status.Warning("Lease %q in namespace %q is held by pod %q which is %s", lease.Name, lease.Namespace,
+					podName, pods[i].Status.Phase)
+			}
+
+			return
+		}
+	}
+
+	info.Status.Warning("Lease %q in namespace %q is held by %q, which doesn't match any pod in the namespace", lease.Name,
+		lease.Namespace, holder)
+}
@@ -0,0 +1,235 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+)
+
+const redactedCABundle = "##redacted-ca-bundle##"
+
+// The API groups of the resources created and used by Submariner; webhooks on other groups can't intercept them.
+var submarinerAPIGroups = sets.New("", "apps", "discovery.k8s.io", "submariner.io", "multicluster.x-k8s.io", "*")
+
+// admissionWebhook holds the fields of validating and mutating webhooks which determine what they intercept.
+type admissionWebhook struct {
+	name              string
+	rules             []admissionregistrationv1.RuleWithOperations
+	namespaceSelector *metav1.LabelSelector
+}
+
+func gatherNetworkPolicies(info *Info, namespace string) {
+	ResourcesToYAMLFile(info, networkingv1.SchemeGroupVersion.WithResource("networkpolicies"), namespace, metav1.ListOptions{})
+}
+
+// gatherAdmissionWebhooks gathers the validating and mutating webhook configurations with at least one webhook which
+// could intercept Submariner resources in the Submariner namespaces. The namespaces each webhook intercepts are
+// recorded at the top of the file.
+func gatherAdmissionWebhooks(info *Info) {
+	namespaceLabels, err := submarinerNamespaceLabels(info)
+	if err != nil {
+		info.Status.Failure("Error retrieving the Submariner namespaces: %v", err)
+		return
+	}
+
+	admissionClient := info.ClientProducer.ForKubernetes().AdmissionregistrationV1()
+
+	validating, err := admissionClient.ValidatingWebhookConfigurations().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		info.Status.Failure("Error listing the validating webhook configurations: %v", err)
+	} else {
+		found := 0
+
+		for i := range validating.Items {
+			config := &validating.Items[i]
+			webhooks := make([]admissionWebhook, len(config.Webhooks))
+
+			for j := range config.Webhooks {
+				webhooks[j] = admissionWebhook{config.Webhooks[j].Name, config.Webhooks[j].Rules, config.Webhooks[j].NamespaceSelector}
+			}
+
+			config.TypeMeta = metav1.TypeMeta{APIVersion: admissionregistrationv1.SchemeGroupVersion.String(),
+				Kind: "ValidatingWebhookConfiguration"}
+
+			found += writeInterceptingWebhooks(info, "validatingwebhookconfigurations", config, webhooks, namespaceLabels)
+		}
+
+		info.Status.Success("Found %d validating webhook configurations which could intercept Submariner resources", found)
+	}
+
+	mutating, err := admissionClient.MutatingWebhookConfigurations().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		info.Status.Failure("Error listing the mutating webhook configurations: %v", err)
+		return
+	}
+
+	found := 0
+
+	for i := range mutating.Items {
+		config := &mutating.Items[i]
+		webhooks := make([]admissionWebhook, len(config.Webhooks))
+
+		for j := range config.Webhooks {
+			webhooks[j] = admissionWebhook{config.Webhooks[j].Name, config.Webhooks[j].Rules, config.Webhooks[j].NamespaceSelector}
+		}
+
+		config.TypeMeta = metav1.TypeMeta{APIVersion: admissionregistrationv1.SchemeGroupVersion.String(),
+			Kind: "MutatingWebhookConfiguration"}
+
+		found += writeInterceptingWebhooks(info, "mutatingwebhookconfigurations", config, webhooks, namespaceLabels)
+	}
+
+	info.Status.Success("Found %d mutating webhook configurations which could intercept Submariner resources", found)
+}
+
+func submarinerNamespaceLabels(info *Info) (map[string]labels.Set, error) {
+	namespaceLabels := map[string]labels.Set{}
+
+	for _, namespace := range info.namespaces {
+		ns, err := info.ClientProducer.ForKubernetes().CoreV1().Namespaces().Get(context.TODO(), namespace, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "error retrieving namespace %q", namespace)
+		}
+
+		namespaceLabels[namespace] = ns.Labels
+	}
+
+	return namespaceLabels, nil
+}
+
+// writeInterceptingWebhooks writes the given webhook configuration if any of its webhooks intercepts Submariner resources,
+// and returns the number of configurations written (0 or 1).
+func writeInterceptingWebhooks(info *Info, resource string, config metav1.Object, webhooks []admissionWebhook,
+	namespaceLabels map[string]labels.Set,
+) int {
+	header := []string{}
+
+	for i := range webhooks {
+		intercepted := interceptedNamespaces(&webhooks[i], namespaceLabels)
+		if len(intercepted) > 0 {
+			header = append(header, fmt.Sprintf("# Webhook %q can intercept resources in namespaces %s", webhooks[i].name,
+				strings.Join(intercepted, ", ")))
+		}
+	}
+
+	if len(header) == 0 {
+		return 0
+	}
+
+	if err := writeWebhookConfiguration(info, resource, config, header); err != nil {
+		info.Status.Failure("Error writing %s %q: %v", resource, config.GetName(), err)
+		return 0
+	}
+
+	return 1
+}
+
+// interceptedNamespaces returns the Submariner namespaces whose resources the webhook can intercept, given its rules' API
+// groups and its namespace selector; webhooks without a namespace selector apply to all namespaces.
+func interceptedNamespaces(webhook *admissionWebhook, namespaceLabels map[string]labels.Set) []string {
+	matchesGroups := false
+
+	for i := range webhook.rules {
+		for _, group := range webhook.rules[i].APIGroups {
+			if submarinerAPIGroups.Has(group) {
+				matchesGroups = true
+			}
+		}
+	}
+
+	if !matchesGroups {
+		return nil
+	}
+
+	selector := labels.Everything()
+
+	if webhook.namespaceSelector != nil {
+		var err error
+
+		selector, err = metav1.LabelSelectorAsSelector(webhook.namespaceSelector)
+		if err != nil {
+			// The API server would reject such a selector, assume the worst
+			selector = labels.Everything()
+		}
+	}
+
+	intercepted := []string{}
+
+	for namespace, nsLabels := range namespaceLabels {
+		if selector.Matches(nsLabels) {
+			intercepted = append(intercepted, namespace)
+		}
+	}
+
+	return sets.List(sets.New(intercepted...))
+}
+
+// writeWebhookConfiguration writes the webhook configuration, with the CA bundles redacted if certificates are.
+func writeWebhookConfiguration(info *Info, resource string, config metav1.Object, header []string) error {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(config)
+	if err != nil {
+		return errors.Wrap(err, "error converting the webhook configuration")
+	}
+
+	if info.redacts(RedactCertificates) {
+		webhooks, _ := content["webhooks"].([]interface{})
+		for _, webhook := range webhooks {
+			if clientConfig, ok := webhook.(map[string]interface{})["clientConfig"].(map[string]interface{}); ok {
+				if _, found := clientConfig["caBundle"]; found {
+					clientConfig["caBundle"] = redactedCABundle
+				}
+			}
+		}
+	}
+
+	data, err := yaml.Marshal(content)
+	if err != nil {
+		return errors.Wrap(err, "error marshaling to YAML")
+	}
+
+	name := escapeFileName(resource+"__"+config.GetName()) + ".yaml"
+	fileContent := strings.Join(header, "\n") + "\n" + scrubSensitiveData(info, string(data))
+
+	if err := os.WriteFile(filepath.Join(info.DirName, name), []byte(fileContent), 0o600); err != nil {
+		return errors.Wrapf(err, "error writing to file %s", name)
+	}
+
+	info.addArtifact(name, info.redactsAny())
+
+	info.Summary.Resources = append(info.Summary.Resources, ResourceInfo{
+		Name:     config.GetName(),
+		Type:     resource,
+		FileName: name,
+	})
+
+	return nil
+}
@@ -0,0 +1,133 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/subctl/pkg/client"
+	"k8s.io/client-go/rest"
+)
+
+// The default rate limits are conservative, so that gathering doesn't get throttled by medium-sized clusters' API servers.
+const (
+	DefaultQPS   = 10
+	DefaultBurst = 20
+)
+
+const (
+	maxThrottledRetries = 5
+	maxThrottledBackoff = 30 * time.Second
+)
+
+// throttlingRoundTripper retries the requests rejected with 429 Too Many Requests, backing off as requested by the server,
+// and counts them so that the throttling can be reported once instead of for each request.
+type throttlingRoundTripper struct {
+	delegate  http.RoundTripper
+	throttled *atomic.Int32
+}
+
+func (t *throttlingRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	backoff := time.Second
+
+	for attempt := 0; ; attempt++ {
+		response, err := t.delegate.RoundTrip(request)
+
+		// Requests with a body can't be replayed
+		if err != nil || response.StatusCode != http.StatusTooManyRequests || attempt == maxThrottledRetries || request.Body != nil {
+			return response, err //nolint:wrapcheck // No need to wrap here
+		}
+
+		t.throttled.Add(1)
+
+		delay := retryAfter(response, backoff)
+
+		_, _ = io.Copy(io.Discard, response.Body)
+		response.Body.Close()
+
+		select {
+		case <-request.Context().Done():
+			return nil, errors.Wrap(request.Context().Err(), "interrupted while backing off from the API server's throttling")
+		case <-time.After(delay):
+		}
+
+		if backoff *= 2; backoff > maxThrottledBackoff {
+			backoff = maxThrottledBackoff
+		}
+	}
+}
+
+// retryAfter returns the delay requested by the server, in seconds, if any, otherwise the given backoff.
+func retryAfter(response *http.Response, backoff time.Duration) time.Duration {
+	seconds, err := strconv.Atoi(response.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return backoff
+	}
+
+	if delay := time.Duration(seconds) * time.Second; delay < maxThrottledBackoff {
+		return delay
+	}
+
+	return maxThrottledBackoff
+}
+
+// rateLimitedConfig returns a copy of the given configuration limited to the gather's request rate, and backing off when
+// throttled by the server.
+func (info *Info) rateLimitedConfig(config *rest.Config) *rest.Config {
+	limited := rest.CopyConfig(config)
+
+	limited.QPS = info.qps
+	limited.Burst = info.burst
+	limited.RateLimiter = nil
+	limited.Wrap(func(delegate http.RoundTripper) http.RoundTripper {
+		return &throttlingRoundTripper{delegate: delegate, throttled: info.throttled}
+	})
+
+	return limited
+}
+
+// applyRateLimit switches the cluster's clients to a rate-limited configuration.
+func applyRateLimit(info *Info, options *Options) error {
+	info.qps = options.QPS
+	if info.qps <= 0 {
+		info.qps = DefaultQPS
+	}
+
+	info.burst = options.Burst
+	if info.burst <= 0 {
+		info.burst = DefaultBurst
+	}
+
+	info.throttled = &atomic.Int32{}
+	info.RestConfig = info.rateLimitedConfig(info.RestConfig)
+
+	producer, err := client.NewProducerFromRestConfig(info.RestConfig)
+	if err != nil {
+		return errors.Wrap(err, "error creating the rate-limited clients")
+	}
+
+	info.ClientProducer = producer
+
+	return nil
+}
@@ -0,0 +1,90 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// The categories of sensitive data which can be redacted.
+const (
+	RedactTokens           = "tokens"
+	RedactPSKs             = "psks"
+	RedactCloudCredentials = "cloud-credentials"
+	RedactPrivateKeys      = "private-keys"
+	RedactCertificates     = "certificates"
+	RedactEndpoints        = "endpoints"
+)
+
+var redactionDescriptions = map[string]string{
+	RedactTokens:           "the broker API server tokens",
+	RedactPSKs:             "the IPsec pre-shared keys",
+	RedactCloudCredentials: "the cloud and database credentials, such as those embedded in OVN database connection strings",
+	RedactPrivateKeys:      "the PEM-encoded private keys",
+	RedactCertificates:     "the broker CA and the admission webhooks' CA bundles",
+	RedactEndpoints:        "the broker API server URL",
+}
+
+// AllRedactionCategories are the categories of sensitive data redacted by default.
+var AllRedactionCategories = sets.KeySet(redactionDescriptions)
+
+var privateKeyRegexp = regexp.MustCompile(`(?s)-----BEGIN ([A-Z ]*)PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`)
+
+// RedactionCategories returns the descriptions of the categories of sensitive data which can be redacted, sorted by name.
+func RedactionCategories() []Description {
+	return describe(redactionDescriptions)
+}
+
+// ValidateRedaction checks that the given redaction categories are known.
+func ValidateRedaction(categories []string) error {
+	unknown := sets.New(categories...).Difference(AllRedactionCategories)
+	if unknown.Len() > 0 {
+		return fmt.Errorf("unknown redaction categories %s; the categories are %s", strings.Join(sets.List(unknown), ", "),
+			strings.Join(sets.List(AllRedactionCategories), ", "))
+	}
+
+	return nil
+}
+
+// redactionPolicy returns the categories to redact: those explicitly requested, otherwise all of them unless sensitive data
+// is included.
+func redactionPolicy(options *Options) sets.Set[string] {
+	if options.Redact != nil {
+		return sets.New(options.Redact...)
+	}
+
+	if options.IncludeSensitiveData {
+		return sets.New[string]()
+	}
+
+	return AllRedactionCategories.Clone()
+}
+
+func (info *Info) redacts(category string) bool {
+	return info.redaction.Has(category)
+}
+
+// redactsAny returns true if any category is redacted; the artifacts are then recorded as redacted.
+func (info *Info) redactsAny() bool {
+	return info.redaction.Len() > 0
+}
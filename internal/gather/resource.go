@@ -30,6 +30,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/yaml"
 )
@@ -38,6 +39,8 @@ var fileNameRegexp = regexp.MustCompile(`[<>:"/\|?*]`)
 
 //nolint:gocritic // hugeParam: listOptions - match K8s API.
 func ResourcesToYAMLFile(info *Info, ofType schema.GroupVersionResource, namespace string, listOptions metav1.ListOptions) {
+	listOptions.LabelSelector = info.withSelector(listOptions.LabelSelector)
+
 	err := func() error {
 		list, err := info.ClientProducer.ForDynamic().Resource(ofType).Namespace(namespace).List(context.TODO(), listOptions)
 		if err != nil {
@@ -57,7 +60,7 @@ func ResourcesToYAMLFile(info *Info, ofType schema.GroupVersionResource, namespa
 		for i := range list.Items {
 			item := &list.Items[i]
 
-			name := escapeFileName(ofType.Resource+"_"+item.GetNamespace()+"_"+item.GetName()) + ".yaml"
+			name := filepath.Join(info.subDir, escapeFileName(ofType.Resource+"_"+item.GetNamespace()+"_"+item.GetName())+".yaml")
 			path := filepath.Join(info.DirName, name)
 
 			file, err := os.Create(path)
@@ -79,6 +82,9 @@ func ResourcesToYAMLFile(info *Info, ofType schema.GroupVersionResource, namespa
 				return errors.WithMessagef(err, "error writing to file %s", path)
 			}
 
+			info.addArtifact(name, info.redactsAny())
+			info.recordSecretReferences(ofType.Resource, item)
+
 			info.Summary.Resources = append(info.Summary.Resources, ResourceInfo{
 				Name:      item.GetName(),
 				Namespace: item.GetNamespace(),
@@ -94,6 +100,26 @@ func ResourcesToYAMLFile(info *Info, ofType schema.GroupVersionResource, namespa
 	}
 }
 
+// ValidateSelector checks that the given label selector, if any, is valid.
+func ValidateSelector(selector string) error {
+	_, err := labels.Parse(selector)
+
+	return errors.Wrapf(err, "invalid label selector %q", selector)
+}
+
+// withSelector restricts the given label selector with the user's selector, if any.
+func (info *Info) withSelector(selector string) string {
+	if info.selector == "" {
+		return selector
+	}
+
+	if selector == "" {
+		return info.selector
+	}
+
+	return selector + "," + info.selector
+}
+
 //nolint:gocritic // hugeParam: listOptions - match K8s API.
 func gatherDaemonSet(info *Info, namespace string, listOptions metav1.ListOptions) {
 	ResourcesToYAMLFile(info, appsv1.SchemeGroupVersion.WithResource("daemonsets"), namespace, listOptions)
@@ -109,20 +135,37 @@ func gatherConfigMaps(info *Info, namespace string, listOptions metav1.ListOptio
 	ResourcesToYAMLFile(info, corev1.SchemeGroupVersion.WithResource("configmaps"), namespace, listOptions)
 }
 
+// scrubSensitiveData redacts the categories of sensitive data selected by the redaction policy.
 func scrubSensitiveData(info *Info, dataString string) string {
-	if info.IncludeSensitiveData {
-		return dataString
-	}
+	var apiServer, token, ca, psk string
 
 	if info.Submariner != nil {
-		dataString = replaceIfNotEmpty(dataString, info.Submariner.Spec.BrokerK8sApiServer, "##redacted-api-server##")
-		dataString = replaceIfNotEmpty(dataString, info.Submariner.Spec.BrokerK8sApiServerToken, "##redacted-token##")
-		dataString = replaceIfNotEmpty(dataString, info.Submariner.Spec.BrokerK8sCA, "##redacted-ca##")
-		dataString = replaceIfNotEmpty(dataString, info.Submariner.Spec.CeIPSecPSK, "##redacted-ipsec-psk##")
+		apiServer, token, ca = info.Submariner.Spec.BrokerK8sApiServer, info.Submariner.Spec.BrokerK8sApiServerToken,
+			info.Submariner.Spec.BrokerK8sCA
+		psk = info.Submariner.Spec.CeIPSecPSK
 	} else if info.ServiceDiscovery != nil {
-		dataString = replaceIfNotEmpty(dataString, info.ServiceDiscovery.Spec.BrokerK8sApiServer, "##redacted-api-server##")
-		dataString = replaceIfNotEmpty(dataString, info.ServiceDiscovery.Spec.BrokerK8sApiServerToken, "##redacted-token##")
-		dataString = replaceIfNotEmpty(dataString, info.ServiceDiscovery.Spec.BrokerK8sCA, "##redacted-ca##")
+		apiServer, token, ca = info.ServiceDiscovery.Spec.BrokerK8sApiServer, info.ServiceDiscovery.Spec.BrokerK8sApiServerToken,
+			info.ServiceDiscovery.Spec.BrokerK8sCA
+	}
+
+	if info.redacts(RedactEndpoints) {
+		dataString = replaceIfNotEmpty(dataString, apiServer, "##redacted-api-server##")
+	}
+
+	if info.redacts(RedactTokens) {
+		dataString = replaceIfNotEmpty(dataString, token, "##redacted-token##")
+	}
+
+	if info.redacts(RedactCertificates) {
+		dataString = replaceIfNotEmpty(dataString, ca, "##redacted-ca##")
+	}
+
+	if info.redacts(RedactPSKs) {
+		dataString = replaceIfNotEmpty(dataString, psk, "##redacted-ipsec-psk##")
+	}
+
+	if info.redacts(RedactPrivateKeys) {
+		dataString = privateKeyRegexp.ReplaceAllString(dataString, "##redacted-${1}private-key##")
 	}
 
 	return dataString
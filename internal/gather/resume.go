@@ -0,0 +1,92 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type moduleType struct {
+	module   string
+	dataType string
+}
+
+// loadReusableArtifacts returns the artifacts recorded in the cluster's existing manifest, by module and type, for the
+// modules and types which were completed without failures and whose files are all present and non-empty. Those which
+// completed without producing any artifacts are returned with no artifacts, so that they aren't gathered again.
+func loadReusableArtifacts(info *Info) map[moduleType][]ArtifactInfo {
+	data, err := os.ReadFile(filepath.Join(info.DirName, manifestFileName))
+	if os.IsNotExist(err) {
+		fmt.Fprintf(info.stdout(), "No previous manifest found in %q, gathering all the data\n", info.DirName)
+		return nil
+	}
+
+	manifest := Manifest{}
+
+	if err == nil {
+		err = json.Unmarshal(data, &manifest)
+	}
+
+	if err != nil {
+		fmt.Fprintf(info.stdout(), "Unable to read the previous manifest in %q, gathering all the data: %v\n", info.DirName, err)
+		return nil
+	}
+
+	failed := map[moduleType]bool{}
+	for i := range manifest.Errors {
+		failed[moduleType{module: manifest.Errors[i].Module, dataType: manifest.Errors[i].Type}] = true
+	}
+
+	reusable := map[moduleType][]ArtifactInfo{}
+	incomplete := map[moduleType]bool{}
+
+	for i := range manifest.Completed {
+		key := moduleType{module: manifest.Completed[i].Module, dataType: manifest.Completed[i].Type}
+		if !failed[key] {
+			reusable[key] = []ArtifactInfo{}
+		}
+	}
+
+	for i := range manifest.Artifacts {
+		artifact := manifest.Artifacts[i]
+		key := moduleType{module: artifact.Module, dataType: artifact.Type}
+
+		if _, completed := reusable[key]; !completed {
+			continue
+		}
+
+		fileInfo, err := os.Stat(filepath.Join(info.DirName, artifact.FileName))
+		if err != nil || fileInfo.Size() == 0 {
+			incomplete[key] = true
+			continue
+		}
+
+		artifact.Reused = true
+		reusable[key] = append(reusable[key], artifact)
+	}
+
+	for key := range incomplete {
+		delete(reusable, key)
+	}
+
+	return reusable
+}
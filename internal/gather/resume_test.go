@@ -0,0 +1,112 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/internal/gather"
+)
+
+var _ = Describe("Resuming a gather", func() {
+	var (
+		dir     string
+		options gather.Options
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		options = gather.Options{
+			Modules: []string{"connectivity", "broker"},
+			Types:   []string{gather.Logs, gather.Resources},
+		}
+	})
+
+	When("the previous run was interrupted", func() {
+		BeforeEach(func() {
+			Expect(func() {
+				gather.GatherModules(dir, options, func(module, dataType string) []string {
+					switch {
+					case module == "connectivity" && dataType == gather.Logs:
+						return []string{"gateway.log"}
+					case module == "connectivity":
+						return nil
+					default:
+						panic("interrupted")
+					}
+				})
+			}).To(Panic())
+		})
+
+		It("should only gather the modules and types which weren't completed", func() {
+			var gathered []string
+
+			options.Resume = true
+
+			gather.GatherModules(dir, options, func(module, dataType string) []string {
+				gathered = append(gathered, module+"/"+dataType)
+				return nil
+			})
+
+			Expect(gathered).To(Equal([]string{"broker/logs", "broker/resources"}))
+
+			data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+			Expect(err).To(Succeed())
+
+			manifest := gather.Manifest{}
+			Expect(json.Unmarshal(data, &manifest)).To(Succeed())
+
+			Expect(manifest.Completed).To(HaveLen(4))
+			Expect(manifest.Artifacts).To(HaveExactElements(gather.ArtifactInfo{
+				Module:   "connectivity",
+				Type:     gather.Logs,
+				FileName: "gateway.log",
+				Size:     int64(len("connectivity logs")),
+				Reused:   true,
+			}))
+		})
+	})
+
+	When("a previously collected file is missing", func() {
+		BeforeEach(func() {
+			gather.GatherModules(dir, options, func(module, dataType string) []string {
+				return []string{module + "-" + dataType + ".txt"}
+			})
+
+			Expect(os.Remove(filepath.Join(dir, "broker-resources.txt"))).To(Succeed())
+		})
+
+		It("should gather its module and type again", func() {
+			var gathered []string
+
+			options.Resume = true
+
+			gather.GatherModules(dir, options, func(module, dataType string) []string {
+				gathered = append(gathered, module+"/"+dataType)
+				return []string{module + "-" + dataType + ".txt"}
+			})
+
+			Expect(gathered).To(Equal([]string{"broker/resources"}))
+		})
+	})
+})
@@ -0,0 +1,238 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+)
+
+// The referenced secrets' metadata is recorded under this pseudo-module.
+const secretsModule = "secrets"
+
+const secretReferencesFileName = "secret-references.yaml"
+
+// The Submariner and ServiceDiscovery fields naming secrets in their own namespace.
+var secretNameFields = [][]string{
+	{"spec", "brokerK8sSecret"},
+	{"spec", "ceIPSecPSKSecret"},
+}
+
+// SecretReference records the metadata of a secret referenced by the gathered resources, to check that it exists and when
+// it was last rotated; its data is never recorded.
+type SecretReference struct {
+	Name         string   `json:"name"`
+	Namespace    string   `json:"namespace"`
+	ReferencedBy []string `json:"referencedBy"`
+	Found        bool     `json:"found"`
+	// The following are only set if the secret was found.
+	Type              corev1.SecretType `json:"type,omitempty"`
+	Keys              []string          `json:"keys,omitempty"`
+	CreationTimestamp *metav1.Time      `json:"creationTimestamp,omitempty"`
+	Age               string            `json:"age,omitempty"`
+	// LastModified is the latest time recorded in the secret's managed fields, which changes when it's rotated in place.
+	LastModified *metav1.Time `json:"lastModified,omitempty"`
+}
+
+// recordSecretReferences records the secrets referenced by the given gathered resource: those named by the Submariner
+// and ServiceDiscovery resources, and those used by pods and pod templates.
+func (info *Info) recordSecretReferences(resource string, item *unstructured.Unstructured) {
+	if info.secretRefs == nil || item.GetNamespace() == "" {
+		return
+	}
+
+	referrer := fmt.Sprintf("%s %s/%s", resource, item.GetNamespace(), item.GetName())
+
+	record := func(name string) {
+		if name == "" {
+			return
+		}
+
+		key := types.NamespacedName{Namespace: item.GetNamespace(), Name: name}
+		if info.secretRefs[key] == nil {
+			info.secretRefs[key] = sets.New[string]()
+		}
+
+		info.secretRefs[key].Insert(referrer)
+	}
+
+	for _, field := range secretNameFields {
+		name, _, _ := unstructured.NestedString(item.Object, field...)
+		record(name)
+	}
+
+	podSpec, found, _ := unstructured.NestedMap(item.Object, "spec", "template", "spec")
+	if !found && item.GetKind() == "Pod" {
+		podSpec, found, _ = unstructured.NestedMap(item.Object, "spec")
+	}
+
+	if !found {
+		return
+	}
+
+	spec := &corev1.PodSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(podSpec, spec); err != nil {
+		return
+	}
+
+	for _, name := range podSpecSecretNames(spec) {
+		record(name)
+	}
+}
+
+// podSpecSecretNames returns the names of the secrets used by the pod spec's volumes, environment and image pulls.
+func podSpecSecretNames(spec *corev1.PodSpec) []string {
+	names := []string{}
+
+	for i := range spec.Volumes {
+		if spec.Volumes[i].Secret != nil {
+			names = append(names, spec.Volumes[i].Secret.SecretName)
+		}
+
+		if spec.Volumes[i].Projected != nil {
+			for j := range spec.Volumes[i].Projected.Sources {
+				if spec.Volumes[i].Projected.Sources[j].Secret != nil {
+					names = append(names, spec.Volumes[i].Projected.Sources[j].Secret.Name)
+				}
+			}
+		}
+	}
+
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+
+	for i := range containers {
+		for j := range containers[i].Env {
+			if containers[i].Env[j].ValueFrom != nil && containers[i].Env[j].ValueFrom.SecretKeyRef != nil {
+				names = append(names, containers[i].Env[j].ValueFrom.SecretKeyRef.Name)
+			}
+		}
+
+		for j := range containers[i].EnvFrom {
+			if containers[i].EnvFrom[j].SecretRef != nil {
+				names = append(names, containers[i].EnvFrom[j].SecretRef.Name)
+			}
+		}
+	}
+
+	for i := range spec.ImagePullSecrets {
+		names = append(names, spec.ImagePullSecrets[i].Name)
+	}
+
+	return names
+}
+
+// gatherSecretReferences writes the metadata of the secrets referenced by the gathered resources to a single file; the
+// secrets' keys are listed, but not their data, so this is done regardless of the redaction policy.
+func gatherSecretReferences(info *Info) {
+	if len(info.secretRefs) == 0 {
+		return
+	}
+
+	gatherPseudoModule(info, secretsModule, func() {
+		info.Status.Start("Gathering the metadata of %d referenced secret(s)", len(info.secretRefs))
+		defer info.Status.End()
+
+		references := []SecretReference{}
+		missing := 0
+
+		keys := make([]types.NamespacedName, 0, len(info.secretRefs))
+		for key := range info.secretRefs {
+			keys = append(keys, key)
+		}
+
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].String() < keys[j].String()
+		})
+
+		for _, key := range keys {
+			reference := SecretReference{
+				Name:         key.Name,
+				Namespace:    key.Namespace,
+				ReferencedBy: sets.List(info.secretRefs[key]),
+			}
+
+			secret, err := info.ClientProducer.ForKubernetes().CoreV1().Secrets(key.Namespace).Get(context.TODO(), key.Name,
+				metav1.GetOptions{})
+
+			switch {
+			case apierrors.IsNotFound(err):
+				missing++
+			case err != nil:
+				info.Status.Failure("Error retrieving secret %q: %v", key.String(), err)
+				continue
+			default:
+				setSecretMetadata(&reference, secret)
+			}
+
+			references = append(references, reference)
+		}
+
+		if missing > 0 {
+			info.Status.Warning("%d referenced secret(s) don't exist", missing)
+		}
+
+		writeSecretReferences(info, references)
+	})
+}
+
+func setSecretMetadata(reference *SecretReference, secret *corev1.Secret) {
+	reference.Found = true
+	reference.Type = secret.Type
+	reference.Keys = sets.List(sets.KeySet(secret.Data).Union(sets.KeySet(secret.StringData)))
+	reference.CreationTimestamp = secret.CreationTimestamp.DeepCopy()
+	reference.Age = duration.HumanDuration(time.Since(secret.CreationTimestamp.Time))
+
+	for i := range secret.ManagedFields {
+		if secret.ManagedFields[i].Time != nil &&
+			(reference.LastModified == nil || reference.LastModified.Before(secret.ManagedFields[i].Time)) {
+			reference.LastModified = secret.ManagedFields[i].Time.DeepCopy()
+		}
+	}
+}
+
+func writeSecretReferences(info *Info, references []SecretReference) {
+	data, err := yaml.Marshal(references)
+	if err != nil {
+		info.Status.Failure("Error marshalling the secret references: %v", err)
+		return
+	}
+
+	err = os.WriteFile(filepath.Join(info.DirName, secretReferencesFileName), []byte(scrubSensitiveData(info, string(data))), 0o600)
+	if err != nil {
+		info.Status.Failure("Error writing the secret references to %q: %v", secretReferencesFileName, err)
+		return
+	}
+
+	info.addArtifact(secretReferencesFileName, info.redactsAny())
+	info.Status.Success("Recorded the metadata of %d referenced secret(s) in %q", len(references), secretReferencesFileName)
+}
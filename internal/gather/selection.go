@@ -0,0 +1,151 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	SelectionKindType   = "type"
+	SelectionKindModule = "module"
+)
+
+// InvalidSelection is an unsupported type or module, with the closest supported name when one is close enough to be a
+// likely typo.
+type InvalidSelection struct {
+	Kind       string `json:"kind"`
+	Value      string `json:"value"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// SelectionError lists every unsupported type and module which was selected.
+type SelectionError struct {
+	Invalid []InvalidSelection `json:"invalid"`
+}
+
+func (e *SelectionError) Error() string {
+	descriptions := make([]string, len(e.Invalid))
+
+	for i := range e.Invalid {
+		descriptions[i] = fmt.Sprintf("%q is not a supported %s", e.Invalid[i].Value, e.Invalid[i].Kind)
+		if e.Invalid[i].Suggestion != "" {
+			descriptions[i] += fmt.Sprintf(" (did you mean %q?)", e.Invalid[i].Suggestion)
+		}
+	}
+
+	return strings.Join(descriptions, "; ")
+}
+
+// ValidateSelection checks all the given types and modules, returning a SelectionError listing each unsupported one.
+func ValidateSelection(types, modules []string) error {
+	selectionErr := &SelectionError{}
+
+	selectionErr.check(SelectionKindType, types, AllTypes)
+	selectionErr.check(SelectionKindModule, modules, AllModules)
+
+	if len(selectionErr.Invalid) == 0 {
+		return nil
+	}
+
+	return selectionErr
+}
+
+func (e *SelectionError) check(kind string, values []string, supported sets.Set[string]) {
+	reported := sets.New[string]()
+
+	for _, value := range values {
+		if supported.Has(value) || reported.Has(value) {
+			continue
+		}
+
+		reported.Insert(value)
+
+		e.Invalid = append(e.Invalid, InvalidSelection{Kind: kind, Value: value, Suggestion: ClosestMatch(value, supported)})
+	}
+}
+
+// ClosestMatch returns the candidate with the smallest edit distance to the given value, ignoring case, or an empty string
+// if none is close enough: at most a third of the value's length, and no more than 3 edits.
+func ClosestMatch(value string, candidates sets.Set[string]) string {
+	value = strings.ToLower(value)
+
+	maxDistance := len(value) / 3
+	if maxDistance > 3 {
+		maxDistance = 3
+	}
+
+	best := ""
+	bestDistance := maxDistance + 1
+
+	// The candidates are sorted so that ties are broken consistently
+	for _, candidate := range sets.List(candidates) {
+		if distance := levenshtein(value, strings.ToLower(candidate)); distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+
+	return best
+}
+
+// levenshtein returns the minimum number of single-character insertions, deletions and substitutions turning a into b.
+func levenshtein(a, b string) int {
+	source := []rune(a)
+	target := []rune(b)
+
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := range source {
+		current[0] = i + 1
+
+		for j := range target {
+			cost := 1
+			if source[i] == target[j] {
+				cost = 0
+			}
+
+			current[j+1] = minInt(previous[j+1]+1, current[j]+1, previous[j]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(target)]
+}
+
+func minInt(first int, others ...int) int {
+	result := first
+
+	for _, other := range others {
+		if other < result {
+			result = other
+		}
+	}
+
+	return result
+}
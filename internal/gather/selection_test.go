@@ -0,0 +1,75 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/internal/component"
+	"github.com/submariner-io/subctl/internal/gather"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+var _ = Describe("ClosestMatch", func() {
+	candidates := sets.New(component.Connectivity, component.ServiceDiscovery, component.Broker, component.Operator, gather.CNI)
+
+	DescribeTable("should suggest the intended name for common typos",
+		func(value, expected string) {
+			Expect(gather.ClosestMatch(value, candidates)).To(Equal(expected))
+		},
+		Entry("a missing letter", "conectivity", component.Connectivity),
+		Entry("an extra letter", "brokerr", component.Broker),
+		Entry("swapped letters", "opertaor", component.Operator),
+		Entry("a substituted letter", "service-discovary", component.ServiceDiscovery),
+		Entry("a different case", "Broker", component.Broker),
+		Entry("a missing separator", "servicediscovery", component.ServiceDiscovery),
+	)
+
+	When("no candidate is close enough", func() {
+		It("should not suggest anything", func() {
+			Expect(gather.ClosestMatch("xyz", candidates)).To(BeEmpty())
+			Expect(gather.ClosestMatch("network", candidates)).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("ValidateSelection", func() {
+	When("all the types and modules are supported", func() {
+		It("should succeed", func() {
+			Expect(gather.ValidateSelection([]string{gather.Logs}, []string{component.Broker})).To(Succeed())
+		})
+	})
+
+	When("several types and modules are unsupported", func() {
+		It("should list each of them with their suggestions", func() {
+			err := gather.ValidateSelection([]string{"logz", gather.Resources, "logz"}, []string{"brokr", "xyz"})
+
+			var selectionErr *gather.SelectionError
+			Expect(errors.As(err, &selectionErr)).To(BeTrue())
+			Expect(selectionErr.Invalid).To(Equal([]gather.InvalidSelection{
+				{Kind: gather.SelectionKindType, Value: "logz", Suggestion: gather.Logs},
+				{Kind: gather.SelectionKindModule, Value: "brokr", Suggestion: component.Broker},
+				{Kind: gather.SelectionKindModule, Value: "xyz"},
+			}))
+			Expect(err).To(MatchError(ContainSubstring(`"brokr" is not a supported module (did you mean "broker"?)`)))
+		})
+	})
+})
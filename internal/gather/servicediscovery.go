@@ -31,13 +31,19 @@ import (
 
 const (
 	lighthouseComponentsLabel = "component=submariner-lighthouse"
+	lighthouseAgentPodLabel   = "app=submariner-lighthouse-agent"
+	lighthouseCoreDNSPodLabel = "app=submariner-lighthouse-coredns"
 	k8sCoreDNSPodLabel        = "k8s-app=kube-dns"
 	ocpCoreDNSPodLabel        = "dns.operator.openshift.io/daemonset-dns=default"
 	internalSvcLabel          = "submariner.io/exportedServiceRef"
 )
 
 func gatherServiceDiscoveryPodLogs(info *Info) {
-	gatherPodLogs(lighthouseComponentsLabel, info)
+	gatherPodLogs(lighthouseAgentPodLabel, info)
+}
+
+func gatherLighthouseCoreDNSPodLogs(info *Info) {
+	gatherPodLogs(lighthouseCoreDNSPodLabel, info)
 }
 
 func gatherCoreDNSPodLogs(info *Info) {
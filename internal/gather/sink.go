@@ -0,0 +1,139 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+)
+
+// Sink receives the gathered artifacts as they're produced, in addition to the local directory, e.g. to stream them to a
+// remote store. Sinks are used concurrently when gathering from several clusters.
+type Sink interface {
+	// Put stores the artifact with the given name, a slash-separated path relative to the gather directory.
+	Put(ctx context.Context, name string, content io.Reader, size int64) error
+}
+
+// The artifacts are retried for about 15s before giving up.
+var sinkBackoff = wait.Backoff{
+	Steps:    5,
+	Duration: time.Second,
+	Factor:   2,
+	Jitter:   0.1,
+}
+
+// streamArtifact sends the given artifact, relative to the cluster's directory, to the sink. Failures are retried, then
+// reported without affecting the local collection.
+func (info *Info) streamArtifact(fileName string) {
+	if info.sink == nil {
+		return
+	}
+
+	name := path.Join(info.ClusterName, filepath.ToSlash(fileName))
+
+	err := retry.OnError(sinkBackoff, func(error) bool { return true }, func() error {
+		file, err := os.Open(filepath.Join(info.DirName, fileName))
+		if err != nil {
+			return errors.Wrapf(err, "error opening %q", fileName)
+		}
+
+		defer file.Close()
+
+		fileInfo, err := file.Stat()
+		if err != nil {
+			return errors.Wrapf(err, "error reading the size of %q", fileName)
+		}
+
+		return info.sink.Put(context.TODO(), name, file, fileInfo.Size())
+	})
+	if err != nil {
+		info.Summary.SinkFailures = append(info.Summary.SinkFailures, name)
+
+		if info.Status != nil {
+			info.Status.Warning("Error streaming %q to the sink, it's only available locally: %v", name, err)
+		}
+	}
+}
+
+// HTTPSink uploads each artifact with an HTTP PUT request to the base URL joined with the artifact's name. This is suitable
+// for WebDAV servers, upload services, and S3-compatible buckets whose policy allows uploads without request signing;
+// buckets requiring signed requests need their own Sink.
+type HTTPSink struct {
+	BaseURL *url.URL
+	// Headers are added to each request, e.g. for authorization.
+	Headers map[string]string
+	Client  *http.Client
+}
+
+// NewHTTPSink returns a sink uploading the artifacts below the given base URL.
+func NewHTTPSink(baseURL string, headers map[string]string) (*HTTPSink, error) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid sink URL %q", baseURL)
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("the sink URL %q must use http or https", baseURL)
+	}
+
+	return &HTTPSink{BaseURL: parsed, Headers: headers, Client: &http.Client{Timeout: 5 * time.Minute}}, nil
+}
+
+func (s *HTTPSink) Put(ctx context.Context, name string, content io.Reader, size int64) error {
+	target := *s.BaseURL
+	target.Path = strings.TrimSuffix(target.Path, "/") + "/" + name
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), content)
+	if err != nil {
+		return errors.Wrapf(err, "error creating the request for %q", name)
+	}
+
+	request.ContentLength = size
+
+	for key, value := range s.Headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := s.Client.Do(request)
+	if err != nil {
+		return errors.Wrapf(err, "error uploading %q", name)
+	}
+
+	defer response.Body.Close()
+
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("error uploading %q: the server responded %s", name, response.Status)
+	}
+
+	return nil
+}
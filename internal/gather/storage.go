@@ -0,0 +1,150 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+)
+
+// The storage artifacts are co-located in this sub-directory of the cluster's directory.
+const storageSubDir = "storage"
+
+const volumeEventsFileName = "volume-events.yaml"
+
+// The reasons of the events reported by the kubelet, the attach/detach controller and the provisioners when volumes can't
+// be provisioned, attached or mounted.
+var volumeEventReasons = sets.New("FailedMount", "FailedUnMount", "FailedAttachVolume", "FailedDetachVolume", "FailedMapVolume",
+	"FailedBinding", "ProvisioningFailed", "VolumeResizeFailed", "FileSystemResizeFailed")
+
+// gatherStorage gathers what's needed to debug the pods stuck in ContainerCreating because of their volumes: the
+// PersistentVolumeClaims in the Submariner namespaces, the PersistentVolumes and StorageClasses they use, and the
+// volume-related events. Nothing is gathered nor reported when there are no PersistentVolumeClaims, or none matching the
+// user's label selector.
+func gatherStorage(info *Info) {
+	claims := []corev1.PersistentVolumeClaim{}
+	namespaces := []string{}
+
+	for _, namespace := range info.namespaces {
+		list, err := info.ClientProducer.ForKubernetes().CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(),
+			metav1.ListOptions{LabelSelector: info.withSelector("")})
+		if err != nil {
+			info.Status.Failure("Error listing the PersistentVolumeClaims in namespace %q: %v", namespace, err)
+			continue
+		}
+
+		if len(list.Items) > 0 {
+			namespaces = append(namespaces, namespace)
+			claims = append(claims, list.Items...)
+		}
+	}
+
+	if len(claims) == 0 {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Join(info.DirName, storageSubDir), 0o700); err != nil {
+		info.Status.Failure("Error creating the storage sub-directory: %v", err)
+		return
+	}
+
+	info.subDir = storageSubDir
+	defer func() {
+		info.subDir = ""
+	}()
+
+	volumes := sets.New[string]()
+	storageClasses := sets.New[string]()
+
+	for i := range claims {
+		if claims[i].Spec.VolumeName != "" {
+			volumes.Insert(claims[i].Spec.VolumeName)
+		}
+
+		if claims[i].Spec.StorageClassName != nil && *claims[i].Spec.StorageClassName != "" {
+			storageClasses.Insert(*claims[i].Spec.StorageClassName)
+		}
+	}
+
+	for _, namespace := range namespaces {
+		ResourcesToYAMLFile(info, corev1.SchemeGroupVersion.WithResource("persistentvolumeclaims"), namespace, metav1.ListOptions{})
+	}
+
+	for _, name := range sets.List(volumes) {
+		ResourcesToYAMLFile(info, corev1.SchemeGroupVersion.WithResource("persistentvolumes"), metav1.NamespaceAll,
+			metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String()})
+	}
+
+	for _, name := range sets.List(storageClasses) {
+		ResourcesToYAMLFile(info, storagev1.SchemeGroupVersion.WithResource("storageclasses"), metav1.NamespaceAll,
+			metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String()})
+	}
+
+	gatherVolumeEvents(info, namespaces)
+}
+
+// gatherVolumeEvents writes the events concerning the PersistentVolumeClaims, and those reporting volume failures, in the
+// given namespaces to a single file. The events aren't labeled, the user's label selector doesn't constrain them.
+func gatherVolumeEvents(info *Info, namespaces []string) {
+	events := []corev1.Event{}
+
+	for _, namespace := range namespaces {
+		list, err := info.ClientProducer.ForKubernetes().CoreV1().Events(namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			info.Status.Failure("Error listing the events in namespace %q: %v", namespace, err)
+			continue
+		}
+
+		for i := range list.Items {
+			if list.Items[i].InvolvedObject.Kind == "PersistentVolumeClaim" || volumeEventReasons.Has(list.Items[i].Reason) {
+				events = append(events, list.Items[i])
+			}
+		}
+	}
+
+	if len(events) == 0 {
+		info.Status.Success("Found no volume-related events")
+		return
+	}
+
+	data, err := yaml.Marshal(events)
+	if err != nil {
+		info.Status.Failure("Error marshalling the volume events: %v", err)
+		return
+	}
+
+	fileName := filepath.Join(info.subDir, volumeEventsFileName)
+
+	err = os.WriteFile(filepath.Join(info.DirName, fileName), []byte(scrubSensitiveData(info, string(data))), 0o600)
+	if err != nil {
+		info.Status.Failure("Error writing the volume events to %q: %v", fileName, err)
+		return
+	}
+
+	info.addArtifact(fileName, info.redactsAny())
+	info.Status.Success("Found %d volume-related events, written to %q", len(events), fileName)
+}
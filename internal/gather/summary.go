@@ -40,10 +40,13 @@ import (
 //go:embed layout.gohtml
 var layout string
 
+const summaryFileName = "summary.html"
+
 func gatherClusterSummary(info *Info) {
 	dataGathered := getClusterInfo(info)
 	file := createFile(info.DirName)
 	writeToHTML(file, &dataGathered)
+	info.addArtifact(summaryFileName, false)
 }
 
 func getClusterInfo(info *Info) data {
@@ -52,7 +55,7 @@ func getClusterInfo(info *Info) data {
 
 	nConfig, err := getNodeConfig(info)
 	if err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(info.stdout(), err)
 	}
 
 	d := data{
@@ -70,17 +73,17 @@ func getClusterInfo(info *Info) data {
 func getClusterConfig(info *Info) clusterConfig {
 	gwNodes, err := getGWNodes(info)
 	if err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(info.stdout(), err)
 	}
 
 	mNodes, err := getMasterNodes(info)
 	if err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(info.stdout(), err)
 	}
 
 	allNodes, err := listNodes(info, metav1.ListOptions{})
 	if err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(info.stdout(), err)
 	}
 
 	config := clusterConfig{
@@ -115,7 +118,7 @@ func getVersions(info *Info) version {
 
 	k8sServerVersion, err := info.ClientProducer.ForKubernetes().Discovery().ServerVersion()
 	if err != nil {
-		fmt.Println("error in getting k8s server version", err)
+		fmt.Fprintln(info.stdout(), "error in getting k8s server version", err)
 		Versions.K8sServer = err.Error()
 	}
 
@@ -225,7 +228,7 @@ func listNodes(info *Info, listOptions metav1.ListOptions) (*v1.NodeList, error)
 }
 
 func createFile(dirname string) io.Writer {
-	fileName := filepath.Join(dirname, "summary.html")
+	fileName := filepath.Join(dirname, summaryFileName)
 
 	f, err := os.OpenFile(fileName, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o666)
 	if err != nil {
@@ -19,25 +19,81 @@ limitations under the License.
 package gather
 
 import (
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+
 	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/cli"
 	"github.com/submariner-io/subctl/pkg/cluster"
 	"github.com/submariner-io/submariner-operator/api/v1alpha1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 type Info struct {
 	cluster.Info
-	Status               reporter.Interface
-	ClusterName          string
-	DirName              string
-	IncludeSensitiveData bool
-	Summary              *Summary
+	Status       reporter.Interface
+	ClusterName  string
+	DirName      string
+	Summary      *Summary
+	module       string
+	dataType     string
+	writer       io.Writer
+	since        time.Time
+	until        time.Time
+	namespaces   []string
+	dumpDatapath bool
+	previousLogs bool
+	sink         Sink
+	// redaction holds the categories of sensitive data which are redacted.
+	redaction sets.Set[string]
+	// subDir, if set, is the sub-directory of DirName in which the artifacts are currently written.
+	subDir string
+	// selector, if set, is the user's label selector restricting the gathered resources.
+	selector string
+	// secretRefs maps the secrets referenced by the gathered resources to their referrers.
+	secretRefs map[types.NamespacedName]sets.Set[string]
+	// The Gateways' status is recorded during gatewayHistoryWindow, if positive, every gatewayHistoryInterval.
+	gatewayHistoryWindow   time.Duration
+	gatewayHistoryInterval time.Duration
+	// The API requests are limited to qps, with bursts of up to burst; throttled counts those rejected by the server.
+	qps       float32
+	burst     int
+	throttled *atomic.Int32
+}
+
+// stdout returns the writer for plain output, by default the standard output.
+func (info *Info) stdout() io.Writer {
+	if info.writer == nil {
+		return os.Stdout
+	}
+
+	return info.writer
+}
+
+// newReporter returns a reporter writing to the configured writer, by default the standard error.
+func (info *Info) newReporter() reporter.Interface {
+	if info.writer == nil {
+		return cli.NewReporter()
+	}
+
+	return cli.NewReporterWithWriter(info.writer)
 }
 
 type Summary struct {
 	Resources []ResourceInfo
 	PodLogs   []LogInfo
+	Artifacts []ArtifactInfo
+	Failures  []ModuleFailure
+	Completed []CompletedTask
+	Counts    []ModuleCounts
+	// NotRestartedPods are the pods, as namespace/name, whose previous logs weren't gathered because they never restarted.
+	NotRestartedPods []string
+	// SinkFailures are the artifacts, named as in the sink, which couldn't be streamed to it.
+	SinkFailures []string
 }
 
 type version struct {
@@ -0,0 +1,195 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gather
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Report describes the completeness of a gather bundle, cluster by cluster.
+type Report struct {
+	Clusters []ClusterReport `json:"clusters"`
+}
+
+// ClusterReport describes the completeness of the data gathered from a cluster. Without a manifest, only empty files
+// can be detected.
+type ClusterReport struct {
+	Directory   string `json:"directory"`
+	ClusterName string `json:"clusterName,omitempty"`
+	HasManifest bool   `json:"hasManifest"`
+	// ManifestError is set if the manifest exists but can't be read.
+	ManifestError string `json:"manifestError,omitempty"`
+	// MissingFiles are recorded in the manifest but absent.
+	MissingFiles []string `json:"missingFiles,omitempty"`
+	// TruncatedFiles are smaller than recorded in the manifest.
+	TruncatedFiles []string `json:"truncatedFiles,omitempty"`
+	EmptyFiles     []string `json:"emptyFiles,omitempty"`
+	// MissingModules and MissingTypes were selected, but no artifacts were gathered for them.
+	MissingModules []string `json:"missingModules,omitempty"`
+	MissingTypes   []string `json:"missingTypes,omitempty"`
+	// Failures are those recorded in the manifest while gathering.
+	Failures []ModuleFailure `json:"failures,omitempty"`
+}
+
+// Complete returns true if no problems were found in any cluster's data. Bundles without manifests are only checked for
+// empty files.
+func (r *Report) Complete() bool {
+	for i := range r.Clusters {
+		if !r.Clusters[i].Complete() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Complete returns true if no problems were found in the cluster's data.
+func (r *ClusterReport) Complete() bool {
+	return r.ManifestError == "" && len(r.MissingFiles) == 0 && len(r.TruncatedFiles) == 0 && len(r.EmptyFiles) == 0 &&
+		len(r.MissingModules) == 0 && len(r.MissingTypes) == 0 && len(r.Failures) == 0
+}
+
+// ValidateBundle checks the completeness of the gather bundle in the given directory, which is either the root of a
+// gather, containing a directory per cluster, or a single cluster's directory. The manifest of each cluster, when present,
+// is checked against the files. An error is only returned if the bundle can't be read; the problems found are
+// described in the report.
+func ValidateBundle(dir string) (Report, error) {
+	report := Report{}
+
+	if _, err := os.Stat(filepath.Join(dir, manifestFileName)); err == nil {
+		clusterReport, err := validateClusterDir(dir)
+		if err != nil {
+			return report, err
+		}
+
+		report.Clusters = append(report.Clusters, clusterReport)
+
+		return report, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return report, errors.Wrapf(err, "error reading the gather directory %q", dir)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		clusterReport, err := validateClusterDir(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return report, err
+		}
+
+		report.Clusters = append(report.Clusters, clusterReport)
+	}
+
+	if len(report.Clusters) == 0 {
+		return report, errors.Errorf("no cluster data found in %q", dir)
+	}
+
+	return report, nil
+}
+
+func validateClusterDir(dir string) (ClusterReport, error) {
+	report := ClusterReport{Directory: dir}
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		fileInfo, err := entry.Info()
+		if err != nil {
+			return err //nolint:wrapcheck // Wrapped below
+		}
+
+		if fileInfo.Size() == 0 {
+			relative, _ := filepath.Rel(dir, path)
+			report.EmptyFiles = append(report.EmptyFiles, relative)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return report, errors.Wrapf(err, "error reading the cluster directory %q", dir)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, manifestFileName))
+	if os.IsNotExist(err) {
+		return report, nil
+	}
+
+	report.HasManifest = true
+
+	manifest := Manifest{}
+
+	if err == nil {
+		err = json.Unmarshal(data, &manifest)
+	}
+
+	if err != nil {
+		report.ManifestError = err.Error()
+		return report, nil
+	}
+
+	report.ClusterName = manifest.ClusterName
+	report.Failures = manifest.Errors
+
+	checkManifestArtifacts(dir, &manifest, &report)
+
+	return report, nil
+}
+
+func checkManifestArtifacts(dir string, manifest *Manifest, report *ClusterReport) {
+	gatheredModules := sets.New[string]()
+	gatheredTypes := sets.New[string]()
+
+	for i := range manifest.Artifacts {
+		artifact := &manifest.Artifacts[i]
+
+		fileInfo, err := os.Stat(filepath.Join(dir, artifact.FileName))
+		if err != nil {
+			report.MissingFiles = append(report.MissingFiles, artifact.FileName)
+			continue
+		}
+
+		// Empty files are already reported
+		if fileInfo.Size() > 0 && fileInfo.Size() < artifact.Size {
+			report.TruncatedFiles = append(report.TruncatedFiles, artifact.FileName)
+		}
+
+		gatheredModules.Insert(artifact.Module)
+		gatheredTypes.Insert(artifact.Type)
+	}
+
+	report.MissingModules = sets.List(sets.New(manifest.Modules...).Difference(gatheredModules))
+	report.MissingTypes = sets.List(sets.New(manifest.Types...).Difference(gatheredTypes))
+}
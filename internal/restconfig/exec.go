@@ -0,0 +1,63 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restconfig
+
+import (
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// The exec plugins are given the name of the context they're invoked for in this environment variable.
+const execContextEnvVar = "SUBCTL_KUBECONTEXT"
+
+// isolateExecCredentials ensures that the exec credential plugin, if any, is invoked for the given context.
+// client-go caches the plugins' authenticators, and thus their credentials, by plugin configuration; unless the plugin
+// is provided the cluster information, contexts using the same plugin configuration for different clusters would share
+// the credentials obtained for the first one. Adding the context to the plugin's environment separates them.
+func isolateExecCredentials(config *rest.Config, contextName string) {
+	if config.ExecProvider == nil {
+		return
+	}
+
+	execProvider := *config.ExecProvider
+	execProvider.Env = append(append([]api.ExecEnvVar{}, config.ExecProvider.Env...),
+		api.ExecEnvVar{Name: execContextEnvVar, Value: contextName})
+	config.ExecProvider = &execProvider
+}
+
+// execCredentialError identifies errors caused by the exec credential plugin failing to provide valid credentials,
+// and names the context and the plugin command in them. Other errors are returned as-is.
+func execCredentialError(restConfig *RestConfig, err error) error {
+	if err == nil || restConfig.Config.ExecProvider == nil {
+		return err
+	}
+
+	if !apierrors.IsUnauthorized(err) && !strings.Contains(err.Error(), "getting credentials") {
+		return err
+	}
+
+	command := strings.TrimSpace(restConfig.Config.ExecProvider.Command + " " + strings.Join(restConfig.Config.ExecProvider.Args, " "))
+
+	return fmt.Errorf("unable to acquire credentials for context %q using the exec plugin %q: %w", restConfig.ContextName,
+		command, err)
+}
@@ -20,6 +20,7 @@ package restconfig
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/coreos/go-semver/semver"
 	"github.com/pkg/errors"
@@ -34,6 +35,7 @@ import (
 	"github.com/submariner-io/submariner-operator/api/v1alpha1"
 	subv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
 	k8serrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
@@ -43,6 +45,7 @@ import (
 type RestConfig struct {
 	Config      *rest.Config
 	ClusterName string
+	ContextName string
 }
 
 type loadingRulesAndOverrides struct {
@@ -61,6 +64,7 @@ type Producer struct {
 	inCluster                 bool
 	namespaceFlag             bool
 	contextsFlag              bool
+	explicitContext           bool
 	defaultNamespace          *string
 	prefixedDefaultNamespaces map[string]*string
 }
@@ -112,6 +116,15 @@ func (rcp *Producer) WithContextsFlag() *Producer {
 	return rcp
 }
 
+// WithExplicitContext configures the producer to check that the context selected with --context exists before using it,
+// and to warn when no context is selected and the current context is used implicitly.
+// This only applies to RunOnSelectedContext.
+func (rcp *Producer) WithExplicitContext() *Producer {
+	rcp.explicitContext = true
+
+	return rcp
+}
+
 // WithInClusterFlag configures the producer to handle an --in-cluster flag, requesting the use
 // of a Kubernetes-provided context.
 func (rcp *Producer) WithInClusterFlag() *Producer {
@@ -181,6 +194,28 @@ type AllContextFn func(clusterInfos []*cluster.Info, namespaces []string, status
 
 type PerContextFn func(clusterInfo *cluster.Info, namespace string, status reporter.Interface) error
 
+// ContextError is returned, aggregated, by RunOnAllContexts for each context on which processing failed.
+type ContextError struct {
+	Context string
+	Err     error
+}
+
+func (e *ContextError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ContextError) Unwrap() error {
+	return e.Err
+}
+
+func contextError(contextName string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &ContextError{Context: contextName, Err: err}
+}
+
 // RunOnSelectedContext runs the given function on the selected context.
 func (rcp *Producer) RunOnSelectedContext(function PerContextFn, status reporter.Interface) error {
 	if rcp.inCluster {
@@ -195,6 +230,12 @@ func (rcp *Producer) RunOnSelectedContext(function PerContextFn, status reporter
 	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
 		rcp.defaultClientConfig.loadingRules, rcp.defaultClientConfig.overrides)
 
+	if rcp.explicitContext {
+		if err := checkSelectedContext(clientConfig, rcp.defaultClientConfig.overrides.CurrentContext, status); err != nil {
+			return err
+		}
+	}
+
 	restConfig, err := getRestConfigFromConfig(clientConfig, rcp.defaultClientConfig.overrides)
 	if err != nil {
 		return status.Error(err, "error retrieving the default configuration")
@@ -202,7 +243,7 @@ func (rcp *Producer) RunOnSelectedContext(function PerContextFn, status reporter
 
 	clusterInfo, err := cluster.NewInfo(restConfig.ClusterName, restConfig.Config)
 	if err != nil {
-		return status.Error(err, "error building the cluster.Info for the default configuration")
+		return status.Error(execCredentialError(&restConfig, err), "error building the cluster.Info for the default configuration")
 	}
 
 	var submVersion string
@@ -230,6 +271,32 @@ func (rcp *Producer) RunOnSelectedContext(function PerContextFn, status reporter
 	return function(clusterInfo, namespace, status)
 }
 
+// checkSelectedContext checks that the given context exists; if none is given, the implicit use of the current context
+// is reported.
+func checkSelectedContext(clientConfig clientcmd.ClientConfig, contextName string, status reporter.Interface) error {
+	rawConfig, err := clientConfig.RawConfig()
+	if err != nil {
+		return status.Error(err, "error retrieving the raw kubeconfig setup")
+	}
+
+	if contextName == "" {
+		if rawConfig.CurrentContext == "" {
+			return status.Error(errors.New("no context was selected with --context and there is no current context"), "")
+		}
+
+		status.Warning("No context was selected with --context, using the current context %q", rawConfig.CurrentContext)
+
+		return nil
+	}
+
+	if _, ok := rawConfig.Contexts[contextName]; !ok {
+		return status.Error(fmt.Errorf("no Kubernetes context found named %s; the available contexts are %s", contextName,
+			strings.Join(sets.List(sets.KeySet(rawConfig.Contexts)), ", ")), "")
+	}
+
+	return nil
+}
+
 func runInCluster(function PerContextFn, status reporter.Interface) error {
 	restConfig, err := rest.InClusterConfig()
 	if err != nil {
@@ -274,7 +341,8 @@ func (rcp *Producer) RunOnSelectedPrefixedContext(prefix string, function PerCon
 
 		clusterInfo, err := cluster.NewInfo(restConfig.ClusterName, restConfig.Config)
 		if err != nil {
-			return true, status.Error(err, "error building the cluster.Info for the configuration for prefix %s", prefix)
+			return true, status.Error(execCredentialError(&restConfig, err),
+				"error building the cluster.Info for the configuration for prefix %s", prefix)
 		}
 
 		namespace, overridden, err := contextClientConfig.Namespace()
@@ -324,7 +392,8 @@ func (rcp *Producer) RunOnSelectedContexts(function AllContextFn, status reporte
 
 				clusterInfo, err := cluster.NewInfo(restConfig.ClusterName, restConfig.Config)
 				if err != nil {
-					return true, status.Error(err, "error building the cluster.Info for context %s", contextName)
+					return true, status.Error(execCredentialError(&restConfig, err), "error building the cluster.Info for context %s",
+						contextName)
 				}
 
 				clusterInfos = append(clusterInfos, clusterInfo)
@@ -350,7 +419,7 @@ func (rcp *Producer) RunOnSelectedContexts(function AllContextFn, status reporte
 
 // RunOnAllContexts runs the given function on all accessible non-prefixed contexts.
 // If the user has explicitly selected one or more contexts, only those contexts are used.
-// All appropriate contexts are processed, and any errors are aggregated.
+// All appropriate contexts are processed, and any errors are aggregated, as ContextErrors for per-context failures.
 // Returns an error if no contexts are found.
 func (rcp *Producer) RunOnAllContexts(function PerContextFn, status reporter.Interface) error {
 	if rcp.inCluster {
@@ -385,19 +454,22 @@ func (rcp *Producer) RunOnAllContexts(function PerContextFn, status reporter.Int
 
 			chosenContext, ok := rawConfig.Contexts[contextName]
 			if !ok {
-				contextErrors = append(contextErrors, status.Error(fmt.Errorf("no Kubernetes context found named %s", contextName), ""))
+				contextErrors = append(contextErrors, contextError(contextName,
+					status.Error(fmt.Errorf("no Kubernetes context found named %s", contextName), "")))
 
 				continue
 			}
 
-			contextErrors = append(contextErrors, rcp.overrideContextAndRun(chosenContext.Cluster, contextName, function, status))
+			contextErrors = append(contextErrors, contextError(contextName,
+				rcp.overrideContextAndRun(chosenContext.Cluster, contextName, function, status)))
 		}
 	} else {
 		// Loop over all accessible contexts
 		for contextName, context := range rawConfig.Contexts {
 			processedContexts++
 
-			contextErrors = append(contextErrors, rcp.overrideContextAndRun(context.Cluster, contextName, function, status))
+			contextErrors = append(contextErrors, contextError(contextName,
+				rcp.overrideContextAndRun(context.Cluster, contextName, function, status)))
 		}
 	}
 
@@ -476,7 +548,14 @@ func getRestConfigFromConfig(config clientcmd.ClientConfig, overrides *clientcmd
 		return RestConfig{}, fmt.Errorf("could not obtain the cluster name from kube config: %#v", raw)
 	}
 
-	return RestConfig{Config: clientConfig, ClusterName: *clusterName}, nil
+	contextName := overrides.CurrentContext
+	if contextName == "" {
+		contextName = raw.CurrentContext
+	}
+
+	isolateExecCredentials(clientConfig, contextName)
+
+	return RestConfig{Config: clientConfig, ClusterName: *clusterName, ContextName: contextName}, nil
 }
 
 func clusterNameFromContext(rawConfig *api.Config, overridesContext string) *string {
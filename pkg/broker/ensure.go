@@ -42,8 +42,10 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+// Ensure sets up the broker namespace and RBAC, and the CRDs if requested. The given namespace labels and annotations are
+// merged onto the namespace, along with the broker label.
 func Ensure(ctx context.Context, crdUpdater crd.Updater, kubeClient kubernetes.Interface, componentArr []string, createCRDs bool,
-	brokerNS string,
+	brokerNS string, nsLabels, nsAnnotations map[string]string,
 ) error {
 	if createCRDs {
 		for i := range componentArr {
@@ -68,10 +70,8 @@ func Ensure(ctx context.Context, crdUpdater crd.Updater, kubeClient kubernetes.I
 		}
 	}
 
-	brokerNamespaceLabels := map[string]string{}
-
 	// Create the namespace
-	_, err := namespace.Ensure(ctx, kubeClient, brokerNS, brokerNamespaceLabels)
+	_, err := namespace.EnsureWithAnnotations(ctx, kubeClient, brokerNS, NamespaceLabels(nsLabels), nsAnnotations)
 	if err != nil {
 		return err //nolint:wrapcheck // No need to wrap here
 	}
@@ -93,7 +93,7 @@ func Ensure(ctx context.Context, crdUpdater crd.Updater, kubeClient kubernetes.I
 
 func createBrokerClusterRoleAndDefaultSA(ctx context.Context, kubeClient kubernetes.Interface, inNamespace string) error {
 	// Create the a default SA for cluster access (backwards compatibility with documentation)
-	err := CreateNewBrokerSA(ctx, kubeClient, submarinerBrokerClusterDefaultSA, inNamespace)
+	err := CreateNewBrokerSA(ctx, kubeClient, SubmarinerBrokerClusterDefaultSA, inNamespace)
 	if err != nil && !apierrors.IsAlreadyExists(err) {
 		return errors.Wrap(err, "error creating the default broker service account")
 	}
@@ -105,7 +105,7 @@ func createBrokerClusterRoleAndDefaultSA(ctx context.Context, kubeClient kuberne
 	}
 
 	// Create the role binding
-	_, err = CreateNewBrokerRoleBinding(ctx, kubeClient, submarinerBrokerClusterDefaultSA, submarinerBrokerClusterRole, inNamespace)
+	_, err = CreateNewBrokerRoleBinding(ctx, kubeClient, SubmarinerBrokerClusterDefaultSA, submarinerBrokerClusterRole, inNamespace)
 	if err != nil && !apierrors.IsAlreadyExists(err) {
 		return errors.Wrap(err, "error creating the broker rolebinding")
 	}
@@ -213,3 +213,16 @@ func CreateNewBrokerSA(ctx context.Context, kubeClient kubernetes.Interface, sub
 
 	return err
 }
+
+// NamespaceLabels returns the given labels with the broker label, which takes precedence, added.
+func NamespaceLabels(custom map[string]string) map[string]string {
+	nsLabels := make(map[string]string, len(custom)+1)
+
+	for k, v := range custom {
+		nsLabels[k] = v
+	}
+
+	nsLabels[constants.SubmarinerBrokerLabel] = constants.TrueLabel
+
+	return nsLabels
+}
@@ -31,6 +31,10 @@ import (
 	"github.com/submariner-io/subctl/internal/component"
 	"github.com/submariner-io/subctl/internal/constants"
 	"github.com/submariner-io/subctl/internal/rbac"
+	"github.com/submariner-io/subctl/pkg/brokercr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -41,29 +45,43 @@ const InfoFileName = "broker-info.subm"
 func WriteInfoToFile(restConfig *rest.Config, brokerNamespace, ipsecFile string, components sets.Set[string],
 	customDomains []string, status reporter.Interface,
 ) error {
-	status.Start("Saving broker info to file %q", InfoFileName)
-	defer status.End()
-
 	kubeClient, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
 		return status.Error(err, "error creating Kubernetes client")
 	}
 
-	data, err := newDataFrom(kubeClient, brokerNamespace, ipsecFile)
+	return WriteInfo(context.TODO(), kubeClient, restConfig.Host+restConfig.APIPath, InfoFileName, brokerNamespace, ipsecFile, components,
+		customDomains, WriteSettings{}, status)
+}
+
+// WriteInfo writes the information needed to join the broker, reachable at the given URL, to the named file, backing up
+// any existing file. If ipsecFile is set, the IPsec PSK is imported from that broker information file.
+func WriteInfo(ctx context.Context, kubeClient kubernetes.Interface, brokerURL, fileName, brokerNamespace, ipsecFile string,
+	components sets.Set[string], customDomains []string, settings WriteSettings, status reporter.Interface,
+) error {
+	status.Start("Saving broker info to file %q", fileName)
+	defer status.End()
+
+	data, err := newDataFrom(ctx, kubeClient, brokerNamespace, ipsecFile)
 	if err != nil {
-		// TODO return reporter.Error(err, "error initializing broker info")
-		return err
+		return status.Error(err, "error initializing broker info")
 	}
 
-	data.BrokerURL = restConfig.Host + restConfig.APIPath
+	data.BrokerURL = brokerURL
 
-	newFilename, err := backupIfExists(InfoFileName)
+	if settings.TokenTTL > 0 {
+		if err := useBoundClientToken(ctx, kubeClient, brokerNamespace, settings.TokenTTL, data, status); err != nil {
+			return status.Error(err, "error creating a bound broker administrator token")
+		}
+	}
+
+	newFilename, err := backupIfExists(fileName)
 	if err != nil {
 		return status.Error(err, "error backing up the broker file")
 	}
 
 	if newFilename != "" {
-		status.Success("Backed up previous file %q to %q", InfoFileName, newFilename)
+		status.Success("Backed up previous file %q to %q", fileName, newFilename)
 	}
 
 	data.ServiceDiscovery = components.Has(component.ServiceDiscovery)
@@ -73,7 +91,11 @@ func WriteInfoToFile(restConfig *rest.Config, brokerNamespace, ipsecFile string,
 		data.CustomDomains = &customDomains
 	}
 
-	return status.Error(data.writeToFile(InfoFileName), "error saving broker info")
+	if settings.IPsec.IsSet() {
+		data.IPsec = &settings.IPsec
+	}
+
+	return status.Error(data.writeToFile(fileName), "error saving broker info")
 }
 
 func ReadInfoFromFile(filename string) (*Info, error) {
@@ -92,15 +114,23 @@ func ReadInfoFromFile(filename string) (*Info, error) {
 	return data, errors.Wrap(json.Unmarshal(bytes, data), "error unmarshalling data")
 }
 
-func newDataFrom(kubeClient kubernetes.Interface, brokerNamespace, ipsecFile string) (*Info, error) {
+func newDataFrom(ctx context.Context, kubeClient kubernetes.Interface, brokerNamespace, ipsecFile string) (*Info, error) {
 	var err error
 	data := &Info{}
 
-	data.ClientToken, err = rbac.GetClientTokenSecret(context.TODO(), kubeClient, brokerNamespace, constants.SubmarinerBrokerAdminSA)
+	data.ClientToken, err = rbac.GetClientTokenSecret(ctx, kubeClient, brokerNamespace, constants.SubmarinerBrokerAdminSA)
 	if err != nil {
 		return nil, errors.Wrap(err, "error getting broker client secret")
 	}
 
+	// A custom broker CA replaces the broker cluster's generated CA as the trust anchor given to the joining clusters
+	caSecret, err := kubeClient.CoreV1().Secrets(brokerNamespace).Get(ctx, brokercr.CASecretName, metav1.GetOptions{})
+	if err == nil {
+		data.ClientToken.Data["ca.crt"] = caSecret.Data[corev1.TLSCertKey]
+	} else if !apierrors.IsNotFound(err) {
+		return nil, errors.Wrap(err, "error getting the broker CA secret")
+	}
+
 	if ipsecFile != "" {
 		ipsecData, err := ReadInfoFromFile(ipsecFile)
 		if err != nil {
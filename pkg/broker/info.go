@@ -23,6 +23,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"os"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/submariner-io/admiral/pkg/resource"
@@ -42,6 +43,41 @@ type Info struct {
 	ServiceDiscovery bool           `omitempty,json:"serviceDiscovery"`
 	Components       []string       `json:",omitempty"`
 	CustomDomains    *[]string      `omitempty,json:"customDomains"`
+	IPsec            *IPsecSettings `json:"ipsec,omitempty"`
+	// ClientTokenExpiry is set when the client token is a bound token, which expires at that time.
+	ClientTokenExpiry *metav1.Time `json:"clientTokenExpiry,omitempty"`
+}
+
+// The ESP encapsulations which can be preferred for the IPsec tunnels between the joining clusters.
+const (
+	// EncapsulationAuto encapsulates ESP in UDP only when NAT is detected between the gateways.
+	EncapsulationAuto = "auto"
+	// EncapsulationUDP always encapsulates ESP in UDP, on the NAT-T port.
+	EncapsulationUDP = "udp"
+	// EncapsulationESP never encapsulates ESP, NAT traversal is disabled.
+	EncapsulationESP = "esp"
+)
+
+var Encapsulations = []string{EncapsulationAuto, EncapsulationUDP, EncapsulationESP}
+
+// IPsecSettings are the broker-wide IPsec defaults picked up by the joining clusters, unless overridden when joining;
+// the zero values leave the join defaults.
+type IPsecSettings struct {
+	NATTPort      int    `json:"nattPort,omitempty"`
+	Encapsulation string `json:"encapsulation,omitempty"`
+}
+
+func (s IPsecSettings) IsSet() bool {
+	return s.NATTPort != 0 || s.Encapsulation != ""
+}
+
+// WriteSettings are the optional settings of the written broker information.
+type WriteSettings struct {
+	// IPsec, if set, are recorded as the defaults of the joining clusters.
+	IPsec IPsecSettings
+	// TokenTTL, when positive, bounds the lifetime of the broker administrator token given to the joining clusters; it
+	// requires support for bound service account tokens, without which the token doesn't expire.
+	TokenTTL time.Duration
 }
 
 func (d *Info) writeToFile(filename string) error {
@@ -0,0 +1,93 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/constants"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/pointer"
+)
+
+// MinTokenTTL is the shortest lifetime accepted by the API server for bound service account tokens.
+const MinTokenTTL = 10 * time.Minute
+
+// useBoundClientToken replaces the broker administrator's long-lived token in the given information with a bound token
+// expiring after the given TTL, and records its expiry. If the cluster doesn't support bound tokens, the long-lived token is
+// kept, with a warning.
+func useBoundClientToken(ctx context.Context, kubeClient kubernetes.Interface, brokerNamespace string, ttl time.Duration, data *Info,
+	status reporter.Interface,
+) error {
+	supported, err := supportsBoundTokens(kubeClient)
+	if err != nil {
+		return err
+	}
+
+	if !supported {
+		status.Warning("The broker cluster doesn't support bound service account tokens, the %v TTL can't be enforced and the "+
+			"broker administrator token given to the joining clusters doesn't expire", ttl)
+
+		return nil
+	}
+
+	request, err := kubeClient.CoreV1().ServiceAccounts(brokerNamespace).CreateToken(ctx, constants.SubmarinerBrokerAdminSA,
+		&authenticationv1.TokenRequest{
+			Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: pointer.Int64(int64(ttl.Seconds()))},
+		}, metav1.CreateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "error requesting a token for ServiceAccount %q", constants.SubmarinerBrokerAdminSA)
+	}
+
+	data.ClientToken.Data[corev1.ServiceAccountTokenKey] = []byte(request.Status.Token)
+	expiry := request.Status.ExpirationTimestamp
+	data.ClientTokenExpiry = &expiry
+
+	// The API server can bound the requested lifetime
+	if requested := time.Now().Add(ttl); expiry.Time.Before(requested.Add(-time.Minute)) || expiry.Time.After(requested.Add(time.Minute)) {
+		status.Warning("The API server adjusted the lifetime of the broker administrator token, it expires at %s instead of "+
+			"after %v", expiry.Format(time.RFC3339), ttl)
+	} else {
+		status.Success("The broker administrator token given to the joining clusters expires at %s", expiry.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// supportsBoundTokens returns whether the cluster serves the TokenRequest API, creating bound service account tokens.
+func supportsBoundTokens(kubeClient kubernetes.Interface) (bool, error) {
+	resources, err := kubeClient.Discovery().ServerResourcesForGroupVersion(corev1.SchemeGroupVersion.String())
+	if err != nil {
+		return false, errors.Wrap(err, "error discovering the core API resources")
+	}
+
+	for i := range resources.APIResources {
+		if resources.APIResources[i].Name == "serviceaccounts/token" {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
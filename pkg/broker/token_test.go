@@ -0,0 +1,119 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker_test
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/pkg/broker"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	testing "k8s.io/client-go/testing"
+)
+
+const brokerNamespace = "submariner-k8s-broker"
+
+var _ = Describe("WriteInfo with a token TTL", func() {
+	var (
+		kubeClient *fake.Clientset
+		fileName   string
+	)
+
+	BeforeEach(func() {
+		tokenSecretName := constants.SubmarinerBrokerAdminSA + "-token-abcde"
+
+		kubeClient = fake.NewSimpleClientset(
+			&corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{Name: constants.SubmarinerBrokerAdminSA, Namespace: brokerNamespace},
+				Secrets:    []corev1.ObjectReference{{Name: tokenSecretName}},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: tokenSecretName, Namespace: brokerNamespace},
+				Data:       map[string][]byte{corev1.ServiceAccountTokenKey: []byte("long-lived")},
+			})
+
+		kubeClient.PrependReactor("create", "serviceaccounts", func(action testing.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "token" {
+				return false, nil, nil
+			}
+
+			request := action.(testing.CreateAction).GetObject().(*authenticationv1.TokenRequest)
+			request.Status = authenticationv1.TokenRequestStatus{
+				Token:               "bound",
+				ExpirationTimestamp: metav1.NewTime(time.Now().Add(time.Duration(*request.Spec.ExpirationSeconds) * time.Second)),
+			}
+
+			return true, request, nil
+		})
+
+		fileName = filepath.Join(GinkgoT().TempDir(), broker.InfoFileName)
+	})
+
+	writeAndRead := func() *broker.Info {
+		Expect(broker.WriteInfo(context.TODO(), kubeClient, "https://broker", fileName, brokerNamespace, "", sets.New[string](), nil,
+			broker.WriteSettings{TokenTTL: time.Hour}, reporter.Silent())).To(Succeed())
+
+		info, err := broker.ReadInfoFromFile(fileName)
+		Expect(err).To(Succeed())
+
+		return info
+	}
+
+	When("the cluster supports bound service account tokens", func() {
+		BeforeEach(func() {
+			kubeClient.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{
+				GroupVersion: corev1.SchemeGroupVersion.String(),
+				APIResources: []metav1.APIResource{{Name: "serviceaccounts"}, {Name: "serviceaccounts/token"}},
+			}}
+		})
+
+		It("should write a bound token and its expiry", func() {
+			info := writeAndRead()
+			Expect(string(info.ClientToken.Data[corev1.ServiceAccountTokenKey])).To(Equal("bound"))
+			Expect(info.ClientTokenExpiry).NotTo(BeNil())
+			Expect(info.ClientTokenExpiry.Time).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+		})
+	})
+
+	When("the cluster doesn't support bound service account tokens", func() {
+		BeforeEach(func() {
+			kubeClient.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{
+				GroupVersion: corev1.SchemeGroupVersion.String(),
+				APIResources: []metav1.APIResource{{Name: "serviceaccounts"}},
+			}}
+		})
+
+		It("should write the long-lived token without an expiry", func() {
+			info := writeAndRead()
+			Expect(string(info.ClientToken.Data[corev1.ServiceAccountTokenKey])).To(Equal("long-lived"))
+			Expect(info.ClientTokenExpiry).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,103 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package brokercr
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CASecretName is the secret, in the broker namespace, holding the custom CA whose certificate is distributed to the
+// joining clusters as the broker's trust anchor.
+const CASecretName = "submariner-broker-ca"
+
+// CA is a PEM-encoded CA certificate and its private key.
+type CA struct {
+	Cert []byte
+	Key  []byte
+}
+
+// CAFromSecret returns the CA stored in the given TLS secret.
+func CAFromSecret(secret *corev1.Secret) *CA {
+	return &CA{
+		Cert: secret.Data[corev1.TLSCertKey],
+		Key:  secret.Data[corev1.TLSPrivateKeyKey],
+	}
+}
+
+// Validate checks that the certificate and key match, and that the certificate is a CA certificate which is currently
+// valid.
+func (ca *CA) Validate() error {
+	cert, err := ca.certificate()
+	if err != nil {
+		return err
+	}
+
+	if !cert.IsCA {
+		return errors.Errorf("the certificate %q isn't a CA certificate", cert.Subject)
+	}
+
+	now := time.Now()
+	if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return errors.Errorf("the CA certificate %q is only valid from %s to %s", cert.Subject,
+			cert.NotBefore.Format(time.RFC3339), cert.NotAfter.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// Subject returns the subject of the CA certificate, or an empty string if it can't be parsed.
+func (ca *CA) Subject() string {
+	cert, err := ca.certificate()
+	if err != nil {
+		return ""
+	}
+
+	return cert.Subject.String()
+}
+
+func (ca *CA) certificate() (*x509.Certificate, error) {
+	pair, err := tls.X509KeyPair(ca.Cert, ca.Key)
+	if err != nil {
+		return nil, errors.Wrap(err, "the CA certificate and key don't form a valid pair")
+	}
+
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+
+	return cert, errors.Wrap(err, "error parsing the CA certificate")
+}
+
+func newCASecret(namespace string, ca *CA) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      CASecretName,
+			Namespace: namespace,
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       ca.Cert,
+			corev1.TLSPrivateKeyKey: ca.Key,
+		},
+	}
+}
@@ -0,0 +1,82 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package brokercr_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/pkg/brokercr"
+)
+
+var _ = Describe("CA", func() {
+	newCA := func(isCA bool, notAfter time.Time) *brokercr.CA {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(Succeed())
+
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: "test-ca"},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              notAfter,
+			IsCA:                  isCA,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign,
+		}
+
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		Expect(err).To(Succeed())
+
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		Expect(err).To(Succeed())
+
+		return &brokercr.CA{
+			Cert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			Key:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		}
+	}
+
+	It("should accept a valid CA", func() {
+		ca := newCA(true, time.Now().Add(time.Hour))
+		Expect(ca.Validate()).To(Succeed())
+		Expect(ca.Subject()).To(Equal("CN=test-ca"))
+	})
+
+	It("should reject a key which doesn't match the certificate", func() {
+		ca := newCA(true, time.Now().Add(time.Hour))
+		ca.Key = newCA(true, time.Now().Add(time.Hour)).Key
+		Expect(ca.Validate()).NotTo(Succeed())
+	})
+
+	It("should reject a certificate which isn't a CA", func() {
+		Expect(newCA(false, time.Now().Add(time.Hour)).Validate()).NotTo(Succeed())
+	})
+
+	It("should reject an expired CA", func() {
+		Expect(newCA(true, time.Now().Add(-time.Minute)).Validate()).NotTo(Succeed())
+	})
+})
@@ -25,7 +25,11 @@ import (
 	"github.com/submariner-io/admiral/pkg/resource"
 	"github.com/submariner-io/admiral/pkg/util"
 	submariner "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -33,16 +37,61 @@ const (
 	Name = "submariner-broker"
 )
 
-func Ensure(ctx context.Context, client controllerClient.Client, namespace string, brokerSpec submariner.BrokerSpec) error {
-	brokerCR := &submariner.Broker{
+// New returns the Broker resource which Ensure would create in the given namespace. The components are sorted, so that
+// the same inputs always produce the same resource.
+func New(namespace string, brokerSpec submariner.BrokerSpec) *submariner.Broker {
+	brokerSpec.Components = sets.List(sets.New(brokerSpec.Components...))
+
+	return &submariner.Broker{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      Name,
 			Namespace: namespace,
 		},
 		Spec: brokerSpec,
 	}
+}
+
+// Ensure creates the Broker resource, with the given labels and annotations, at the given API version of the submariner.io
+// group, by default that of the submariner API package. If a CA is given, it is stored first, to be used as the broker's
+// trust anchor instead of the broker cluster's generated CA; the CA is expected to have been validated.
+func Ensure(ctx context.Context, client controllerClient.Client, namespace, apiVersion string, brokerSpec submariner.BrokerSpec,
+	labels, annotations map[string]string, ca *CA,
+) error {
+	if ca != nil {
+		caSecret := newCASecret(namespace, ca)
+
+		_, err := util.CreateOrUpdate(ctx, resource.ForControllerClient(client, namespace, &corev1.Secret{}), caSecret,
+			util.Replace(caSecret))
+		if err != nil {
+			return errors.Wrap(err, "error storing the broker CA")
+		}
+	}
+
+	brokerCR := New(namespace, brokerSpec)
+	brokerCR.Labels = labels
+	brokerCR.Annotations = annotations
+
+	if apiVersion == "" {
+		apiVersion = submariner.GroupVersion.Version
+	}
+
+	// The controller client derives the API version of typed resources from the scheme, so the Broker resource is created
+	// unstructured to pin its version
+	gvk := submariner.GroupVersion.WithKind("Broker")
+	gvk.Version = apiVersion
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(brokerCR)
+	if err != nil {
+		return errors.Wrap(err, "error converting the Broker resource")
+	}
+
+	brokerObj := &unstructured.Unstructured{Object: obj}
+	brokerObj.SetGroupVersionKind(gvk)
+
+	objType := &unstructured.Unstructured{}
+	objType.SetGroupVersionKind(gvk)
 
-	_, err := util.CreateAnew(ctx, resource.ForControllerClient(client, namespace, &submariner.Broker{}), brokerCR,
+	_, err = util.CreateAnew(ctx, resource.ForControllerClient(client, namespace, objType), brokerObj,
 		metav1.CreateOptions{}, metav1.DeleteOptions{})
 
 	return errors.Wrap(err, "error creating Broker resource")
@@ -0,0 +1,85 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package brokercr_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/internal/component"
+	"github.com/submariner-io/subctl/pkg/brokercr"
+	submariner "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("New", func() {
+	const namespace = "test-namespace"
+
+	render := func(components ...string) string {
+		data, err := yaml.Marshal(brokercr.New(namespace, submariner.BrokerSpec{Components: components}))
+		Expect(err).To(Succeed())
+
+		return string(data)
+	}
+
+	It("should sort the components", func() {
+		broker := brokercr.New(namespace, submariner.BrokerSpec{
+			Components: []string{component.ServiceDiscovery, component.Connectivity},
+		})
+		Expect(broker.Spec.Components).To(Equal([]string{component.Connectivity, component.ServiceDiscovery}))
+	})
+
+	It("should produce identical resources regardless of the components' order", func() {
+		Expect(render(component.ServiceDiscovery, component.Connectivity)).To(Equal(
+			render(component.Connectivity, component.ServiceDiscovery)))
+	})
+
+	It("should not modify the given components", func() {
+		components := []string{component.ServiceDiscovery, component.Connectivity}
+		brokercr.New(namespace, submariner.BrokerSpec{Components: components})
+		Expect(components).To(Equal([]string{component.ServiceDiscovery, component.Connectivity}))
+	})
+})
+
+var _ = Describe("Ensure", func() {
+	const namespace = "test-namespace"
+
+	var fakeClient client.Client
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(submariner.AddToScheme(scheme)).To(Succeed())
+
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+	})
+
+	It("should create the Broker resource with the given API version", func() {
+		Expect(brokercr.Ensure(context.TODO(), fakeClient, namespace, "v1alpha1",
+			submariner.BrokerSpec{Components: []string{component.Connectivity}}, map[string]string{"team": "net"}, nil, nil)).To(Succeed())
+
+		broker := &submariner.Broker{}
+		Expect(fakeClient.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: brokercr.Name}, broker)).To(Succeed())
+		Expect(broker.Spec.Components).To(Equal([]string{component.Connectivity}))
+		Expect(broker.Labels).To(Equal(map[string]string{"team": "net"}))
+	})
+})
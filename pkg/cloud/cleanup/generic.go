@@ -20,17 +20,14 @@ package cleanup
 
 import (
 	"github.com/submariner-io/admiral/pkg/reporter"
-	"github.com/submariner-io/cloud-prepare/pkg/api"
 	"github.com/submariner-io/subctl/pkg/cloud/generic"
 	"github.com/submariner-io/subctl/pkg/cluster"
 )
 
 func GenericCluster(clusterInfo *cluster.Info, status reporter.Interface) error {
 	defer status.End()
-	err := generic.RunOnCluster(clusterInfo, status,
-		func(gwDeployer api.GatewayDeployer, status reporter.Interface) error {
-			return gwDeployer.Cleanup(status) //nolint:wrapcheck // No need to wrap here
-		})
+
+	err := generic.CleanupCluster(clusterInfo, status)
 
 	return status.Error(err, "Failed to cleanup generic K8s cluster")
 }
@@ -18,8 +18,29 @@ limitations under the License.
 
 package cloud
 
+import (
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/pkg/cluster"
+)
+
 type Ports struct {
 	Natt         uint16
 	NatDiscovery uint16
 	Vxlan        uint16
 }
+
+// Supported cloud providers, as selected with NewCloudPreparer in the prepare package.
+const (
+	AWS     = "aws"
+	Azure   = "azure"
+	GCP     = "gcp"
+	RHOS    = "rhos"
+	Generic = "generic"
+)
+
+// CloudPreparer prepares a cluster's infrastructure for Submariner, opening the given ports and deploying gateways as
+// configured, and cleans up what it prepared. The provider-specific configuration is provided when it's created.
+type CloudPreparer interface {
+	Prepare(clusterInfo *cluster.Info, ports *Ports, status reporter.Interface) error
+	Cleanup(clusterInfo *cluster.Info, status reporter.Interface) error
+}
@@ -0,0 +1,65 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// GatewayNodes returns the number and the names of the nodes labeled as Submariner gateways in the given cluster.
+func GatewayNodes(clusterInfo *cluster.Info) (int, []string, error) {
+	selector := labels.SelectorFromSet(map[string]string{constants.SubmarinerGatewayLabel: constants.TrueLabel})
+
+	nodes, err := clusterInfo.ClientProducer.ForKubernetes().CoreV1().Nodes().List(context.TODO(),
+		metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "error listing the gateway nodes")
+	}
+
+	names := make([]string, len(nodes.Items))
+	for i := range nodes.Items {
+		names[i] = nodes.Items[i].Name
+	}
+
+	return len(names), names, nil
+}
+
+// ReportGatewayNodes reports the gateway nodes which exist in the given cluster before it is modified.
+func ReportGatewayNodes(clusterInfo *cluster.Info, status reporter.Interface) error {
+	count, names, err := GatewayNodes(clusterInfo)
+	if err != nil {
+		return status.Error(err, "Unable to determine the existing gateway nodes")
+	}
+
+	if count == 0 {
+		status.Success("No existing gateway nodes found")
+	} else {
+		status.Success("Found %d existing gateway node(s): %s", count, strings.Join(names, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakeProducer only provides the Kubernetes client, which is all the generic cloud uses.
+type fakeProducer struct {
+	kubeClient *fakeclientset.Clientset
+}
+
+func (p *fakeProducer) ForGeneral() controllerClient.Client {
+	return nil
+}
+
+func (p *fakeProducer) ForKubernetes() kubernetes.Interface {
+	return p.kubeClient
+}
+
+func (p *fakeProducer) ForDynamic() dynamic.Interface {
+	return nil
+}
+
+func newClusterInfo(kubeClient *fakeclientset.Clientset) *cluster.Info {
+	return &cluster.Info{Name: "test-cluster", ClientProducer: &fakeProducer{kubeClient: kubeClient}}
+}
+
+func newNode(name string, gateway bool, annotations map[string]string) *corev1.Node {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}, Annotations: annotations},
+	}
+
+	if gateway {
+		node.Labels[constants.SubmarinerGatewayLabel] = constants.TrueLabel
+	}
+
+	return node
+}
+
+func newMasterNode(name string) *corev1.Node {
+	node := newNode(name, false, nil)
+	node.Spec.Taints = []corev1.Taint{{Key: "node-role.kubernetes.io/master", Effect: corev1.TaintEffectNoSchedule}}
+
+	return node
+}
+
+func gatewayNodeNames(kubeClient *fakeclientset.Clientset) []string {
+	nodes, err := kubeClient.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: constants.SubmarinerGatewayLabel})
+	Expect(err).To(Succeed())
+
+	names := []string{}
+	for i := range nodes.Items {
+		names = append(names, nodes.Items[i].Name)
+	}
+
+	return names
+}
+
+// fakeReporter records the messages reported, by kind.
+type fakeReporter struct {
+	successes []string
+	warnings  []string
+	failures  []string
+}
+
+func (r *fakeReporter) Start(_ string, _ ...interface{}) {
+}
+
+func (r *fakeReporter) End() {
+}
+
+func (r *fakeReporter) Success(message string, args ...interface{}) {
+	r.successes = append(r.successes, fmt.Sprintf(message, args...))
+}
+
+func (r *fakeReporter) Warning(message string, args ...interface{}) {
+	r.warnings = append(r.warnings, fmt.Sprintf(message, args...))
+}
+
+func (r *fakeReporter) Failure(message string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(message, args...))
+}
+
+func (r *fakeReporter) Error(err error, message string, args ...interface{}) error {
+	if err != nil {
+		r.Failure(message+": %v", append(args, err)...)
+	}
+
+	return err
+}
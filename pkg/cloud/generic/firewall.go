@@ -0,0 +1,100 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	submv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ReportFirewallGuidance reports, for each gateway node, its addresses and the ports which must be opened in the
+// firewall, since subctl can't configure the firewalls of generic clusters.
+func ReportFirewallGuidance(clusterInfo *cluster.Info, gwPorts, internalPorts []api.PortSpec, status reporter.Interface) error {
+	status.Start("Determining the firewall configuration required for the gateway nodes")
+	defer status.End()
+
+	selector := labels.SelectorFromSet(map[string]string{constants.SubmarinerGatewayLabel: constants.TrueLabel})
+
+	nodes, err := clusterInfo.ClientProducer.ForKubernetes().CoreV1().Nodes().List(context.TODO(),
+		metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return status.Error(err, "Unable to list the gateway nodes")
+	}
+
+	if len(nodes.Items) == 0 {
+		status.Warning("No gateway nodes found, there are no firewall instructions")
+		return nil
+	}
+
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+
+		status.Success("Gateway node %q (external IP %s, internal IP %s): allow %s from the other clusters' gateways",
+			node.Name, externalIP(node), nodeAddress(node, corev1.NodeInternalIP), formatPorts(gwPorts))
+	}
+
+	if len(internalPorts) > 0 {
+		status.Success("All nodes: allow %s between the nodes of this cluster", formatPorts(internalPorts))
+	}
+
+	return nil
+}
+
+// externalIP returns the public IP configured on the node, or its external address.
+func externalIP(node *corev1.Node) string {
+	if publicIP := node.Annotations[submv1.GatewayConfigPrefix+submv1.PublicIP]; publicIP != "" {
+		return publicIP
+	}
+
+	return nodeAddress(node, corev1.NodeExternalIP)
+}
+
+func nodeAddress(node *corev1.Node, addressType corev1.NodeAddressType) string {
+	for _, address := range node.Status.Addresses {
+		if address.Type == addressType {
+			return address.Address
+		}
+	}
+
+	return "unknown"
+}
+
+func formatPorts(ports []api.PortSpec) string {
+	formatted := make([]string, len(ports))
+
+	for i := range ports {
+		if ports[i].Port == 0 {
+			formatted[i] = fmt.Sprintf("protocol %s", ports[i].Protocol)
+		} else {
+			formatted[i] = fmt.Sprintf("%d/%s", ports[i].Port, ports[i].Protocol)
+		}
+	}
+
+	return strings.Join(formatted, ", ")
+}
@@ -0,0 +1,66 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/subctl/pkg/cloud/generic"
+	submv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("ReportFirewallGuidance", func() {
+	gwPorts := []api.PortSpec{{Port: 4500, Protocol: "udp"}, {Protocol: "esp"}}
+	internalPorts := []api.PortSpec{{Port: 4800, Protocol: "udp"}}
+
+	var status *fakeReporter
+
+	BeforeEach(func() {
+		status = &fakeReporter{}
+	})
+
+	When("there are gateway nodes", func() {
+		It("should report the ports to open on each of them", func() {
+			gwNode := newNode("gw-1", true, map[string]string{submv1.GatewayConfigPrefix + submv1.PublicIP: "1.2.3.4"})
+			gwNode.Status.Addresses = []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "10.0.0.1"}}
+
+			kubeClient := fakeclientset.NewSimpleClientset(gwNode, newNode("worker-1", false, nil))
+
+			Expect(generic.ReportFirewallGuidance(newClusterInfo(kubeClient), gwPorts, internalPorts, status)).To(Succeed())
+			Expect(status.successes).To(Equal([]string{
+				`Gateway node "gw-1" (external IP 1.2.3.4, internal IP 10.0.0.1): allow 4500/udp, protocol esp ` +
+					"from the other clusters' gateways",
+				"All nodes: allow 4800/udp between the nodes of this cluster",
+			}))
+		})
+	})
+
+	When("there are no gateway nodes", func() {
+		It("should warn that there are no instructions", func() {
+			kubeClient := fakeclientset.NewSimpleClientset(newNode("worker-1", false, nil))
+
+			Expect(generic.ReportFirewallGuidance(newClusterInfo(kubeClient), gwPorts, internalPorts, status)).To(Succeed())
+			Expect(status.warnings).To(ConsistOf("No gateway nodes found, there are no firewall instructions"))
+			Expect(status.successes).To(BeEmpty())
+		})
+	})
+})
@@ -19,19 +19,142 @@ limitations under the License.
 package generic
 
 import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
 	"github.com/submariner-io/admiral/pkg/reporter"
 	"github.com/submariner-io/cloud-prepare/pkg/api"
 	"github.com/submariner-io/cloud-prepare/pkg/generic"
 	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"github.com/submariner-io/subctl/pkg/cloud"
 	"github.com/submariner-io/subctl/pkg/cluster"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
 )
 
+type Config struct {
+	Gateways int
+	// PublicInterface, when set, is annotated on the gateway nodes as the interface to use for inter-cluster traffic.
+	PublicInterface string
+}
+
 func RunOnCluster(clusterInfo *cluster.Info, status reporter.Interface,
 	function func(api.GatewayDeployer, reporter.Interface) error,
 ) error {
+	if err := cloud.ReportGatewayNodes(clusterInfo, status); err != nil {
+		return err //nolint:wrapcheck // No need to wrap here
+	}
+
 	clientSet := clusterInfo.ClientProducer.ForKubernetes()
-	k8sClientSet := k8s.NewInterface(clientSet)
+	k8sClientSet := conflictRetryingInterface{Interface: k8s.NewInterface(clientSet)}
 	gwDeployer := generic.NewGatewayDeployer(k8sClientSet)
 
 	return function(gwDeployer, status)
 }
+
+// PrepareGateways deploys the configured number of gateways on the given cluster, and configures their public interface
+// if one was specified.
+func PrepareGateways(clusterInfo *cluster.Info, gwDeployer api.GatewayDeployer, config *Config, status reporter.Interface) error {
+	if config.PublicInterface != "" {
+		if err := validatePublicInterface(config.PublicInterface); err != nil {
+			return status.Error(err, "Invalid public interface")
+		}
+	}
+
+	if config.Gateways > 0 {
+		configured, err := gatewaysConfigured(clusterInfo, config.Gateways, status)
+		if err != nil {
+			return err
+		}
+
+		if configured {
+			status.Success("The %d gateway node(s) are already configured", config.Gateways)
+		} else if err := gwDeployer.Deploy(api.GatewayDeployInput{Gateways: config.Gateways}, status); err != nil {
+			return err //nolint:wrapcheck // No need to wrap here
+		}
+	}
+
+	if config.PublicInterface != "" {
+		return annotatePublicInterface(clusterInfo, config, status)
+	}
+
+	return nil
+}
+
+// gatewaysConfigured determines whether the cluster already has the given number of gateway nodes.
+func gatewaysConfigured(clusterInfo *cluster.Info, gateways int, status reporter.Interface) (bool, error) {
+	gwNodes, err := clusterInfo.ClientProducer.ForKubernetes().CoreV1().Nodes().List(context.TODO(),
+		metav1.ListOptions{LabelSelector: k8s.SubmarinerGatewayLabel + "=true"})
+	if err != nil {
+		return false, status.Error(errors.Wrap(err, "error listing the gateway nodes"), "Unable to determine the gateway nodes")
+	}
+
+	return len(gwNodes.Items) == gateways, nil
+}
+
+// conflictRetryingInterface retries the gateway node label updates which fail because the nodes were modified concurrently;
+// each attempt re-applies the update to the latest version of the node, so updates which are already applied are no-ops.
+type conflictRetryingInterface struct {
+	k8s.Interface
+}
+
+func (i conflictRetryingInterface) AddGWLabelOnNode(nodeName string) error {
+	return retryOnConflict(func() error {
+		return i.Interface.AddGWLabelOnNode(nodeName) //nolint:wrapcheck // No need to wrap here
+	})
+}
+
+func (i conflictRetryingInterface) RemoveGWLabelFromWorkerNodes() error {
+	return retryOnConflict(i.Interface.RemoveGWLabelFromWorkerNodes)
+}
+
+func (i conflictRetryingInterface) RemoveGWLabelFromWorkerNode(node *corev1.Node) error {
+	return retryOnConflict(func() error {
+		return i.Interface.RemoveGWLabelFromWorkerNode(node) //nolint:wrapcheck // No need to wrap here
+	})
+}
+
+func retryOnConflict(update func() error) error {
+	return retry.OnError(retry.DefaultBackoff, apierrors.IsConflict, update) //nolint:wrapcheck // No need to wrap here
+}
+
+// CleanupCluster removes the gateway configuration from the nodes of the given cluster, reporting how many nodes were affected.
+// Failures are returned without being reported, the caller reports them.
+func CleanupCluster(clusterInfo *cluster.Info, status reporter.Interface) error {
+	clientSet := clusterInfo.ClientProducer.ForKubernetes()
+
+	// The generic deployer removes the label from all labeled nodes, whatever its value
+	gwNodes, err := clientSet.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: k8s.SubmarinerGatewayLabel})
+	if err != nil {
+		return errors.Wrap(err, "error listing the gateway nodes")
+	}
+
+	// The annotation may remain on nodes which are no longer gateways
+	cleaned, err := removePublicInterface(clusterInfo)
+	if len(cleaned) > 0 {
+		status.Success("Removed the public interface annotation from node(s) %s", strings.Join(cleaned, ", "))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if len(gwNodes.Items) == 0 {
+		status.Success("No gateway nodes found, there is nothing to clean up")
+		return nil
+	}
+
+	err = RunOnCluster(clusterInfo, status, func(gwDeployer api.GatewayDeployer, status reporter.Interface) error {
+		return gwDeployer.Cleanup(status) //nolint:wrapcheck // No need to wrap here
+	})
+	if err != nil {
+		return errors.Wrap(err, "error removing the gateway configuration")
+	}
+
+	status.Success("Removed the gateway configuration from %d node(s)", len(gwNodes.Items))
+
+	return nil
+}
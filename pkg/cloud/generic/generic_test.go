@@ -0,0 +1,157 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/subctl/pkg/cloud/generic"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/testing"
+)
+
+var _ = Describe("PrepareGateways", func() {
+	var (
+		kubeClient  *fakeclientset.Clientset
+		clusterInfo *cluster.Info
+		config      *generic.Config
+		status      *fakeReporter
+	)
+
+	BeforeEach(func() {
+		kubeClient = fakeclientset.NewSimpleClientset(newMasterNode("master"), newNode("worker-1", false, nil))
+		clusterInfo = newClusterInfo(kubeClient)
+		config = &generic.Config{Gateways: 1}
+		status = &fakeReporter{}
+	})
+
+	prepareGateways := func() error {
+		return generic.RunOnCluster(clusterInfo, status, func(gwDeployer api.GatewayDeployer, status reporter.Interface) error {
+			return generic.PrepareGateways(clusterInfo, gwDeployer, config, status)
+		})
+	}
+
+	It("should label the worker nodes as gateways", func() {
+		Expect(prepareGateways()).To(Succeed())
+		Expect(gatewayNodeNames(kubeClient)).To(Equal([]string{"worker-1"}))
+		Expect(status.successes).To(ContainElement("No existing gateway nodes found"))
+	})
+
+	When("the gateway nodes are already configured", func() {
+		BeforeEach(func() {
+			Expect(kubeClient.Tracker().Update(schema.GroupVersionResource{Version: "v1", Resource: "nodes"},
+				newNode("worker-1", true, nil), "")).To(Succeed())
+			kubeClient.ClearActions()
+		})
+
+		It("should leave them as they are", func() {
+			Expect(prepareGateways()).To(Succeed())
+			Expect(status.successes).To(ContainElement("The 1 gateway node(s) are already configured"))
+
+			for _, action := range kubeClient.Actions() {
+				Expect(action.GetVerb()).NotTo(Equal("update"))
+			}
+		})
+	})
+
+	When("the nodes are modified concurrently", func() {
+		BeforeEach(func() {
+			conflicts := 0
+
+			// More conflicts than the label update itself retries
+			kubeClient.PrependReactor("update", "nodes", func(action testing.Action) (bool, runtime.Object, error) {
+				if conflicts < 6 {
+					conflicts++
+					return true, nil, apierrors.NewConflict(schema.GroupResource{Resource: "nodes"}, "worker-1", nil)
+				}
+
+				return false, nil, nil
+			})
+		})
+
+		It("should retry the labelling", func() {
+			Expect(prepareGateways()).To(Succeed())
+			Expect(gatewayNodeNames(kubeClient)).To(Equal([]string{"worker-1"}))
+		})
+	})
+
+	When("a public interface is requested", func() {
+		BeforeEach(func() {
+			config.PublicInterface = "eth1"
+		})
+
+		It("should reject it, since the gateway doesn't support it", func() {
+			Expect(prepareGateways()).NotTo(Succeed())
+			Expect(status.failures).To(ConsistOf(ContainSubstring("doesn't support the %q annotation", generic.PublicInterfaceAnnotation)))
+			Expect(gatewayNodeNames(kubeClient)).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("CleanupCluster", func() {
+	var (
+		kubeClient *fakeclientset.Clientset
+		status     *fakeReporter
+	)
+
+	BeforeEach(func() {
+		status = &fakeReporter{}
+	})
+
+	When("there are gateway nodes", func() {
+		BeforeEach(func() {
+			kubeClient = fakeclientset.NewSimpleClientset(
+				newNode("worker-1", true, map[string]string{generic.PublicInterfaceAnnotation: "eth1"}),
+				newNode("worker-2", false, nil))
+		})
+
+		It("should remove their gateway configuration", func() {
+			Expect(generic.CleanupCluster(newClusterInfo(kubeClient), status)).To(Succeed())
+			Expect(gatewayNodeNames(kubeClient)).To(BeEmpty())
+
+			node, err := kubeClient.CoreV1().Nodes().Get(context.TODO(), "worker-1", metav1.GetOptions{})
+			Expect(err).To(Succeed())
+			Expect(node.Annotations).NotTo(HaveKey(generic.PublicInterfaceAnnotation))
+
+			Expect(status.successes).To(ContainElements(
+				"Removed the public interface annotation from node(s) worker-1",
+				"Removed the gateway configuration from 1 node(s)"))
+		})
+	})
+
+	When("there are no gateway nodes", func() {
+		BeforeEach(func() {
+			kubeClient = fakeclientset.NewSimpleClientset(newNode("worker-1", false, nil))
+		})
+
+		It("should report that there is nothing to clean up", func() {
+			Expect(generic.CleanupCluster(newClusterInfo(kubeClient), status)).To(Succeed())
+			Expect(status.successes).To(Equal([]string{"No gateway nodes found, there is nothing to clean up"}))
+		})
+	})
+})
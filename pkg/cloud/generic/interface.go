@@ -0,0 +1,164 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	submv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	interfaceConfig = "interface"
+	// Linux limits interface names to 15 characters.
+	maxInterfaceNameLength = 15
+)
+
+// PublicInterfaceAnnotation is the gateway node annotation naming the interface used for inter-cluster traffic.
+var PublicInterfaceAnnotation = submv1.GatewayConfigPrefix + interfaceConfig
+
+// validatePublicInterface checks that the interface name is valid, and that Submariner's gateway reads the annotation;
+// otherwise, the annotation would be silently ignored.
+func validatePublicInterface(name string) error {
+	if !sets.New(submv1.ValidGatewayNodeConfig...).Has(interfaceConfig) {
+		return fmt.Errorf("this Submariner release's gateway doesn't support the %q annotation, the public interface "+
+			"can't be configured", PublicInterfaceAnnotation)
+	}
+
+	return validateInterfaceName(name)
+}
+
+func validateInterfaceName(name string) error {
+	if name == "" || name == "." || name == ".." || len(name) > maxInterfaceNameLength ||
+		strings.ContainsAny(name, "/: \t\n") {
+		return fmt.Errorf("%q isn't a valid interface name", name)
+	}
+
+	return nil
+}
+
+// annotatePublicInterface annotates all the gateway nodes with the configured public interface.
+func annotatePublicInterface(clusterInfo *cluster.Info, config *Config, status reporter.Interface) error {
+	status.Start("Configuring the public interface %q on the gateway nodes", config.PublicInterface)
+	defer status.End()
+
+	if errs := validation.IsQualifiedName(PublicInterfaceAnnotation); len(errs) > 0 {
+		return status.Error(fmt.Errorf("%s", strings.Join(errs, ", ")), "Invalid annotation key %q", PublicInterfaceAnnotation)
+	}
+
+	kubeClient := clusterInfo.ClientProducer.ForKubernetes()
+	selector := labels.SelectorFromSet(map[string]string{constants.SubmarinerGatewayLabel: constants.TrueLabel})
+
+	nodes, err := kubeClient.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return status.Error(err, "Unable to list the gateway nodes")
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{PublicInterfaceAnnotation: config.PublicInterface},
+		},
+	})
+	if err != nil {
+		return status.Error(err, "Unable to build the annotation patch")
+	}
+
+	annotated := []string{}
+	alreadyAnnotated := 0
+
+	for i := range nodes.Items {
+		if nodes.Items[i].Annotations[PublicInterfaceAnnotation] == config.PublicInterface {
+			alreadyAnnotated++
+			continue
+		}
+
+		_, err := kubeClient.CoreV1().Nodes().Patch(context.TODO(), nodes.Items[i].Name, types.MergePatchType, patch,
+			metav1.PatchOptions{})
+		if err != nil {
+			return status.Error(errors.Wrapf(err, "error annotating node %q", nodes.Items[i].Name),
+				"Unable to configure the public interface")
+		}
+
+		annotated = append(annotated, nodes.Items[i].Name)
+	}
+
+	if len(nodes.Items) == 0 {
+		status.Warning("No gateway nodes found, the public interface wasn't configured")
+		return nil
+	}
+
+	if len(annotated) == 0 {
+		status.Success("The public interface is already configured on the %d gateway node(s)", alreadyAnnotated)
+		return nil
+	}
+
+	status.Success("Annotated gateway node(s) %s with %s=%s", strings.Join(annotated, ", "), PublicInterfaceAnnotation,
+		config.PublicInterface)
+
+	return nil
+}
+
+// removePublicInterface removes the public interface annotation from the nodes, returning the names of those which had it.
+func removePublicInterface(clusterInfo *cluster.Info) ([]string, error) {
+	kubeClient := clusterInfo.ClientProducer.ForKubernetes()
+
+	nodes, err := kubeClient.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the nodes")
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{PublicInterfaceAnnotation: nil},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error building the annotation patch")
+	}
+
+	cleaned := []string{}
+
+	for i := range nodes.Items {
+		if _, found := nodes.Items[i].Annotations[PublicInterfaceAnnotation]; !found {
+			continue
+		}
+
+		_, err := kubeClient.CoreV1().Nodes().Patch(context.TODO(), nodes.Items[i].Name, types.MergePatchType, patch,
+			metav1.PatchOptions{})
+		if err != nil {
+			return cleaned, errors.Wrapf(err, "error removing the public interface annotation from node %q", nodes.Items[i].Name)
+		}
+
+		cleaned = append(cleaned, nodes.Items[i].Name)
+	}
+
+	return cleaned, nil
+}
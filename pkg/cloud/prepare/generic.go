@@ -21,27 +21,28 @@ package prepare
 import (
 	"github.com/submariner-io/admiral/pkg/reporter"
 	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/subctl/pkg/cloud"
 	"github.com/submariner-io/subctl/pkg/cloud/generic"
 	"github.com/submariner-io/subctl/pkg/cluster"
 )
 
-func GenericCluster(clusterInfo *cluster.Info, gateways int, status reporter.Interface) error {
+func GenericCluster(clusterInfo *cluster.Info, ports *cloud.Ports, config *generic.Config, status reporter.Interface) error {
 	defer status.End()
 
+	// The ports are only reported, failing to discover the network only affects the internal ports
+	gwPorts, internalPorts, err := getPortConfig(clusterInfo.ClientProducer, ports, false)
+	if err != nil {
+		status.Warning("Unable to determine the internal ports to open: %v", err)
+	}
+
 	//nolint:wrapcheck // No need to wrap errors here.
-	err := generic.RunOnCluster(clusterInfo, status,
+	err = generic.RunOnCluster(clusterInfo, status,
 		func(gwDeployer api.GatewayDeployer, status reporter.Interface) error {
-			if gateways > 0 {
-				gwInput := api.GatewayDeployInput{
-					Gateways: gateways,
-				}
-				err := gwDeployer.Deploy(gwInput, status)
-				if err != nil {
-					return err
-				}
+			if err := generic.PrepareGateways(clusterInfo, gwDeployer, config, status); err != nil {
+				return err
 			}
 
-			return nil
+			return generic.ReportFirewallGuidance(clusterInfo, gwPorts, internalPorts, status)
 		})
 
 	return status.Error(err, "Failed to prepare generic K8s cluster")
@@ -0,0 +1,145 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prepare
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/pkg/cloud"
+	"github.com/submariner-io/subctl/pkg/cloud/aws"
+	"github.com/submariner-io/subctl/pkg/cloud/azure"
+	"github.com/submariner-io/subctl/pkg/cloud/cleanup"
+	"github.com/submariner-io/subctl/pkg/cloud/gcp"
+	"github.com/submariner-io/subctl/pkg/cloud/generic"
+	"github.com/submariner-io/subctl/pkg/cloud/rhos"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// PreparerConfig holds the configuration of each provider; only the selected provider's configuration is used, and
+// it must be set.
+type PreparerConfig struct {
+	AWS     *aws.Config
+	Azure   *azure.Config
+	GCP     *gcp.Config
+	RHOS    *rhos.Config
+	Generic *generic.Config
+	// UseLoadBalancer deploys load-balanced gateways, on the providers which support them.
+	UseLoadBalancer bool
+}
+
+var preparers = map[string]func(*PreparerConfig) (cloud.CloudPreparer, bool){
+	cloud.AWS: func(config *PreparerConfig) (cloud.CloudPreparer, bool) {
+		return &awsPreparer{config: config.AWS, useLoadBalancer: config.UseLoadBalancer}, config.AWS != nil
+	},
+	cloud.Azure: func(config *PreparerConfig) (cloud.CloudPreparer, bool) {
+		return &azurePreparer{config: config.Azure, useLoadBalancer: config.UseLoadBalancer}, config.Azure != nil
+	},
+	cloud.GCP: func(config *PreparerConfig) (cloud.CloudPreparer, bool) {
+		return &gcpPreparer{config: config.GCP, useLoadBalancer: config.UseLoadBalancer}, config.GCP != nil
+	},
+	cloud.RHOS: func(config *PreparerConfig) (cloud.CloudPreparer, bool) {
+		return &rhosPreparer{config: config.RHOS, useLoadBalancer: config.UseLoadBalancer}, config.RHOS != nil
+	},
+	cloud.Generic: func(config *PreparerConfig) (cloud.CloudPreparer, bool) {
+		return &genericPreparer{config: config.Generic}, config.Generic != nil
+	},
+}
+
+// NewCloudPreparer returns the CloudPreparer for the given provider, using its configuration.
+func NewCloudPreparer(provider string, config *PreparerConfig) (cloud.CloudPreparer, error) {
+	newPreparer, found := preparers[provider]
+	if !found {
+		return nil, fmt.Errorf("unknown cloud provider %q, the supported providers are %s", provider,
+			strings.Join(sets.List(sets.KeySet(preparers)), ", "))
+	}
+
+	preparer, configured := newPreparer(config)
+	if !configured {
+		return nil, fmt.Errorf("no configuration was provided for cloud provider %q", provider)
+	}
+
+	return preparer, nil
+}
+
+type awsPreparer struct {
+	config          *aws.Config
+	useLoadBalancer bool
+}
+
+func (p *awsPreparer) Prepare(clusterInfo *cluster.Info, ports *cloud.Ports, status reporter.Interface) error {
+	return AWS(clusterInfo, ports, p.config, p.useLoadBalancer, status)
+}
+
+func (p *awsPreparer) Cleanup(clusterInfo *cluster.Info, status reporter.Interface) error {
+	return cleanup.AWS(clusterInfo, p.config, status) //nolint:wrapcheck // No need to wrap here
+}
+
+type azurePreparer struct {
+	config          *azure.Config
+	useLoadBalancer bool
+}
+
+func (p *azurePreparer) Prepare(clusterInfo *cluster.Info, ports *cloud.Ports, status reporter.Interface) error {
+	return Azure(clusterInfo, ports, p.config, p.useLoadBalancer, status)
+}
+
+func (p *azurePreparer) Cleanup(clusterInfo *cluster.Info, status reporter.Interface) error {
+	return cleanup.Azure(clusterInfo, p.config, status) //nolint:wrapcheck // No need to wrap here
+}
+
+type gcpPreparer struct {
+	config          *gcp.Config
+	useLoadBalancer bool
+}
+
+func (p *gcpPreparer) Prepare(clusterInfo *cluster.Info, ports *cloud.Ports, status reporter.Interface) error {
+	return GCP(clusterInfo, ports, p.config, p.useLoadBalancer, status)
+}
+
+func (p *gcpPreparer) Cleanup(clusterInfo *cluster.Info, status reporter.Interface) error {
+	return cleanup.GCP(clusterInfo, p.config, status) //nolint:wrapcheck // No need to wrap here
+}
+
+type rhosPreparer struct {
+	config          *rhos.Config
+	useLoadBalancer bool
+}
+
+func (p *rhosPreparer) Prepare(clusterInfo *cluster.Info, ports *cloud.Ports, status reporter.Interface) error {
+	return RHOS(clusterInfo, ports, p.config, p.useLoadBalancer, status)
+}
+
+func (p *rhosPreparer) Cleanup(clusterInfo *cluster.Info, status reporter.Interface) error {
+	return cleanup.RHOS(clusterInfo, p.config, status) //nolint:wrapcheck // No need to wrap here
+}
+
+type genericPreparer struct {
+	config *generic.Config
+}
+
+func (p *genericPreparer) Prepare(clusterInfo *cluster.Info, ports *cloud.Ports, status reporter.Interface) error {
+	return GenericCluster(clusterInfo, ports, p.config, status)
+}
+
+func (p *genericPreparer) Cleanup(clusterInfo *cluster.Info, status reporter.Interface) error {
+	return cleanup.GenericCluster(clusterInfo, status) //nolint:wrapcheck // No need to wrap here
+}
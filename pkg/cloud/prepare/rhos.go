@@ -30,6 +30,10 @@ import (
 func RHOS(clusterInfo *cluster.Info, ports *cloud.Ports, config *rhos.Config, useLoadBalancer bool, status reporter.Interface) error {
 	defer status.End()
 
+	if err := rhos.ValidateGatewayCount(config); err != nil {
+		return status.Error(err, "Invalid gateway count")
+	}
+
 	gwPorts, internalPorts, err := getPortConfig(clusterInfo.ClientProducer, ports, false)
 	if err != nil {
 		return status.Error(err, "Failed to prepare the cloud")
@@ -38,7 +42,20 @@ func RHOS(clusterInfo *cluster.Info, ports *cloud.Ports, config *rhos.Config, us
 	//nolint:wrapcheck // No need to wrap errors here.
 	err = rhos.RunOn(clusterInfo, config, status,
 		func(cloud api.Cloud, gwDeployer api.GatewayDeployer, status reporter.Interface) error {
-			if config.Gateways > 0 {
+			if config.PortsOnly {
+				// The gateway nodes are managed externally, only the security group rules are configured
+				err := gwDeployer.Deploy(api.GatewayDeployInput{PublicPorts: gwPorts, UseLoadBalancer: useLoadBalancer}, status)
+				if err != nil {
+					return errors.Wrap(err, "Configuring the gateway ports failed")
+				}
+			} else {
+				// Without dedicated gateways, existing workers are labeled here so the deployer only opens their ports
+				if !config.DedicatedGateway {
+					if err := rhos.LabelWorkerGateways(clusterInfo, config, status); err != nil {
+						return err //nolint:wrapcheck // No need to wrap here
+					}
+				}
+
 				gwInput := api.GatewayDeployInput{
 					PublicPorts:     gwPorts,
 					Gateways:        config.Gateways,
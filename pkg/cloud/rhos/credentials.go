@@ -0,0 +1,176 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gophercloud/utils/openstack/clientconfig"
+	"github.com/pkg/errors"
+)
+
+// The environment variables from which the secrets are read when they aren't given explicitly, as the OpenStack clients do.
+const (
+	passwordEnv                    = "OS_PASSWORD"
+	applicationCredentialSecretEnv = "OS_APPLICATION_CREDENTIAL_SECRET"
+)
+
+// explicitCredentialsEnvPrefix replaces the "OS_" prefix of the environment variables read by clientconfig when using explicit
+// credentials, so that they aren't overridden by the environment; in particular, OS_CLOUD would select a clouds.yaml entry
+// replacing them.
+const explicitCredentialsEnvPrefix = "SUBCTL_IGNORED_OS_"
+
+// Credentials holds RHOS credentials supplied explicitly rather than read from a clouds.yaml. The password and application
+// credential secret can be read from files instead, or, if neither is given, from the OS_PASSWORD and
+// OS_APPLICATION_CREDENTIAL_SECRET environment variables.
+type Credentials struct {
+	AuthURL                         string
+	Username                        string
+	Password                        string
+	PasswordFile                    string
+	ApplicationCredentialID         string
+	ApplicationCredentialSecret     string
+	ApplicationCredentialSecretFile string
+	ProjectName                     string
+	UserDomainName                  string
+	ProjectDomainName               string
+}
+
+// IsSet returns true if any explicit credential has been specified.
+func (c *Credentials) IsSet() bool {
+	return *c != Credentials{}
+}
+
+// withResolvedSecrets returns the credentials with the password and application credential secret read from their files,
+// or from the environment if the corresponding user name or application credential ID is given without them.
+func (c Credentials) withResolvedSecrets() (Credentials, error) {
+	var err error
+
+	c.Password, err = resolveSecret(c.Password, c.PasswordFile, "password", c.Username != "", passwordEnv)
+	if err != nil {
+		return c, err
+	}
+
+	c.ApplicationCredentialSecret, err = resolveSecret(c.ApplicationCredentialSecret, c.ApplicationCredentialSecretFile,
+		"application credential secret", c.ApplicationCredentialID != "", applicationCredentialSecretEnv)
+
+	return c, err
+}
+
+func resolveSecret(value, file, description string, needed bool, envName string) (string, error) {
+	switch {
+	case value != "" && file != "":
+		return "", fmt.Errorf("the %s can't be given both directly and from a file", description)
+	case file != "":
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return "", errors.Wrapf(err, "error reading the %s from %q", description, file)
+		}
+
+		return strings.TrimSpace(string(content)), nil
+	case value == "" && needed:
+		return os.Getenv(envName), nil
+	}
+
+	return value, nil
+}
+
+func (c *Credentials) validate() error {
+	if c.AuthURL == "" {
+		return errors.New("an auth URL is required when specifying explicit credentials")
+	}
+
+	usingPassword := c.Username != "" || c.Password != ""
+	usingAppCredential := c.ApplicationCredentialID != "" || c.ApplicationCredentialSecret != ""
+
+	switch {
+	case usingPassword && usingAppCredential:
+		return errors.New("a username and password can't be combined with an application credential")
+	case usingPassword:
+		if c.Username == "" || c.Password == "" {
+			return errors.New("both a username and a password are required")
+		}
+
+		if c.ProjectName == "" {
+			return errors.New("a project name is required when authenticating with a username and password")
+		}
+	case usingAppCredential:
+		if c.ApplicationCredentialID == "" || c.ApplicationCredentialSecret == "" {
+			return errors.New("both an application credential ID and secret are required")
+		}
+	default:
+		return errors.New("either a username and password or an application credential are required")
+	}
+
+	return nil
+}
+
+func clientOpts(config *Config) (*clientconfig.ClientOpts, error) {
+	if !config.Credentials.IsSet() {
+		// Using RHOS default "openstack", if not specified
+		if config.CloudEntry == "" {
+			config.CloudEntry = "openstack"
+		}
+
+		return &clientconfig.ClientOpts{
+			Cloud: config.CloudEntry,
+		}, nil
+	}
+
+	if config.CloudEntry != "" {
+		return nil, errors.New("explicit credentials can't be combined with a cloud entry from the clouds.yaml")
+	}
+
+	credentials, err := config.Credentials.withResolvedSecrets()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := credentials.validate(); err != nil {
+		return nil, err
+	}
+
+	region := config.Region
+	if region == "" {
+		region = os.Getenv("OS_REGION_NAME")
+	}
+
+	authType := clientconfig.AuthPassword
+	if credentials.ApplicationCredentialID != "" {
+		authType = clientconfig.AuthV3ApplicationCredential
+	}
+
+	return &clientconfig.ClientOpts{
+		AuthType:   authType,
+		RegionName: region,
+		EnvPrefix:  explicitCredentialsEnvPrefix,
+		AuthInfo: &clientconfig.AuthInfo{
+			AuthURL:                     credentials.AuthURL,
+			Username:                    credentials.Username,
+			Password:                    credentials.Password,
+			ApplicationCredentialID:     credentials.ApplicationCredentialID,
+			ApplicationCredentialSecret: credentials.ApplicationCredentialSecret,
+			ProjectName:                 credentials.ProjectName,
+			UserDomainName:              credentials.UserDomainName,
+			ProjectDomainName:           credentials.ProjectDomainName,
+		},
+	}, nil
+}
@@ -0,0 +1,117 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos
+
+import (
+	"github.com/gophercloud/gophercloud"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+	"github.com/submariner-io/cloud-prepare/pkg/rhos"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// NewRegionGatewayDeployer returns the dedicated gateway deployer RunOn sets up for the given one of several regions,
+// using the given clients.
+func NewRegionGatewayDeployer(client *gophercloud.ProviderClient, infraID string, region RegionGateways,
+	msDeployer ocp.MachineSetDeployer, k8sClient k8s.Interface,
+) api.GatewayDeployer {
+	config := regionConfig(&Config{InfraID: infraID, DedicatedGateway: true}, region)
+	msDeployer, k8sClient = scopeToRegion(config, msDeployer, k8sClient)
+
+	return withRegionGatewayCount(config, rhos.NewOcpGatewayDeployer(rhos.CloudInfo{
+		Client:    client,
+		InfraID:   infraID,
+		Region:    config.Region,
+		K8sClient: k8sClient,
+	}, msDeployer, "project", "flavor", "", "openstack", true))
+}
+
+// NewGatewayMachineSet returns the gateway machine set deployed with the given existing security group.
+func NewGatewayMachineSet(infraID, securityGroup string) (*unstructured.Unstructured, error) {
+	return newGatewayMachineSet(&gatewayMachineSetConfig{
+		Index:         "0",
+		InfraID:       infraID,
+		InstanceType:  "flavor",
+		Image:         "rhcos",
+		CloudName:     "openstack",
+		SecurityGroup: securityGroup,
+	})
+}
+
+// NewTaggingCloud returns the cloud and gateway deployer RunOn sets up for dedicated gateways, using the given clients.
+func NewTaggingCloud(client *gophercloud.ProviderClient, infraID, region string, msDeployer ocp.MachineSetDeployer,
+	k8sClient k8s.Interface,
+) (api.Cloud, api.GatewayDeployer) {
+	cloudInfo := rhos.CloudInfo{Client: client, InfraID: infraID, Region: region, K8sClient: k8sClient}
+
+	tagger, err := newTagger(client, region, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	keeper := &securityGroupKeepingGatewayDeployer{
+		GatewayDeployer: rhos.NewOcpGatewayDeployer(cloudInfo, msDeployer, "project", "flavor", "", "openstack", true),
+		msDeployer:      msDeployer,
+		k8sClient:       k8sClient,
+	}
+
+	return &taggingCloud{Cloud: rhos.NewCloud(cloudInfo), tagger: tagger, infraID: infraID},
+		&taggingGatewayDeployer{GatewayDeployer: keeper, tagger: tagger, infraID: infraID, keeper: keeper}
+}
+
+// NewPreview returns the cloud and gateway deployer RunOn sets up in preview mode, using the given clients, and a function
+// reporting the recorded operations.
+func NewPreview(client *gophercloud.ProviderClient, config *Config, msDeployer ocp.MachineSetDeployer,
+) (api.Cloud, api.GatewayDeployer, func(reporter.Interface)) {
+	preview := &plan{}
+
+	resources, err := newInventory(client, config, msDeployer)
+	if err != nil {
+		panic(err)
+	}
+
+	return &previewCloud{plan: preview, infraID: config.InfraID, inventory: resources},
+		&previewGatewayDeployer{plan: preview, config: config, inventory: resources}, preview.report
+}
+
+// RunOnRegions runs runRegion on each of the configured regions, as RunOn runs the function on them.
+func RunOnRegions(config *Config, status reporter.Interface, runRegion func(*Config, reporter.Interface) error) error {
+	return runOnRegions(config, status, runRegion)
+}
+
+// NewQuotaCheckingGatewayDeployer returns the given deployer, checking the instance quota before deploying.
+func NewQuotaCheckingGatewayDeployer(client *gophercloud.ProviderClient, config *Config, msDeployer ocp.MachineSetDeployer,
+	deployer api.GatewayDeployer,
+) api.GatewayDeployer {
+	return &quotaCheckingGatewayDeployer{GatewayDeployer: deployer, client: client, config: config, msDeployer: msDeployer}
+}
+
+// VerifyCleanup checks that the RHOS resources were deleted, as RunOn does after cleaning up.
+func VerifyCleanup(client *gophercloud.ProviderClient, config *Config, msDeployer ocp.MachineSetDeployer,
+	status reporter.Interface,
+) error {
+	resources, err := newInventory(client, config, msDeployer)
+	if err != nil {
+		return err
+	}
+
+	return verifyCleanup(resources, config, status)
+}
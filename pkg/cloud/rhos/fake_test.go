@@ -0,0 +1,390 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gophercloud/gophercloud"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type fakeSecurityGroup struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+}
+
+type fakeServer struct {
+	ID             string              `json:"id"`
+	Name           string              `json:"name"`
+	Status         string              `json:"status"`
+	Metadata       map[string]string   `json:"metadata"`
+	SecurityGroups []map[string]string `json:"security_groups"`
+}
+
+type fakeFloatingIP struct {
+	ID          string   `json:"id"`
+	FloatingIP  string   `json:"floating_ip_address"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+}
+
+type fakeInstanceQuota struct {
+	InUse    int `json:"in_use"`
+	Limit    int `json:"limit"`
+	Reserved int `json:"reserved"`
+}
+
+// fakeOpenStack serves the subset of the compute and network APIs used by the RHOS deployers, from in-memory resources
+// shared by all the regions. The requests to the regions listed in failingRegions fail.
+type fakeOpenStack struct {
+	server         *httptest.Server
+	securityGroups []fakeSecurityGroup
+	servers        []fakeServer
+	floatingIPs    []fakeFloatingIP
+	failingRegions []string
+	mutex          sync.Mutex
+	quota          fakeInstanceQuota
+	nextID         int
+}
+
+func newFakeOpenStack() *fakeOpenStack {
+	f := &fakeOpenStack{quota: fakeInstanceQuota{Limit: -1}}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	DeferCleanup(f.server.Close)
+
+	return f
+}
+
+// providerClient returns a client whose compute and network endpoints, in each region, are served by the fake.
+func (f *fakeOpenStack) providerClient() *gophercloud.ProviderClient {
+	return &gophercloud.ProviderClient{
+		HTTPClient: *http.DefaultClient,
+		EndpointLocator: func(opts gophercloud.EndpointOpts) (string, error) {
+			return fmt.Sprintf("%s/%s/%s/", f.server.URL, opts.Region, opts.Type), nil
+		},
+	}
+}
+
+func (f *fakeOpenStack) addSecurityGroup(name, description string, tags ...string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.securityGroups = append(f.securityGroups, fakeSecurityGroup{ID: f.newID(), Name: name, Description: description, Tags: tags})
+}
+
+func (f *fakeOpenStack) securityGroupNames() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	names := []string{}
+	for i := range f.securityGroups {
+		names = append(names, f.securityGroups[i].Name)
+	}
+
+	return names
+}
+
+func (f *fakeOpenStack) securityGroupTags(name string) []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for i := range f.securityGroups {
+		if f.securityGroups[i].Name == name {
+			return f.securityGroups[i].Tags
+		}
+	}
+
+	return nil
+}
+
+func (f *fakeOpenStack) addServer(name string, metadata map[string]string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.servers = append(f.servers, fakeServer{ID: f.newID(), Name: name, Status: "ACTIVE", Metadata: metadata})
+}
+
+func (f *fakeOpenStack) addFloatingIP(address, description string, tags ...string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.floatingIPs = append(f.floatingIPs, fakeFloatingIP{ID: f.newID(), FloatingIP: address, Description: description, Tags: tags})
+}
+
+func (f *fakeOpenStack) setQuota(quota fakeInstanceQuota) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.quota = quota
+}
+
+func (f *fakeOpenStack) failRegion(region string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.failingRegions = append(f.failingRegions, region)
+}
+
+func (f *fakeOpenStack) newID() string {
+	f.nextID++
+	return fmt.Sprintf("id-%d", f.nextID)
+}
+
+func (f *fakeOpenStack) serve(writer http.ResponseWriter, request *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	// The paths are /<region>/<service type>/<resource path>
+	parts := strings.SplitN(strings.TrimPrefix(request.URL.Path, "/"), "/", 3)
+	if len(parts) < 3 {
+		http.NotFound(writer, request)
+		return
+	}
+
+	for _, region := range f.failingRegions {
+		if parts[0] == region {
+			http.Error(writer, "region "+region+" is failing", http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	resource := strings.TrimPrefix(parts[2], "v2.0/")
+	query := request.URL.Query()
+
+	switch {
+	case request.Method == http.MethodGet && resource == "os-security-groups":
+		respond(writer, map[string]interface{}{"security_groups": f.securityGroups})
+	case request.Method == http.MethodPost && resource == "os-security-groups":
+		var body struct {
+			SecurityGroup fakeSecurityGroup `json:"security_group"`
+		}
+
+		decode(request, &body)
+		body.SecurityGroup.ID = f.newID()
+		f.securityGroups = append(f.securityGroups, body.SecurityGroup)
+		respond(writer, body)
+	case request.Method == http.MethodDelete && strings.HasPrefix(resource, "os-security-groups/"):
+		f.securityGroups = removeByID(f.securityGroups, path.Base(resource), func(g fakeSecurityGroup) string { return g.ID })
+
+		writer.WriteHeader(http.StatusAccepted)
+	case request.Method == http.MethodGet && resource == "security-groups":
+		found := []fakeSecurityGroup{}
+
+		for i := range f.securityGroups {
+			if name := query.Get("name"); name == "" || f.securityGroups[i].Name == name {
+				found = append(found, f.securityGroups[i])
+			}
+		}
+
+		respond(writer, map[string]interface{}{"security_groups": found})
+	case request.Method == http.MethodPut && strings.HasPrefix(resource, "security-groups/") && strings.HasSuffix(resource, "/tags"):
+		var body struct {
+			Tags []string `json:"tags"`
+		}
+
+		decode(request, &body)
+
+		for i := range f.securityGroups {
+			if f.securityGroups[i].ID == path.Base(path.Dir(resource)) {
+				f.securityGroups[i].Tags = body.Tags
+			}
+		}
+
+		respond(writer, body)
+	case request.Method == http.MethodGet && resource == "servers/detail":
+		found := []fakeServer{}
+
+		for i := range f.servers {
+			if strings.HasPrefix(f.servers[i].Name, strings.TrimPrefix(query.Get("name"), "^")) {
+				found = append(found, f.servers[i])
+			}
+		}
+
+		respond(writer, map[string]interface{}{"servers": found})
+	case request.Method == http.MethodPost && strings.HasPrefix(resource, "servers/") && strings.HasSuffix(resource, "/action"):
+		writer.WriteHeader(http.StatusAccepted)
+	case request.Method == http.MethodGet && strings.HasPrefix(resource, "os-quota-sets/"):
+		respond(writer, map[string]interface{}{"quota_set": map[string]interface{}{"instances": f.quota}})
+	case request.Method == http.MethodGet && resource == "floatingips":
+		found := []fakeFloatingIP{}
+
+		for i := range f.floatingIPs {
+			if description := query.Get("description"); description == "" || f.floatingIPs[i].Description == description {
+				found = append(found, f.floatingIPs[i])
+			}
+		}
+
+		respond(writer, map[string]interface{}{"floatingips": found})
+	case request.Method == http.MethodDelete && strings.HasPrefix(resource, "floatingips/"):
+		f.floatingIPs = removeByID(f.floatingIPs, path.Base(resource), func(ip fakeFloatingIP) string { return ip.ID })
+
+		writer.WriteHeader(http.StatusNoContent)
+	case request.Method == http.MethodGet && resource == "ports":
+		respond(writer, map[string]interface{}{"ports": []interface{}{}})
+	case request.Method == http.MethodGet && resource == "security-group-rules":
+		respond(writer, map[string]interface{}{"security_group_rules": []interface{}{}})
+	default:
+		http.NotFound(writer, request)
+	}
+}
+
+func respond(writer http.ResponseWriter, body interface{}) {
+	writer.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(writer).Encode(body); err != nil {
+		panic(err)
+	}
+}
+
+func decode(request *http.Request, body interface{}) {
+	if err := json.NewDecoder(request.Body).Decode(body); err != nil {
+		panic(err)
+	}
+}
+
+func removeByID[T any](items []T, id string, idOf func(T) string) []T {
+	kept := []T{}
+
+	for _, item := range items {
+		if idOf(item) != id {
+			kept = append(kept, item)
+		}
+	}
+
+	return kept
+}
+
+// fakeMachineSetDeployer keeps the deployed machine sets in memory, listing those which deploy gateways.
+type fakeMachineSetDeployer struct {
+	machineSets map[string]*unstructured.Unstructured
+}
+
+func newFakeMachineSetDeployer() *fakeMachineSetDeployer {
+	return &fakeMachineSetDeployer{machineSets: map[string]*unstructured.Unstructured{}}
+}
+
+func (d *fakeMachineSetDeployer) Deploy(machineSet *unstructured.Unstructured) error {
+	d.machineSets[machineSet.GetName()] = machineSet.DeepCopy()
+	return nil
+}
+
+func (d *fakeMachineSetDeployer) GetWorkerNodeImage(_ *unstructured.Unstructured, _ string) (string, error) {
+	return "rhcos", nil
+}
+
+func (d *fakeMachineSetDeployer) List() ([]unstructured.Unstructured, error) {
+	machineSets := []unstructured.Unstructured{}
+
+	for _, machineSet := range d.machineSets {
+		labels, _, _ := unstructured.NestedStringMap(machineSet.Object, "spec", "template", "spec", "metadata", "labels")
+		if labels["submariner.io/gateway"] == "true" {
+			machineSets = append(machineSets, *machineSet)
+		}
+	}
+
+	return machineSets, nil
+}
+
+func (d *fakeMachineSetDeployer) Delete(machineSet *unstructured.Unstructured) error {
+	delete(d.machineSets, machineSet.GetName())
+	return nil
+}
+
+func (d *fakeMachineSetDeployer) DeleteByName(name, _ string) error {
+	delete(d.machineSets, name)
+	return nil
+}
+
+func (d *fakeMachineSetDeployer) names() []string {
+	names := []string{}
+	for name := range d.machineSets {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// fakeReporter records the messages reported, by kind, and all the events in order.
+type fakeReporter struct {
+	started   []string
+	successes []string
+	warnings  []string
+	failures  []string
+	events    []string
+}
+
+func (r *fakeReporter) Start(message string, args ...interface{}) {
+	r.started = append(r.started, fmt.Sprintf(message, args...))
+	r.events = append(r.events, "start: "+fmt.Sprintf(message, args...))
+}
+
+func (r *fakeReporter) End() {
+	r.events = append(r.events, "end")
+}
+
+func (r *fakeReporter) Success(message string, args ...interface{}) {
+	r.successes = append(r.successes, fmt.Sprintf(message, args...))
+	r.events = append(r.events, "success: "+fmt.Sprintf(message, args...))
+}
+
+func (r *fakeReporter) Warning(message string, args ...interface{}) {
+	r.warnings = append(r.warnings, fmt.Sprintf(message, args...))
+	r.events = append(r.events, "warning: "+fmt.Sprintf(message, args...))
+}
+
+func (r *fakeReporter) Failure(message string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(message, args...))
+	r.events = append(r.events, "failure: "+fmt.Sprintf(message, args...))
+}
+
+func (r *fakeReporter) Error(err error, message string, args ...interface{}) error {
+	if err != nil {
+		r.Failure(message+": %v", append(args, err)...)
+		r.End()
+	}
+
+	return err
+}
+
+// fakeGatewayDeployer records the numbers of gateways it's asked to deploy.
+type fakeGatewayDeployer struct {
+	gateways []int
+}
+
+//nolint:gocritic // hugeParam: input - match the interface.
+func (d *fakeGatewayDeployer) Deploy(input api.GatewayDeployInput, _ reporter.Interface) error {
+	d.gateways = append(d.gateways, input.Gateways)
+	return nil
+}
+
+func (d *fakeGatewayDeployer) Cleanup(_ reporter.Interface) error {
+	return nil
+}
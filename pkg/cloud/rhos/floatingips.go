@@ -0,0 +1,219 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/attributestags"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/floatingips"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	floatingIPResource     = "floatingips"
+	gatewayInstanceTimeout = 10 * time.Minute
+	gatewayInstanceActive  = "ACTIVE"
+)
+
+// floatingIPGatewayDeployer assigns the dedicated gateway instances a floating IP from the selected external network once
+// they're active, since cloud-prepare doesn't allocate any; the floating IPs it assigned are released when cleaning up.
+type floatingIPGatewayDeployer struct {
+	api.GatewayDeployer
+	inventory   *inventory
+	networkID   string
+	networkName string
+}
+
+func floatingIPDescription(infraID string) string {
+	return "Submariner gateway floating IP for " + infraID
+}
+
+//nolint:gocritic // hugeParam: input - match the interface.
+func (d *floatingIPGatewayDeployer) Deploy(input api.GatewayDeployInput, status reporter.Interface) error {
+	if err := d.GatewayDeployer.Deploy(input, status); err != nil {
+		return err //nolint:wrapcheck // No need to wrap here
+	}
+
+	if d.networkID == "" || input.Gateways == 0 {
+		return nil
+	}
+
+	status.Start("Assigning floating IPs from external network %q to the gateway instances", d.networkName)
+	defer status.End()
+
+	instances, err := d.activeGatewayInstances()
+	if err != nil {
+		return status.Error(err, "Error waiting for the gateway instances")
+	}
+
+	for i := range instances {
+		if err := d.assignFloatingIP(&instances[i], status); err != nil {
+			return status.Error(err, "Error assigning a floating IP to gateway instance %q", instances[i].Name)
+		}
+	}
+
+	return nil
+}
+
+// activeGatewayInstances waits until an active instance, carrying Submariner's tag, exists for each gateway machine set.
+func (d *floatingIPGatewayDeployer) activeGatewayInstances() ([]servers.Server, error) {
+	var instances []servers.Server
+
+	err := wait.PollImmediate(verifyInterval, gatewayInstanceTimeout, func() (bool, error) {
+		machineSets, err := d.inventory.msDeployer.List()
+		if err != nil {
+			return false, errors.Wrap(err, "error listing the gateway machine sets")
+		}
+
+		expected := 0
+
+		for i := range machineSets {
+			if strings.HasPrefix(machineSets[i].GetName(), d.inventory.infraID) {
+				expected++
+			}
+		}
+
+		instances, err = d.inventory.gatewayInstances()
+		if err != nil {
+			return false, err
+		}
+
+		for i := range instances {
+			if instances[i].Status != gatewayInstanceActive {
+				return false, nil
+			}
+		}
+
+		return len(instances) >= expected, nil
+	})
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return nil, fmt.Errorf("the gateway instances weren't all %s after %v", gatewayInstanceActive, gatewayInstanceTimeout)
+	}
+
+	return instances, err //nolint:wrapcheck // No need to wrap here
+}
+
+func (d *floatingIPGatewayDeployer) assignFloatingIP(instance *servers.Server, status reporter.Interface) error {
+	pages, err := ports.List(d.inventory.tagger.networkClient, ports.ListOpts{DeviceID: instance.ID}).AllPages()
+	if err != nil {
+		return errors.Wrapf(err, "error listing the ports of instance %s", instance.ID)
+	}
+
+	found, err := ports.ExtractPorts(pages)
+	if err != nil {
+		return errors.Wrap(err, "error extracting the ports")
+	}
+
+	// The gateway machine sets only attach the instances to the cluster's nodes network
+	if len(found) == 0 {
+		return fmt.Errorf("instance %s has no port", instance.ID)
+	}
+
+	ips, err := d.inventory.floatingIPsOf(found[0].ID)
+	if err != nil {
+		return err
+	}
+
+	if len(ips) > 0 {
+		status.Success("Gateway instance %q already has floating IP %s", instance.Name, ips[0])
+		return nil
+	}
+
+	ip, err := floatingips.Create(d.inventory.tagger.networkClient, floatingips.CreateOpts{
+		FloatingNetworkID: d.networkID,
+		PortID:            found[0].ID,
+		Description:       floatingIPDescription(d.inventory.infraID),
+	}).Extract()
+	if err != nil {
+		return errors.Wrapf(err, "error creating a floating IP on network %s", d.networkID)
+	}
+
+	_, err = attributestags.ReplaceAll(d.inventory.tagger.networkClient, floatingIPResource, ip.ID,
+		attributestags.ReplaceAllOpts{Tags: d.inventory.tagger.tags}).Extract()
+	if err != nil {
+		return errors.Wrapf(err, "error tagging the floating IP %s (%s)", ip.FloatingIP, ip.ID)
+	}
+
+	status.Success("Assigned floating IP %s to gateway instance %q", ip.FloatingIP, instance.Name)
+
+	return nil
+}
+
+func (d *floatingIPGatewayDeployer) Cleanup(status reporter.Interface) error {
+	found, err := d.inventory.gatewayFloatingIPs()
+	if err != nil {
+		return status.Error(err, "Error looking up the gateway floating IPs")
+	}
+
+	for i := range found {
+		if err := floatingips.Delete(d.inventory.tagger.networkClient, found[i].ID).ExtractErr(); err != nil {
+			return status.Error(err, "Error releasing the gateway floating IP %s (%s)", found[i].FloatingIP, found[i].ID)
+		}
+
+		status.Success("Released the gateway floating IP %s", found[i].FloatingIP)
+	}
+
+	return d.GatewayDeployer.Cleanup(status) //nolint:wrapcheck // No need to wrap here
+}
+
+// gatewayInstances returns the instances named after the infra ID which carry Submariner's tag.
+func (i *inventory) gatewayInstances() ([]servers.Server, error) {
+	pages, err := servers.List(i.computeClient, servers.ListOpts{Name: "^" + i.infraID}).AllPages()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing the instances named after %q", i.infraID)
+	}
+
+	found, err := servers.ExtractServers(pages)
+	if err != nil {
+		return nil, errors.Wrap(err, "error extracting the instances")
+	}
+
+	instances := []servers.Server{}
+
+	for j := range found {
+		if _, ok := found[j].Metadata[submarinerTag]; ok {
+			instances = append(instances, found[j])
+		}
+	}
+
+	return instances, nil
+}
+
+// gatewayFloatingIPs returns the floating IPs which subctl assigned to the gateway instances.
+func (i *inventory) gatewayFloatingIPs() ([]floatingips.FloatingIP, error) {
+	pages, err := floatingips.List(i.tagger.networkClient, floatingips.ListOpts{
+		Description: floatingIPDescription(i.infraID),
+		Tags:        submarinerTag,
+	}).AllPages()
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the gateway floating IPs")
+	}
+
+	found, err := floatingips.ExtractFloatingIPs(pages)
+
+	return found, errors.Wrap(err, "error extracting the floating IPs")
+}
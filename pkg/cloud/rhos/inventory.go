@@ -0,0 +1,139 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos
+
+import (
+	"strings"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+)
+
+// inventory looks up the existing RHOS resources which a cleanup would affect, so that they can be listed beforehand.
+type inventory struct {
+	tagger        *tagger
+	computeClient *gophercloud.ServiceClient
+	msDeployer    ocp.MachineSetDeployer
+	infraID       string
+}
+
+func newInventory(client *gophercloud.ProviderClient, config *Config, msDeployer ocp.MachineSetDeployer) (*inventory, error) {
+	tagger, err := newTagger(client, config.Region, config.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	computeClient, err := openstack.NewComputeV2(client, gophercloud.EndpointOpts{Region: config.Region})
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating the compute client")
+	}
+
+	return &inventory{tagger: tagger, computeClient: computeClient, msDeployer: msDeployer, infraID: config.InfraID}, nil
+}
+
+// planSecurityGroupDeletion records the detachment and deletion of the security groups with the given name suffix. They
+// are checked as the cleanup checks them: when any may not have been created by subctl, the refusal to delete them is
+// recorded instead.
+func (i *inventory) planSecurityGroupDeletion(p *plan, suffix string) error {
+	check, err := i.tagger.checkSecurityGroups(i.infraID, suffix)
+	if err != nil {
+		return err
+	}
+
+	if !check.deletable() {
+		for j := range check.foreign {
+			p.record("refuse to delete security group %q (%s), which isn't tagged %q and may not have been created by subctl",
+				check.name, check.foreign[j].ID, submarinerTag)
+		}
+
+		return nil
+	}
+
+	if len(check.owned)+len(check.legacy) == 0 {
+		p.record("find no security group %q to delete", check.name)
+		return nil
+	}
+
+	instances, err := i.instancesWithSecurityGroup(check.name)
+	if err != nil {
+		return err
+	}
+
+	for _, instance := range instances {
+		p.record("remove security group %q from instance %q", check.name, instance)
+	}
+
+	for j := range check.owned {
+		p.record("delete security group %q (%s)", check.name, check.owned[j].ID)
+	}
+
+	for j := range check.legacy {
+		p.record("delete security group %q (%s), created by an earlier version without the %q tag", check.name,
+			check.legacy[j].ID, submarinerTag)
+	}
+
+	return nil
+}
+
+// instancesWithSecurityGroup returns the names of the cluster's instances which use the named security group.
+func (i *inventory) instancesWithSecurityGroup(name string) ([]string, error) {
+	pages, err := servers.List(i.computeClient, servers.ListOpts{Name: "^" + i.infraID}).AllPages()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing the instances named after %q", i.infraID)
+	}
+
+	found, err := servers.ExtractServers(pages)
+	if err != nil {
+		return nil, errors.Wrap(err, "error extracting the instances")
+	}
+
+	instances := []string{}
+
+	for j := range found {
+		for _, group := range found[j].SecurityGroups {
+			if groupName, ok := group["name"].(string); ok && groupName == name {
+				instances = append(instances, found[j].Name)
+				break
+			}
+		}
+	}
+
+	return instances, nil
+}
+
+// planMachineSetDeletion records the deletion of the gateway machine sets, and thus of their instances.
+func (i *inventory) planMachineSetDeletion(p *plan) error {
+	machineSets, err := i.msDeployer.List()
+	if err != nil {
+		return errors.Wrap(err, "error listing the gateway machine sets")
+	}
+
+	for j := range machineSets {
+		if !strings.HasPrefix(machineSets[j].GetName(), i.infraID) {
+			continue
+		}
+
+		p.record("delete machine set %q and its gateway instance(s)", machineSets[j].GetName())
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos
+
+import (
+	"bytes"
+	"text/template"
+	_ "unsafe" // Required by go:linkname
+
+	"github.com/pkg/errors"
+	// The package providing the gateway machine set template
+	_ "github.com/submariner-io/cloud-prepare/pkg/rhos"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
+)
+
+// cloudPrepareMachineSetYAML is the template cloud-prepare deploys its gateway machine sets from; it isn't exported, but
+// is used as is so that the gateway machine sets deployed with an existing security group don't drift from cloud-prepare's.
+//
+//go:linkname cloudPrepareMachineSetYAML github.com/submariner-io/cloud-prepare/pkg/rhos.machineSetYAML
+var cloudPrepareMachineSetYAML string
+
+// gatewayNodeTag is the instance tag cloud-prepare gives the gateway nodes.
+const gatewayNodeTag = "submariner-io-gateway-node"
+
+// gatewayMachineSetConfig holds the variables of cloud-prepare's template, and the gateway security group which replaces
+// the one named after the infra ID.
+type gatewayMachineSetConfig struct {
+	Index               string
+	InfraID             string
+	ProjectID           string
+	InstanceType        string
+	Region              string
+	Image               string
+	SubmarinerGWNodeTag string
+	CloudName           string
+	SecurityGroup       string
+}
+
+func newGatewayMachineSet(config *gatewayMachineSetConfig) (*unstructured.Unstructured, error) {
+	tpl, err := template.New("").Parse(cloudPrepareMachineSetYAML)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing the gateway machine set template")
+	}
+
+	var buf bytes.Buffer
+
+	vars := *config
+	vars.SubmarinerGWNodeTag = gatewayNodeTag
+
+	if err := tpl.Execute(&buf, &vars); err != nil {
+		return nil, errors.Wrap(err, "error executing the gateway machine set template")
+	}
+
+	machineSet := &unstructured.Unstructured{}
+
+	_, _, err = yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme).Decode(buf.Bytes(), nil, machineSet)
+	if err != nil {
+		return nil, errors.Wrap(err, "error decoding the gateway machine set")
+	}
+
+	return machineSet, replaceGatewaySecurityGroup(machineSet, config.InfraID+gwSecurityGroupSuffix, config.SecurityGroup)
+}
+
+// replaceGatewaySecurityGroup replaces the named security group in the machine set's provider spec; the machine set is
+// rejected if it doesn't reference it, rather than deploying gateways without the gateway security group.
+func replaceGatewaySecurityGroup(machineSet *unstructured.Unstructured, name, replacement string) error {
+	path := []string{"spec", "template", "spec", "providerSpec", "value", "securityGroups"}
+
+	securityGroups, _, err := unstructured.NestedSlice(machineSet.Object, path...)
+	if err != nil {
+		return errors.Wrap(err, "error reading the security groups of the gateway machine set")
+	}
+
+	for i := range securityGroups {
+		securityGroup, ok := securityGroups[i].(map[string]interface{})
+		if ok && securityGroup["name"] == name {
+			securityGroup["name"] = replacement
+
+			return errors.Wrap(unstructured.SetNestedSlice(machineSet.Object, securityGroups, path...),
+				"error setting the security groups of the gateway machine set")
+		}
+	}
+
+	return errors.Errorf("the gateway machine set template doesn't reference the security group %q", name)
+}
@@ -0,0 +1,49 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/pkg/cloud/rhos"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Gateway machine set", func() {
+	It("should be cloud-prepare's, with the given security group instead of the gateway security group", func() {
+		machineSet, err := rhos.NewGatewayMachineSet(infraID, "existing-sg")
+		Expect(err).To(Succeed())
+		Expect(machineSet.GetName()).To(Equal(infraID + "-submariner-gw-0"))
+
+		securityGroups, _, err := unstructured.NestedSlice(machineSet.Object, "spec", "template", "spec", "providerSpec", "value",
+			"securityGroups")
+		Expect(err).To(Succeed())
+
+		names := []interface{}{}
+		for i := range securityGroups {
+			names = append(names, securityGroups[i].(map[string]interface{})["name"])
+		}
+
+		Expect(names).To(ConsistOf(infraID+"-worker", "existing-sg", infraID+"-submariner-internal-sg"))
+
+		tags, _, err := unstructured.NestedStringSlice(machineSet.Object, "spec", "template", "spec", "providerSpec", "value", "tags")
+		Expect(err).To(Succeed())
+		Expect(tags).To(ContainElement("submariner-io-gateway-node"))
+	})
+})
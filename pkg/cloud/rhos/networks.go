@@ -0,0 +1,65 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos
+
+import (
+	"fmt"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/external"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/networks"
+	"github.com/pkg/errors"
+)
+
+type externalNetwork struct {
+	networks.Network
+	external.NetworkExternalExt
+}
+
+// lookupExternalNetwork returns the ID of the named network, ensuring it exists and is external.
+func lookupExternalNetwork(client *gophercloud.ProviderClient, region, name string) (string, error) {
+	networkClient, err := openstack.NewNetworkV2(client, gophercloud.EndpointOpts{Region: region})
+	if err != nil {
+		return "", errors.Wrap(err, "error creating the network client")
+	}
+
+	pages, err := networks.List(networkClient, networks.ListOpts{Name: name}).AllPages()
+	if err != nil {
+		return "", errors.Wrapf(err, "error listing the networks named %q", name)
+	}
+
+	var found []externalNetwork
+
+	err = networks.ExtractNetworksInto(pages, &found)
+	if err != nil {
+		return "", errors.Wrap(err, "error extracting the networks")
+	}
+
+	switch {
+	case len(found) == 0:
+		return "", fmt.Errorf("network %q wasn't found in region %q", name, region)
+	case len(found) > 1:
+		return "", fmt.Errorf("found %d networks named %q, expected exactly one", len(found), name)
+	case !found[0].External:
+		return "", fmt.Errorf("network %q (%s) isn't an external network", name, found[0].ID)
+	}
+
+	return found[0].ID, nil
+}
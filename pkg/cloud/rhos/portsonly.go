@@ -0,0 +1,37 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos
+
+import (
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+)
+
+// portsOnlyGatewayDeployer only configures the gateway security group rules, for gateway nodes managed externally. The
+// deployers only add gateways when there are fewer than requested, so requesting none restricts them to creating or
+// updating the security group and attaching it to the existing gateway nodes.
+type portsOnlyGatewayDeployer struct {
+	api.GatewayDeployer
+}
+
+func (d *portsOnlyGatewayDeployer) Deploy(input api.GatewayDeployInput, status reporter.Interface) error {
+	input.Gateways = 0
+
+	return d.GatewayDeployer.Deploy(input, status) //nolint:wrapcheck // No need to wrap here
+}
@@ -0,0 +1,177 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+)
+
+// These match the names used by the cloud-prepare RHOS implementation.
+const (
+	gwSecurityGroupSuffix       = "-submariner-gw-sg"
+	internalSecurityGroupSuffix = "-submariner-internal-sg"
+	allNetworkCIDR              = "0.0.0.0/0"
+)
+
+// plan records the operations which would be performed on RHOS, without performing them. When cleaning up, the existing
+// resources which would be deleted are listed.
+type plan struct {
+	operations []string
+}
+
+func (p *plan) record(format string, args ...interface{}) {
+	p.operations = append(p.operations, fmt.Sprintf(format, args...))
+}
+
+func (p *plan) report(status reporter.Interface) {
+	status.Start("Previewing the changes to RHOS")
+	defer status.End()
+
+	if len(p.operations) == 0 {
+		status.Success("No changes would be made")
+		return
+	}
+
+	for _, operation := range p.operations {
+		status.Success("Would %s", operation)
+	}
+}
+
+type previewCloud struct {
+	plan      *plan
+	infraID   string
+	inventory *inventory
+}
+
+func (c *previewCloud) OpenPorts(ports []api.PortSpec, _ reporter.Interface) error {
+	groupName := c.infraID + internalSecurityGroupSuffix
+
+	c.plan.record("create security group %q, unless it exists, allowing %s from its members", groupName, formatPorts(ports))
+	c.plan.record("tag security group %q with %s", groupName, strings.Join(c.inventory.tagger.tags, ", "))
+	c.plan.record("add security group %q to the instances named after %q", groupName, c.infraID)
+
+	return nil
+}
+
+func (c *previewCloud) ClosePorts(_ reporter.Interface) error {
+	return c.inventory.planSecurityGroupDeletion(c.plan, internalSecurityGroupSuffix)
+}
+
+type previewGatewayDeployer struct {
+	plan      *plan
+	config    *Config
+	inventory *inventory
+}
+
+func (d *previewGatewayDeployer) Deploy(input api.GatewayDeployInput, _ reporter.Interface) error {
+	groupName := d.config.InfraID + gwSecurityGroupSuffix
+
+	if d.config.SecurityGroupName != "" {
+		groupName = d.config.SecurityGroupName
+
+		if err := d.planExistingSecurityGroup(input.PublicPorts); err != nil {
+			return err
+		}
+	} else {
+		d.plan.record("create security group %q, unless it exists, allowing %s from %s", groupName, formatPorts(input.PublicPorts),
+			allNetworkCIDR)
+		d.plan.record("tag security group %q with %s", groupName, strings.Join(d.inventory.tagger.tags, ", "))
+	}
+
+	if d.config.PortsOnly {
+		d.plan.record("add security group %q to the existing gateway nodes, without deploying or labeling any", groupName)
+	} else if d.config.DedicatedGateway {
+		d.plan.record("deploy up to %d dedicated gateway instance(s) of type %q, with security group %q", input.Gateways,
+			d.config.GWInstanceType, groupName)
+
+		if d.config.RootVolumeSizeGB > 0 {
+			d.plan.record("boot the gateway instances from a root volume of %d GB", d.config.RootVolumeSizeGB)
+		}
+
+		if d.config.ExternalNetwork != "" {
+			d.plan.record("assign each gateway instance a floating IP from external network %q, tagged with %s",
+				d.config.ExternalNetwork, strings.Join(d.inventory.tagger.tags, ", "))
+		}
+	} else {
+		d.plan.record("add security group %q to the %d worker node(s) labeled as gateways", groupName, input.Gateways)
+	}
+
+	return nil
+}
+
+// planExistingSecurityGroup records each rule which would be added to the existing security group.
+func (d *previewGatewayDeployer) planExistingSecurityGroup(ports []api.PortSpec) error {
+	group, err := findSecurityGroup(d.inventory.tagger.networkClient, d.config.SecurityGroupName)
+	if err != nil {
+		return err
+	}
+
+	missing, err := missingIngressRules(d.inventory.tagger.networkClient, group, ports)
+	if err != nil {
+		return err
+	}
+
+	for _, port := range missing {
+		d.plan.record("add %s", formatRule(port, group.Name))
+	}
+
+	if len(missing) == 0 {
+		d.plan.record("add no rule to security group %q, which already allows %s from %s", group.Name, formatPorts(ports),
+			allNetworkCIDR)
+	}
+
+	d.plan.record("add security group %q to the existing gateway nodes", group.Name)
+
+	return nil
+}
+
+func (d *previewGatewayDeployer) Cleanup(_ reporter.Interface) error {
+	ips, err := d.inventory.gatewayFloatingIPs()
+	if err != nil {
+		return err
+	}
+
+	for i := range ips {
+		d.plan.record("release gateway floating IP %s (%s)", ips[i].FloatingIP, ips[i].ID)
+	}
+
+	if err := d.inventory.planMachineSetDeletion(d.plan); err != nil {
+		return err
+	}
+
+	if d.config.SecurityGroupName != "" {
+		d.plan.record("remove security group %q from the labeled gateway nodes, keeping the group and its rules",
+			d.config.SecurityGroupName)
+	}
+
+	return d.inventory.planSecurityGroupDeletion(d.plan, gwSecurityGroupSuffix)
+}
+
+func formatPorts(ports []api.PortSpec) string {
+	formatted := make([]string, len(ports))
+	for i := range ports {
+		formatted[i] = fmt.Sprintf("%d/%s", ports[i].Port, ports[i].Protocol)
+	}
+
+	return strings.Join(formatted, ", ")
+}
@@ -0,0 +1,122 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/subctl/pkg/cloud/rhos"
+)
+
+var _ = Describe("Preview", func() {
+	var (
+		openStack  *fakeOpenStack
+		msDeployer *fakeMachineSetDeployer
+		config     *rhos.Config
+	)
+
+	BeforeEach(func() {
+		openStack = newFakeOpenStack()
+		msDeployer = newFakeMachineSetDeployer()
+		config = &rhos.Config{InfraID: infraID, Region: "regionOne", DedicatedGateway: true, GWInstanceType: "flavor"}
+	})
+
+	previewCleanup := func() []string {
+		cloud, gwDeployer, report := rhos.NewPreview(openStack.providerClient(), config, msDeployer)
+		Expect(gwDeployer.Cleanup(&fakeReporter{})).To(Succeed())
+		Expect(cloud.ClosePorts(&fakeReporter{})).To(Succeed())
+
+		status := &fakeReporter{}
+		report(status)
+
+		return status.successes
+	}
+
+	It("should list the deployment operations", func() {
+		cloud, gwDeployer, report := rhos.NewPreview(openStack.providerClient(), config, msDeployer)
+		Expect(gwDeployer.Deploy(api.GatewayDeployInput{Gateways: 2, PublicPorts: []api.PortSpec{{Port: 4500, Protocol: "udp"}}},
+			&fakeReporter{})).To(Succeed())
+		Expect(cloud.OpenPorts([]api.PortSpec{{Port: 4800, Protocol: "udp"}}, &fakeReporter{})).To(Succeed())
+
+		status := &fakeReporter{}
+		report(status)
+
+		Expect(status.successes).To(Equal([]string{
+			`Would create security group "` + gwSecurityGroup + `", unless it exists, allowing 4500/udp from 0.0.0.0/0`,
+			`Would tag security group "` + gwSecurityGroup + `" with submariner-managed`,
+			`Would deploy up to 2 dedicated gateway instance(s) of type "flavor", with security group "` + gwSecurityGroup + `"`,
+			`Would create security group "` + internalSecurityGroup + `", unless it exists, allowing 4800/udp from its members`,
+			`Would tag security group "` + internalSecurityGroup + `" with submariner-managed`,
+			`Would add security group "` + internalSecurityGroup + `" to the instances named after "` + infraID + `"`,
+		}))
+		Expect(openStack.securityGroupNames()).To(BeEmpty())
+	})
+
+	When("the security groups were created and tagged by subctl", func() {
+		BeforeEach(func() {
+			openStack.addSecurityGroup(gwSecurityGroup, "Submariner Gateway", "submariner-managed")
+			openStack.addSecurityGroup(internalSecurityGroup, "Submariner Internal", "submariner-managed")
+			openStack.addFloatingIP("10.0.0.1", "Submariner gateway floating IP for "+infraID, "submariner-managed")
+
+			machineSet, err := rhos.NewGatewayMachineSet(infraID, gwSecurityGroup)
+			Expect(err).To(Succeed())
+			Expect(msDeployer.Deploy(machineSet)).To(Succeed())
+		})
+
+		It("should list the resources the cleanup would delete", func() {
+			Expect(previewCleanup()).To(Equal([]string{
+				"Would release gateway floating IP 10.0.0.1 (id-3)",
+				`Would delete machine set "` + infraID + `-submariner-gw-0" and its gateway instance(s)`,
+				`Would delete security group "` + gwSecurityGroup + `" (id-1)`,
+				`Would delete security group "` + internalSecurityGroup + `" (id-2)`,
+			}))
+		})
+	})
+
+	When("the security groups were created by cloud-prepare before they were tagged", func() {
+		BeforeEach(func() {
+			openStack.addSecurityGroup(gwSecurityGroup, "Submariner Gateway")
+		})
+
+		It("should list them as deleted", func() {
+			Expect(previewCleanup()).To(ContainElement(`Would delete security group "` + gwSecurityGroup +
+				`" (id-1), created by an earlier version without the "submariner-managed" tag`))
+		})
+	})
+
+	When("the security groups may not have been created by subctl", func() {
+		BeforeEach(func() {
+			openStack.addSecurityGroup(gwSecurityGroup, "Someone else's gateway group")
+
+			machineSet, err := rhos.NewGatewayMachineSet(infraID, gwSecurityGroup)
+			Expect(err).To(Succeed())
+			Expect(msDeployer.Deploy(machineSet)).To(Succeed())
+		})
+
+		It("should list the refusal to delete them, but still the deletion of the gateways", func() {
+			Expect(previewCleanup()).To(Equal([]string{
+				`Would delete machine set "` + infraID + `-submariner-gw-0" and its gateway instance(s)`,
+				`Would refuse to delete security group "` + gwSecurityGroup + `" (id-1), which isn't tagged "submariner-managed" ` +
+					`and may not have been created by subctl`,
+				`Would find no security group "` + internalSecurityGroup + `" to delete`,
+			}))
+		})
+	})
+})
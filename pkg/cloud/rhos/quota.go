@@ -0,0 +1,162 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/quotasets"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ValidateGatewayCount checks the number of gateways to prepare, in each of the regions if several are configured: it must
+// be positive, unless only the ports are opened, and can't exceed MaxGateways. The gateways can only be spread over several
+// regions as dedicated instances, since the worker nodes labeled as gateways aren't tracked by region.
+func ValidateGatewayCount(config *Config) error {
+	if config.PortsOnly {
+		return nil
+	}
+
+	if len(config.Regions) == 0 {
+		return validateGatewayCount(config.Gateways, config.MaxGateways)
+	}
+
+	if !config.DedicatedGateway {
+		return errors.New("the gateways of several regions must be dedicated instances, worker nodes can't be used")
+	}
+
+	for _, region := range config.Regions {
+		if err := validateGatewayCount(region.Gateways, config.MaxGateways); err != nil {
+			return errors.Wrapf(err, "invalid gateway count for region %q", region.Region)
+		}
+	}
+
+	return nil
+}
+
+func validateGatewayCount(gateways, maxGateways int) error {
+	if gateways <= 0 {
+		return fmt.Errorf("the number of gateways must be positive, got %d", gateways)
+	}
+
+	if maxGateways > 0 && gateways > maxGateways {
+		return fmt.Errorf("the requested number of gateways (%d) exceeds the maximum of %d", gateways, maxGateways)
+	}
+
+	return nil
+}
+
+// checkInstanceQuota verifies that the project's compute quota can accommodate the gateway instances which remain to be
+// deployed, given the existing gateway machine sets. If the quota can't be retrieved, or would be exceeded in non-strict
+// mode, a warning is reported instead of an error.
+func checkInstanceQuota(client *gophercloud.ProviderClient, config *Config, gateways int, msDeployer ocp.MachineSetDeployer,
+	status reporter.Interface,
+) error {
+	machineSets, err := msDeployer.List()
+	if err != nil {
+		status.Warning("Unable to list the existing gateway machine sets to check the instance quota: %v", err)
+		return nil
+	}
+
+	toDeploy := gatewaysToDeploy(gateways, config.InfraID, machineSets)
+	if toDeploy == 0 {
+		status.Success("The %d gateway instance(s) are already deployed", gateways)
+		return nil
+	}
+
+	computeClient, err := openstack.NewComputeV2(client, gophercloud.EndpointOpts{Region: config.Region})
+	if err != nil {
+		status.Warning("Unable to create the compute client to check the instance quota: %v", err)
+		return nil
+	}
+
+	quota, err := quotasets.GetDetail(computeClient, config.ProjectID).Extract()
+	if err != nil {
+		status.Warning("Unable to retrieve the instance quota for project %q: %v", config.ProjectID, err)
+		return nil
+	}
+
+	instances := quota.Instances
+
+	// A negative limit means the quota is unlimited
+	if instances.Limit < 0 || instances.InUse+instances.Reserved+toDeploy <= instances.Limit {
+		return nil
+	}
+
+	err = fmt.Errorf("deploying %d gateway(s) would exceed the instance quota of project %q: the quota is %d,"+
+		" with %d instance(s) in use and %d reserved", toDeploy, config.ProjectID, instances.Limit,
+		instances.InUse, instances.Reserved)
+
+	if config.StrictQuota {
+		return err
+	}
+
+	status.Warning("%s", err.Error())
+
+	return nil
+}
+
+// gatewaysToDeploy returns the number of gateway instances which remain to be deployed, given the existing gateway machine
+// sets named after the infra ID.
+func gatewaysToDeploy(gateways int, infraID string, machineSets []unstructured.Unstructured) int {
+	existing := 0
+
+	for i := range machineSets {
+		if strings.HasPrefix(machineSets[i].GetName(), infraID) {
+			existing++
+		}
+	}
+
+	if existing >= gateways {
+		return 0
+	}
+
+	return gateways - existing
+}
+
+// quotaCheckingGatewayDeployer checks, before deploying dedicated gateways, that the instance quota can accommodate those
+// which remain to be deployed.
+type quotaCheckingGatewayDeployer struct {
+	api.GatewayDeployer
+	client     *gophercloud.ProviderClient
+	config     *Config
+	msDeployer ocp.MachineSetDeployer
+}
+
+//nolint:gocritic // hugeParam: input - match the interface.
+func (d *quotaCheckingGatewayDeployer) Deploy(input api.GatewayDeployInput, status reporter.Interface) error {
+	if input.Gateways > 0 && d.config.DedicatedGateway && !d.config.PortsOnly {
+		status.Start("Checking the instance quota for %d gateway(s)", input.Gateways)
+
+		if err := checkInstanceQuota(d.client, d.config, input.Gateways, d.msDeployer, status); err != nil {
+			return status.Error(err, "Insufficient instance quota")
+		}
+
+		status.End()
+	}
+
+	return d.GatewayDeployer.Deploy(input, status) //nolint:wrapcheck // No need to wrap here
+}
@@ -0,0 +1,166 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/subctl/pkg/cloud/rhos"
+)
+
+var _ = Describe("Gateway count validation", func() {
+	var config *rhos.Config
+
+	BeforeEach(func() {
+		config = &rhos.Config{Gateways: 1, DedicatedGateway: true}
+	})
+
+	It("should accept a positive count", func() {
+		Expect(rhos.ValidateGatewayCount(config)).To(Succeed())
+	})
+
+	It("should reject a count which isn't positive", func() {
+		config.Gateways = 0
+		Expect(rhos.ValidateGatewayCount(config)).NotTo(Succeed())
+
+		config.Gateways = -1
+		Expect(rhos.ValidateGatewayCount(config)).NotTo(Succeed())
+	})
+
+	It("should accept any count when only the ports are opened", func() {
+		config.Gateways = 0
+		config.PortsOnly = true
+		Expect(rhos.ValidateGatewayCount(config)).To(Succeed())
+	})
+
+	It("should reject a count above the maximum", func() {
+		config.Gateways = 3
+		config.MaxGateways = 2
+		Expect(rhos.ValidateGatewayCount(config)).NotTo(Succeed())
+
+		config.Gateways = 2
+		Expect(rhos.ValidateGatewayCount(config)).To(Succeed())
+	})
+
+	When("several regions are configured", func() {
+		BeforeEach(func() {
+			config.Regions = []rhos.RegionGateways{{Region: "regionA", Gateways: 1}, {Region: "regionB", Gateways: 2}}
+		})
+
+		It("should check each region's count", func() {
+			Expect(rhos.ValidateGatewayCount(config)).To(Succeed())
+
+			config.Regions[1].Gateways = 0
+			Expect(rhos.ValidateGatewayCount(config)).To(MatchError(ContainSubstring(`region "regionB"`)))
+		})
+
+		It("should reject gateways which aren't dedicated instances", func() {
+			config.DedicatedGateway = false
+			Expect(rhos.ValidateGatewayCount(config)).NotTo(Succeed())
+		})
+	})
+})
+
+var _ = Describe("Instance quota check", func() {
+	var (
+		openStack  *fakeOpenStack
+		msDeployer *fakeMachineSetDeployer
+		config     *rhos.Config
+		deployed   *fakeGatewayDeployer
+		status     *fakeReporter
+	)
+
+	BeforeEach(func() {
+		openStack = newFakeOpenStack()
+		msDeployer = newFakeMachineSetDeployer()
+		config = &rhos.Config{InfraID: infraID, ProjectID: "project", Region: "regionOne", DedicatedGateway: true}
+		deployed = &fakeGatewayDeployer{}
+		status = &fakeReporter{}
+
+		// Two instances can still be created
+		openStack.setQuota(fakeInstanceQuota{Limit: 10, InUse: 7, Reserved: 1})
+	})
+
+	deploy := func(gateways int) error {
+		return rhos.NewQuotaCheckingGatewayDeployer(openStack.providerClient(), config, msDeployer, deployed).
+			Deploy(api.GatewayDeployInput{Gateways: gateways}, status)
+	}
+
+	addGatewayMachineSets := func(count int) {
+		for i := 0; i < count; i++ {
+			machineSet, err := rhos.NewGatewayMachineSet(infraID, gwSecurityGroup)
+			Expect(err).To(Succeed())
+			machineSet.SetName(fmt.Sprintf("%s-submariner-gw-%d", infraID, i))
+			Expect(msDeployer.Deploy(machineSet)).To(Succeed())
+		}
+	}
+
+	It("should deploy the gateways the quota accommodates", func() {
+		Expect(deploy(2)).To(Succeed())
+		Expect(deployed.gateways).To(Equal([]int{2}))
+		Expect(status.warnings).To(BeEmpty())
+	})
+
+	It("should only count the gateways which remain to be deployed", func() {
+		addGatewayMachineSets(2)
+
+		config.StrictQuota = true
+		Expect(deploy(4)).To(Succeed())
+		Expect(deployed.gateways).To(Equal([]int{4}))
+	})
+
+	It("should not check the quota when all the gateways are deployed", func() {
+		addGatewayMachineSets(3)
+		openStack.setQuota(fakeInstanceQuota{Limit: 1, InUse: 1})
+
+		config.StrictQuota = true
+		Expect(deploy(3)).To(Succeed())
+		Expect(status.successes).To(ContainElement("The 3 gateway instance(s) are already deployed"))
+	})
+
+	It("should accept any number of gateways with an unlimited quota", func() {
+		openStack.setQuota(fakeInstanceQuota{Limit: -1, InUse: 100})
+
+		config.StrictQuota = true
+		Expect(deploy(5)).To(Succeed())
+	})
+
+	When("the quota would be exceeded", func() {
+		It("should warn, and deploy the gateways", func() {
+			Expect(deploy(3)).To(Succeed())
+			Expect(deployed.gateways).To(Equal([]int{3}))
+			Expect(status.warnings).To(ConsistOf(ContainSubstring("deploying 3 gateway(s) would exceed the instance quota")))
+		})
+
+		It("should fail in strict mode, without deploying the gateways", func() {
+			config.StrictQuota = true
+			Expect(deploy(3)).NotTo(Succeed())
+			Expect(deployed.gateways).To(BeEmpty())
+		})
+	})
+
+	It("should not check the quota of gateways which aren't dedicated instances", func() {
+		config.DedicatedGateway = false
+		config.StrictQuota = true
+		Expect(deploy(3)).To(Succeed())
+	})
+})
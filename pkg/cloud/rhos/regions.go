@@ -0,0 +1,439 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/identity/v3/tokens"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8serrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// RegionGateways is a region to process, with the number of gateways to deploy in it.
+type RegionGateways struct {
+	Region   string
+	Gateways int
+}
+
+func validateRegions(config *Config) error {
+	if config.Region != "" {
+		return errors.New("a single region and a list of regions can't both be specified")
+	}
+
+	seen := sets.New[string]()
+
+	for _, region := range config.Regions {
+		if region.Region == "" {
+			return errors.New("the region names can't be empty")
+		}
+
+		if regionLabelValue(region.Region) == "" {
+			return fmt.Errorf("the region name %q doesn't contain any letter or digit", region.Region)
+		}
+
+		if seen.Has(region.Region) {
+			return fmt.Errorf("region %q is specified more than once", region.Region)
+		}
+
+		seen.Insert(region.Region)
+	}
+
+	return nil
+}
+
+// runOnRegions runs runRegion on each of the configured regions, with the region's own configuration; in RunOn, it runs
+// the function as RunOn would on a single region, each region being authenticated independently. The regions are
+// processed in turn, or in parallel if MaxParallelRegions allows it. Unless ContinueOnError is set, the first failing
+// region stops the processing.
+func runOnRegions(config *Config, status reporter.Interface, runRegion func(*Config, reporter.Interface) error) error {
+	if config.MaxParallelRegions > 1 && len(config.Regions) > 1 {
+		return runOnRegionsInParallel(config, status, runRegion)
+	}
+
+	errs := []error{}
+	failed := []string{}
+
+	for _, region := range config.Regions {
+		status.Start("Processing RHOS region %q", region.Region)
+		status.End()
+
+		err := runRegion(regionConfig(config, region), status)
+		if err == nil {
+			continue
+		}
+
+		if !config.ContinueOnError {
+			return errors.Wrapf(err, "error processing region %q", region.Region)
+		}
+
+		status.Warning("Processing region %q failed, continuing with the remaining regions", region.Region)
+
+		errs = append(errs, errors.Wrapf(err, "region %q", region.Region))
+		failed = append(failed, region.Region)
+	}
+
+	if len(errs) > 0 {
+		return status.Error(k8serrors.NewAggregate(errs), "Processing failed in %d of %d regions (%s)", len(failed),
+			len(config.Regions), strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// runOnRegionsInParallel processes up to MaxParallelRegions regions at a time. Each region's output is recorded, and
+// replayed through the given reporter in one go once its processing is complete, so that the regions' outputs aren't
+// interleaved. A failure doesn't interrupt the regions already being processed, they run to completion; unless
+// ContinueOnError is set, the regions which weren't started yet are skipped. The outcome of every region is reported at
+// the end.
+func runOnRegionsInParallel(config *Config, status reporter.Interface, runRegion func(*Config, reporter.Interface) error) error {
+	var (
+		wg sync.WaitGroup
+		// mutex guards the output, results and failedAny
+		mutex     sync.Mutex
+		failedAny bool
+	)
+
+	results := make([]error, len(config.Regions))
+	skipped := make([]bool, len(config.Regions))
+	semaphore := make(chan struct{}, config.MaxParallelRegions)
+
+	for i := range config.Regions {
+		semaphore <- struct{}{}
+
+		mutex.Lock()
+		skipped[i] = failedAny && !config.ContinueOnError
+		mutex.Unlock()
+
+		if skipped[i] {
+			<-semaphore
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(i int) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			output := &regionRecorder{}
+
+			err := runRegion(regionConfig(config, config.Regions[i]), &reporter.Adapter{Basic: output})
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			results[i] = err
+			failedAny = failedAny || err != nil
+
+			status.Start("Processing RHOS region %q", config.Regions[i].Region)
+			status.End()
+			output.replay(status)
+		}(i)
+	}
+
+	wg.Wait()
+
+	return reportRegions(config, results, skipped, status)
+}
+
+// regionRecorder records the output of a region processed in parallel, to replay it once the region is processed.
+type regionRecorder struct {
+	events []func(reporter.Basic)
+}
+
+func (r *regionRecorder) record(report func(reporter.Basic, string), message string, args ...interface{}) {
+	// The message is formatted right away, the arguments may change by the time it's replayed
+	formatted := fmt.Sprintf(message, args...)
+
+	r.events = append(r.events, func(status reporter.Basic) {
+		report(status, formatted)
+	})
+}
+
+func (r *regionRecorder) Start(message string, args ...interface{}) {
+	r.record(func(status reporter.Basic, message string) { status.Start("%s", message) }, message, args...)
+}
+
+func (r *regionRecorder) Success(message string, args ...interface{}) {
+	r.record(func(status reporter.Basic, message string) { status.Success("%s", message) }, message, args...)
+}
+
+func (r *regionRecorder) Failure(message string, args ...interface{}) {
+	r.record(func(status reporter.Basic, message string) { status.Failure("%s", message) }, message, args...)
+}
+
+func (r *regionRecorder) Warning(message string, args ...interface{}) {
+	r.record(func(status reporter.Basic, message string) { status.Warning("%s", message) }, message, args...)
+}
+
+func (r *regionRecorder) End() {
+	r.events = append(r.events, reporter.Basic.End)
+}
+
+func (r *regionRecorder) replay(status reporter.Basic) {
+	for _, event := range r.events {
+		event(status)
+	}
+}
+
+// reportRegions reports the outcome of each region processed in parallel, and returns the aggregated errors.
+func reportRegions(config *Config, results []error, skipped []bool, status reporter.Interface) error {
+	status.Start("Summary of the %d RHOS regions", len(config.Regions))
+
+	errs := []error{}
+	failed := []string{}
+
+	for i, region := range config.Regions {
+		switch {
+		case skipped[i]:
+			status.Warning("Region %q wasn't processed, since another region failed", region.Region)
+		case results[i] != nil:
+			status.Failure("Processing region %q failed: %v", region.Region, results[i])
+
+			errs = append(errs, errors.Wrapf(results[i], "region %q", region.Region))
+			failed = append(failed, region.Region)
+		default:
+			status.Success("Processed region %q", region.Region)
+		}
+	}
+
+	status.End()
+
+	if len(errs) > 0 {
+		return status.Error(k8serrors.NewAggregate(errs), "Processing failed in %d of %d regions (%s)", len(failed),
+			len(config.Regions), strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// regionConfig returns the configuration to process the given region on its own.
+func regionConfig(config *Config, region RegionGateways) *Config {
+	regionConfig := *config
+	regionConfig.Region = region.Region
+	regionConfig.Gateways = region.Gateways
+	regionConfig.Regions = nil
+	regionConfig.multiRegion = true
+	// The metadata was already read
+	regionConfig.OcpMetadataFile = ""
+
+	return &regionConfig
+}
+
+const (
+	// regionLabel labels the gateway machine sets deployed in one of several regions with their region.
+	regionLabel     = "submariner.io/rhos-region"
+	machineSetLabel = "machine.openshift.io/cluster-api-machineset"
+)
+
+// regionLabelValue returns the region name in a form which can be used as a label value and in machine set names.
+func regionLabelValue(region string) string {
+	value := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+
+		return '-'
+	}, strings.ToLower(region))
+
+	return strings.Trim(value, "-")
+}
+
+// scopeToRegion scopes the gateway machine sets and nodes seen by the gateway deployers to the configured region, so that
+// each of several regions gets its own number of gateways, and only its own are cleaned up.
+func scopeToRegion(config *Config, msDeployer ocp.MachineSetDeployer, k8sClient k8s.Interface) (ocp.MachineSetDeployer, k8s.Interface) {
+	regional := &regionalMachineSetDeployer{
+		MachineSetDeployer: msDeployer,
+		infraID:            config.InfraID,
+		region:             regionLabelValue(config.Region),
+	}
+
+	return regional, &regionalK8sInterface{Interface: k8sClient, msDeployer: regional}
+}
+
+// regionalMachineSetDeployer names the gateway machine sets after their region, and labels them with it; only the region's
+// own machine sets are listed. Gateway machine sets deployed without regions aren't listed by any region.
+type regionalMachineSetDeployer struct {
+	ocp.MachineSetDeployer
+	infraID string
+	region  string
+}
+
+// Deploy deploys the machine set as the region's next gateway machine set, whatever its given name.
+func (d *regionalMachineSetDeployer) Deploy(machineSet *unstructured.Unstructured) error {
+	existing, err := d.List()
+	if err != nil {
+		return err
+	}
+
+	names := sets.New[string]()
+	for i := range existing {
+		names.Insert(existing[i].GetName())
+	}
+
+	name := ""
+
+	for index := 0; name == "" || names.Has(name); index++ {
+		name = fmt.Sprintf("%s-submariner-gw-%s-%d", d.infraID, d.region, index)
+	}
+
+	machineSet.SetName(name)
+
+	labels := machineSet.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	labels[regionLabel] = d.region
+	machineSet.SetLabels(labels)
+
+	for _, path := range [][]string{{"spec", "selector", "matchLabels"}, {"spec", "template", "metadata", "labels"}} {
+		if err := unstructured.SetNestedField(machineSet.Object, name, append(path, machineSetLabel)...); err != nil {
+			return errors.Wrapf(err, "error setting the %s label of the machine set", machineSetLabel)
+		}
+	}
+
+	return d.MachineSetDeployer.Deploy(machineSet) //nolint:wrapcheck // No need to wrap here
+}
+
+func (d *regionalMachineSetDeployer) List() ([]unstructured.Unstructured, error) {
+	all, err := d.MachineSetDeployer.List()
+	if err != nil {
+		return nil, err //nolint:wrapcheck // No need to wrap here
+	}
+
+	machineSets := []unstructured.Unstructured{}
+
+	for i := range all {
+		if all[i].GetLabels()[regionLabel] == d.region {
+			machineSets = append(machineSets, all[i])
+		}
+	}
+
+	return machineSets, nil
+}
+
+// regionalK8sInterface only lists the gateway nodes deployed by the region's machine sets.
+type regionalK8sInterface struct {
+	k8s.Interface
+	msDeployer ocp.MachineSetDeployer
+}
+
+func (i *regionalK8sInterface) ListGatewayNodes() (*corev1.NodeList, error) {
+	nodes, err := i.Interface.ListGatewayNodes()
+	if err != nil {
+		return nil, err //nolint:wrapcheck // No need to wrap here
+	}
+
+	machineSets, err := i.msDeployer.List()
+	if err != nil {
+		return nil, err //nolint:wrapcheck // No need to wrap here
+	}
+
+	regional := &corev1.NodeList{}
+
+	for j := range nodes.Items {
+		for k := range machineSets {
+			// The machines, and thus the nodes, are named after their machine set
+			if strings.HasPrefix(nodes.Items[j].Name, machineSets[k].GetName()+"-") {
+				regional.Items = append(regional.Items, nodes.Items[j])
+				break
+			}
+		}
+	}
+
+	return regional, nil
+}
+
+// regionGatewayCountDeployer deploys the region's own number of gateways, instead of the number it's given.
+type regionGatewayCountDeployer struct {
+	api.GatewayDeployer
+	gateways int
+}
+
+//nolint:gocritic // hugeParam: input - match the interface.
+func (d *regionGatewayCountDeployer) Deploy(input api.GatewayDeployInput, status reporter.Interface) error {
+	input.Gateways = d.gateways
+
+	return d.GatewayDeployer.Deploy(input, status) //nolint:wrapcheck // No need to wrap here
+}
+
+// withRegionGatewayCount applies the region's number of gateways to the deployer, when processing one of several regions.
+func withRegionGatewayCount(config *Config, deployer api.GatewayDeployer) api.GatewayDeployer {
+	if !config.multiRegion {
+		return deployer
+	}
+
+	return &regionGatewayCountDeployer{GatewayDeployer: deployer, gateways: config.Gateways}
+}
+
+// The catalog services whose region is used when none is specified.
+var regionalServiceTypes = sets.New("compute", "network")
+
+// regionFromCatalog derives the region from the compute and network endpoints in the authenticated client's service
+// catalog; it fails if the catalog doesn't list exactly one region for them.
+func regionFromCatalog(client *gophercloud.ProviderClient) (string, error) {
+	result, ok := client.GetAuthResult().(tokens.CreateResult)
+	if !ok {
+		return "", errors.New("the identity service didn't provide a service catalog")
+	}
+
+	catalog, err := result.ExtractServiceCatalog()
+	if err != nil {
+		return "", errors.Wrap(err, "error extracting the service catalog")
+	}
+
+	regions := sets.New[string]()
+
+	for i := range catalog.Entries {
+		if !regionalServiceTypes.Has(catalog.Entries[i].Type) {
+			continue
+		}
+
+		for _, endpoint := range catalog.Entries[i].Endpoints {
+			if endpoint.RegionID != "" {
+				regions.Insert(endpoint.RegionID)
+			} else if endpoint.Region != "" {
+				regions.Insert(endpoint.Region)
+			}
+		}
+	}
+
+	switch regions.Len() {
+	case 0:
+		return "", errors.New("the service catalog doesn't list any region for the compute and network services")
+	case 1:
+		return sets.List(regions)[0], nil
+	}
+
+	return "", fmt.Errorf("the service catalog lists several regions (%s)", strings.Join(sets.List(regions), ", "))
+}
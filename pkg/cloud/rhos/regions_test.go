@@ -0,0 +1,269 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos_test
+
+import (
+	"fmt"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"github.com/submariner-io/subctl/pkg/cloud/rhos"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const infraID = "test-infra"
+
+var _ = Describe("Gateways in several regions", func() {
+	var (
+		openStack  *fakeOpenStack
+		msDeployer *fakeMachineSetDeployer
+		k8sClient  k8s.Interface
+	)
+
+	BeforeEach(func() {
+		openStack = newFakeOpenStack()
+		msDeployer = newFakeMachineSetDeployer()
+		k8sClient = k8s.NewInterface(fake.NewSimpleClientset())
+	})
+
+	regionDeployer := func(region string, gateways int) api.GatewayDeployer {
+		return rhos.NewRegionGatewayDeployer(openStack.providerClient(), infraID,
+			rhos.RegionGateways{Region: region, Gateways: gateways}, msDeployer, k8sClient)
+	}
+
+	deploy := func(region string, gateways int) {
+		// The given number of gateways is the global one, each region deploys its own
+		Expect(regionDeployer(region, gateways).Deploy(api.GatewayDeployInput{Gateways: 1}, reporter.Silent())).To(Succeed())
+	}
+
+	When("each region is deployed", func() {
+		BeforeEach(func() {
+			deploy("RegionA", 1)
+			deploy("RegionB", 2)
+		})
+
+		It("should deploy each region's number of gateway machine sets, named after the region", func() {
+			Expect(msDeployer.names()).To(ConsistOf(infraID+"-submariner-gw-regiona-0", infraID+"-submariner-gw-regionb-0",
+				infraID+"-submariner-gw-regionb-1"))
+		})
+
+		It("should not deploy more gateways when deployed again", func() {
+			deploy("RegionA", 1)
+			deploy("RegionB", 2)
+
+			Expect(msDeployer.names()).To(HaveLen(3))
+		})
+
+		It("should deploy the additional gateways when a region's count increases", func() {
+			deploy("RegionA", 2)
+
+			Expect(msDeployer.names()).To(ContainElement(infraID + "-submariner-gw-regiona-1"))
+			Expect(msDeployer.names()).To(HaveLen(4))
+		})
+
+		It("should only clean up the region's own machine sets", func() {
+			Expect(regionDeployer("RegionA", 1).Cleanup(reporter.Silent())).To(Succeed())
+
+			Expect(msDeployer.names()).To(ConsistOf(infraID+"-submariner-gw-regionb-0", infraID+"-submariner-gw-regionb-1"))
+		})
+	})
+})
+
+var _ = Describe("Processing regions in parallel", func() {
+	var (
+		config *rhos.Config
+		status *fakeReporter
+	)
+
+	BeforeEach(func() {
+		config = &rhos.Config{
+			Regions:            []rhos.RegionGateways{{Region: "regionA", Gateways: 1}, {Region: "regionB", Gateways: 2}},
+			MaxParallelRegions: 2,
+		}
+		status = &fakeReporter{}
+	})
+
+	It("should replay each region's output through the reporter, without interleaving it", func() {
+		// Both regions start before either reports its outcome
+		var started sync.WaitGroup
+
+		started.Add(2)
+
+		Expect(rhos.RunOnRegions(config, status, func(config *rhos.Config, status reporter.Interface) error {
+			status.Start("Deploying %d gateway(s) in %s", config.Gateways, config.Region)
+			started.Done()
+			started.Wait()
+			status.Success("Deployed the gateway(s) in %s", config.Region)
+			status.Warning("Nothing else to do in %s", config.Region)
+			status.End()
+
+			return nil
+		})).To(Succeed())
+
+		for _, region := range config.Regions {
+			header := fmt.Sprintf("start: Processing RHOS region %q", region.Region)
+			Expect(status.events).To(ContainElement(header))
+
+			// The region's header is followed by its own output, in order
+			i := 0
+			for status.events[i] != header {
+				i++
+			}
+
+			Expect(status.events[i+1 : i+6]).To(Equal([]string{
+				"end",
+				fmt.Sprintf("start: Deploying %d gateway(s) in %s", region.Gateways, region.Region),
+				"success: Deployed the gateway(s) in " + region.Region,
+				"warning: Nothing else to do in " + region.Region,
+				"end",
+			}))
+		}
+
+		Expect(status.events[12:]).To(Equal([]string{
+			"start: Summary of the 2 RHOS regions",
+			`success: Processed region "regionA"`,
+			`success: Processed region "regionB"`,
+			"end",
+		}))
+	})
+})
+
+var _ = Describe("Processing regions", func() {
+	var (
+		config    *rhos.Config
+		status    *fakeReporter
+		processed []string
+		mutex     sync.Mutex
+	)
+
+	BeforeEach(func() {
+		config = &rhos.Config{
+			InfraID: infraID,
+			Regions: []rhos.RegionGateways{{Region: "regionA", Gateways: 1}, {Region: "regionB", Gateways: 2}, {Region: "regionC", Gateways: 3}},
+		}
+		status = &fakeReporter{}
+		processed = nil
+	})
+
+	run := func(failing ...string) error {
+		return rhos.RunOnRegions(config, status, func(config *rhos.Config, _ reporter.Interface) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			Expect(config.Regions).To(BeEmpty())
+			Expect(config.InfraID).To(Equal(infraID))
+
+			processed = append(processed, fmt.Sprintf("%s:%d", config.Region, config.Gateways))
+
+			for _, region := range failing {
+				if config.Region == region {
+					return fmt.Errorf("%s failed", region)
+				}
+			}
+
+			return nil
+		})
+	}
+
+	It("should process each region in turn, with its own configuration", func() {
+		Expect(run()).To(Succeed())
+		Expect(processed).To(Equal([]string{"regionA:1", "regionB:2", "regionC:3"}))
+	})
+
+	It("should stop at the first failing region", func() {
+		Expect(run("regionB")).To(MatchError(ContainSubstring(`error processing region "regionB"`)))
+		Expect(processed).To(Equal([]string{"regionA:1", "regionB:2"}))
+	})
+
+	When("continuing on error", func() {
+		BeforeEach(func() {
+			config.ContinueOnError = true
+		})
+
+		It("should process the remaining regions, and report the failed ones", func() {
+			err := run("regionA", "regionB")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("regionA failed"))
+			Expect(err.Error()).To(ContainSubstring("regionB failed"))
+			Expect(processed).To(Equal([]string{"regionA:1", "regionB:2", "regionC:3"}))
+			Expect(status.failures).To(ConsistOf(ContainSubstring("Processing failed in 2 of 3 regions (regionA, regionB)")))
+		})
+	})
+
+	When("processing the regions in parallel", func() {
+		BeforeEach(func() {
+			config.MaxParallelRegions = 2
+		})
+
+		It("should process all the regions", func() {
+			Expect(run()).To(Succeed())
+			Expect(processed).To(ConsistOf("regionA:1", "regionB:2", "regionC:3"))
+			Expect(status.successes).To(HaveLen(3))
+		})
+
+		It("should report each failed region, and fail", func() {
+			config.ContinueOnError = true
+
+			Expect(run("regionC")).NotTo(Succeed())
+			Expect(processed).To(HaveLen(3))
+			Expect(status.failures).To(ContainElement(ContainSubstring(`Processing region "regionC" failed: regionC failed`)))
+		})
+
+		It("should skip the regions which weren't started once a region fails", func() {
+			config.Regions = append(config.Regions, rhos.RegionGateways{Region: "regionD", Gateways: 1})
+
+			// regionB only completes once regionA's failure is reported, so the following regions start after it
+			reported := &signallingReporter{fakeReporter: status, start: `Processing RHOS region "regionA"`, signal: make(chan struct{})}
+
+			Expect(rhos.RunOnRegions(config, reported, func(config *rhos.Config, _ reporter.Interface) error {
+				switch config.Region {
+				case "regionA":
+					return fmt.Errorf("regionA failed")
+				case "regionB":
+					<-reported.signal
+				}
+
+				return nil
+			})).NotTo(Succeed())
+
+			Expect(status.warnings).To(ConsistOf(`Region "regionC" wasn't processed, since another region failed`,
+				`Region "regionD" wasn't processed, since another region failed`))
+			Expect(status.successes).To(ConsistOf(`Processed region "regionB"`))
+		})
+	})
+})
+
+// signallingReporter closes the signal channel when the given operation starts.
+type signallingReporter struct {
+	*fakeReporter
+	start  string
+	signal chan struct{}
+}
+
+func (r *signallingReporter) Start(message string, args ...interface{}) {
+	r.fakeReporter.Start(message, args...)
+
+	if fmt.Sprintf(message, args...) == r.start {
+		close(r.signal)
+	}
+}
@@ -20,9 +20,12 @@ limitations under the License.
 package rhos
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
+	"time"
 
-	"github.com/gophercloud/utils/openstack/clientconfig"
+	"github.com/pkg/errors"
 	"github.com/submariner-io/admiral/pkg/reporter"
 	"github.com/submariner-io/admiral/pkg/util"
 	"github.com/submariner-io/cloud-prepare/pkg/api"
@@ -36,12 +39,52 @@ import (
 type Config struct {
 	DedicatedGateway bool
 	Gateways         int
+	MaxGateways      int
+	StrictQuota      bool
 	InfraID          string
 	Region           string
-	ProjectID        string
-	OcpMetadataFile  string
-	CloudEntry       string
-	GWInstanceType   string
+	// Regions, when set instead of Region, are processed in turn, or in parallel up to MaxParallelRegions, each with its own
+	// number of gateways replacing Gateways.
+	Regions []RegionGateways
+	// ContinueOnError continues with the remaining regions when processing one of them fails.
+	ContinueOnError bool
+	// MaxParallelRegions, when greater than one, is the maximum number of regions processed in parallel.
+	MaxParallelRegions int
+	ProjectID          string
+	OcpMetadataFile    string
+	CloudEntry         string
+	GWInstanceType     string
+	AvailabilityZone   string
+	// SecurityGroupName, when set, is an existing security group which the gateway instances use instead of one created
+	// for them; only the rules it lacks for the gateway ports are added to it.
+	SecurityGroupName string
+	// PortsOnly, when set, only configures the gateway security group and its rules, and attaches it to the existing gateway
+	// nodes; no gateway instances are deployed nor worker nodes labeled, Gateways is ignored.
+	PortsOnly bool
+	// RootVolumeSizeGB, when set, boots the gateway instances from a root volume of this size instead of the flavor's disk.
+	RootVolumeSizeGB int
+	// ExternalNetwork, when set, is the name of the external network from which a floating IP is assigned to each dedicated
+	// gateway instance. Floating IPs assigned this way are released when cleaning up.
+	ExternalNetwork string
+	// HTTPProxy is the proxy used to reach the OpenStack API; by default, the standard proxy environment variables apply.
+	HTTPProxy string
+	// CACertFile is a PEM bundle of the CAs used to verify the OpenStack API endpoints.
+	CACertFile string
+	// InsecureSkipVerify disables the verification of the OpenStack API endpoints' certificates.
+	InsecureSkipVerify bool
+	// Preview, when set, only reports the changes which would be made to RHOS.
+	Preview bool
+	// Credentials, when set, are used to authenticate instead of the clouds.yaml.
+	Credentials Credentials
+	// Tags are applied, along with Submariner's own tag, to the security groups and gateway instances which are created.
+	// Only security groups carrying Submariner's tag are removed when cleaning up.
+	Tags map[string]string
+	// VerifyCleanup, when set, checks after running the function that the gateway instances and Submariner security groups
+	// are gone, waiting up to VerifyTimeout for their deletion.
+	VerifyCleanup bool
+	VerifyTimeout time.Duration
+	// multiRegion is set on the configuration of each of the Regions, whose gateways are then scoped to the region.
+	multiRegion bool
 }
 
 // RunOn runs the given function on RHOS, supplying it with a cloud instance connected to RHOS and a reporter that writes to CLI.
@@ -49,6 +92,12 @@ type Config struct {
 func RunOn(clusterInfo *cluster.Info, config *Config, status reporter.Interface,
 	function func(api.Cloud, api.GatewayDeployer, reporter.Interface) error,
 ) error {
+	if len(config.Regions) > 0 {
+		if err := validateRegions(config); err != nil {
+			return status.Error(err, "Invalid regions")
+		}
+	}
+
 	if config.OcpMetadataFile != "" {
 		var err error
 
@@ -60,27 +109,60 @@ func RunOn(clusterInfo *cluster.Info, config *Config, status reporter.Interface,
 		status.Success("Obtained infra ID %q and project ID %q from OCP metadata file %q", config.InfraID,
 			config.ProjectID, config.OcpMetadataFile)
 
-		config.Region = os.Getenv("OS_REGION_NAME")
+		if region := os.Getenv("OS_REGION_NAME"); len(config.Regions) == 0 && region != "" {
+			config.Region = region
+
+			status.Success("Obtained region %q from environment variable OS_REGION_NAME", config.Region)
+		} else if len(config.Regions) == 0 && config.Region != "" {
+			status.Success("Using the explicitly specified region %q", config.Region)
+		}
+	}
+
+	if len(config.Regions) > 0 {
+		return runOnRegions(config, status, func(config *Config, status reporter.Interface) error {
+			return RunOn(clusterInfo, config, status, function)
+		})
+	}
+
+	if err := validateRootVolumeSize(config); err != nil {
+		return status.Error(err, "Invalid root volume size")
+	}
 
-		status.Success("Obtained region %q from environment variable OS_REGION_NAME", config.Region)
+	if err := validateTags(config.Tags); err != nil {
+		return status.Error(err, "Invalid tags")
 	}
 
-	status.Start("Retrieving RHOS credentials from your RHOS configuration")
+	if config.Credentials.IsSet() {
+		status.Start("Using the explicitly specified RHOS credentials")
+	} else {
+		status.Start("Retrieving RHOS credentials from your RHOS configuration")
+	}
 
-	// Using RHOS default "openstack", if not specified
-	if config.CloudEntry == "" {
-		config.CloudEntry = "openstack"
+	if config.InsecureSkipVerify {
+		status.Warning("TLS certificate verification of the RHOS API is DISABLED; the connection is vulnerable to " +
+			"man-in-the-middle attacks and this must not be used in production")
 	}
 
-	opts := &clientconfig.ClientOpts{
-		Cloud: config.CloudEntry,
+	opts, err := clientOpts(config)
+	if err != nil {
+		return status.Error(err, "invalid RHOS credentials")
 	}
 
-	providerClient, err := clientconfig.AuthenticatedClient(opts)
+	providerClient, err := authenticatedClient(opts, config)
 	if err != nil {
 		return status.Error(err, "error initializing RHOS Client")
 	}
 
+	if config.Region == "" {
+		config.Region, err = regionFromCatalog(providerClient)
+		if err != nil {
+			return status.Error(err, "Unable to determine the RHOS region, set the OS_REGION_NAME environment variable "+
+				"or specify the region with --region")
+		}
+
+		status.Success("Obtained region %q from the RHOS service catalog", config.Region)
+	}
+
 	status.End()
 
 	clientSet := clusterInfo.ClientProducer.ForKubernetes()
@@ -92,6 +174,11 @@ func RunOn(clusterInfo *cluster.Info, config *Config, status reporter.Interface,
 	}
 
 	dynamicClient := clusterInfo.ClientProducer.ForDynamic()
+	msDeployer := ocp.NewK8sMachinesetDeployer(restMapper, dynamicClient)
+
+	if config.multiRegion {
+		msDeployer, k8sClientSet = scopeToRegion(config, msDeployer, k8sClientSet)
+	}
 
 	cloudInfo := rhos.CloudInfo{
 		Client:    providerClient,
@@ -100,22 +187,169 @@ func RunOn(clusterInfo *cluster.Info, config *Config, status reporter.Interface,
 		K8sClient: k8sClientSet,
 	}
 	rhosCloud := rhos.NewCloud(cloudInfo)
-	msDeployer := ocp.NewK8sMachinesetDeployer(restMapper, dynamicClient)
-	gwDeployer := rhos.NewOcpGatewayDeployer(cloudInfo, msDeployer, config.ProjectID, config.GWInstanceType,
-		"", config.CloudEntry, config.DedicatedGateway)
 
-	return function(rhosCloud, gwDeployer, status)
+	if config.AvailabilityZone != "" {
+		status.Start("Validating availability zone %q", config.AvailabilityZone)
+
+		err = validateAvailabilityZone(providerClient, config.Region, config.AvailabilityZone)
+		if err != nil {
+			return status.Error(err, "Invalid availability zone")
+		}
+
+		status.End()
+
+		msDeployer = &zonalMachineSetDeployer{MachineSetDeployer: msDeployer, availabilityZone: config.AvailabilityZone}
+	}
+
+	if config.RootVolumeSizeGB > 0 {
+		status.Start("Configuring the gateway root volumes")
+
+		rootVolumeDeployer, err := newRootVolumeMachineSetDeployer(providerClient, config, msDeployer)
+		if err != nil {
+			return status.Error(err, "error initializing the root volume configuration")
+		}
+
+		status.Success("The gateway instances will boot from a root volume of %d GB", config.RootVolumeSizeGB)
+		status.End()
+
+		msDeployer = rootVolumeDeployer
+	}
+
+	externalNetworkID := ""
+
+	if config.ExternalNetwork != "" {
+		status.Start("Validating external network %q", config.ExternalNetwork)
+
+		externalNetworkID, err = lookupExternalNetwork(providerClient, config.Region, config.ExternalNetwork)
+		if err != nil {
+			return status.Error(err, "Invalid external network")
+		}
+
+		status.Success("Selected external network %q (%s)", config.ExternalNetwork, externalNetworkID)
+		status.End()
+	}
+
+	if err := cloud.ReportGatewayNodes(clusterInfo, status); err != nil {
+		return err //nolint:wrapcheck // No need to wrap here
+	}
+
+	if config.Preview {
+		preview := &plan{}
+
+		// The existing resources are looked up so that the cleanup preview lists exactly those which would be deleted
+		resources, err := newInventory(providerClient, config, msDeployer)
+		if err != nil {
+			return status.Error(err, "error initializing the RHOS inventory")
+		}
+
+		err = function(&previewCloud{plan: preview, infraID: config.InfraID, inventory: resources},
+			withRegionGatewayCount(config, &quotaCheckingGatewayDeployer{
+				GatewayDeployer: &previewGatewayDeployer{plan: preview, config: config, inventory: resources},
+				client:          providerClient, config: config, msDeployer: msDeployer,
+			}), status)
+		if err != nil {
+			return err //nolint:wrapcheck // No need to wrap here
+		}
+
+		preview.report(status)
+
+		return nil
+	}
+
+	// The machine sets reference the cluster's own clouds.yaml, which uses the RHOS default entry
+	cloudEntry := config.CloudEntry
+	if cloudEntry == "" {
+		cloudEntry = "openstack"
+	}
+
+	tagger, err := newTagger(providerClient, config.Region, config.Tags)
+	if err != nil {
+		return status.Error(err, "error initializing the RHOS resource tagging")
+	}
+
+	msDeployer = &taggingMachineSetDeployer{MachineSetDeployer: msDeployer, tags: config.Tags}
+
+	keeper := &securityGroupKeepingGatewayDeployer{
+		GatewayDeployer: rhos.NewOcpGatewayDeployer(cloudInfo, msDeployer, config.ProjectID, config.GWInstanceType,
+			"", cloudEntry, config.DedicatedGateway),
+		msDeployer: msDeployer,
+		k8sClient:  k8sClientSet,
+	}
+
+	var gwDeployer api.GatewayDeployer = keeper
+
+	if config.SecurityGroupName != "" {
+		gwDeployer, err = newExistingSecurityGroupGatewayDeployer(cloudInfo, config, cloudEntry, msDeployer, gwDeployer)
+		if err != nil {
+			return status.Error(err, "error initializing the existing security group configuration")
+		}
+	}
+
+	if config.PortsOnly {
+		gwDeployer = &portsOnlyGatewayDeployer{GatewayDeployer: gwDeployer}
+	}
+
+	resources, err := newInventory(providerClient, config, msDeployer)
+	if err != nil {
+		return status.Error(err, "error initializing the RHOS inventory")
+	}
+
+	gwDeployer = &floatingIPGatewayDeployer{
+		GatewayDeployer: gwDeployer, inventory: resources, networkID: externalNetworkID, networkName: config.ExternalNetwork,
+	}
+
+	gwDeployer = &taggingGatewayDeployer{GatewayDeployer: gwDeployer, tagger: tagger, infraID: config.InfraID, keeper: keeper}
+
+	gwDeployer = &quotaCheckingGatewayDeployer{GatewayDeployer: gwDeployer, client: providerClient, config: config, msDeployer: msDeployer}
+
+	err = function(&taggingCloud{Cloud: rhosCloud, tagger: tagger, infraID: config.InfraID},
+		withRegionGatewayCount(config, gwDeployer), status)
+	if err != nil || !config.VerifyCleanup {
+		return err //nolint:wrapcheck // No need to wrap here
+	}
+
+	return verifyCleanup(resources, config, status)
 }
 
+// The sections of the OCP metadata files specific to the other cloud providers.
+var otherPlatforms = []string{"aws", "azure", "gcp", "ibmcloud", "powervs", "vsphere", "ovirt", "nutanix", "baremetal", "libvirt"}
+
+// readMetadataFile reads the infra and project IDs from the given OCP metadata file, failing if either is missing, or if
+// the file describes a cluster on another cloud provider.
 func readMetadataFile(fileName string) (string, string, error) {
 	var metadata struct {
 		InfraID string `json:"infraID"`
-		RHOS    struct {
+		RHOS    *struct {
 			ProjectID string `json:"projectID"`
 		} `json:"rhos"`
 	}
 
-	err := cloud.ReadMetadataFile(fileName, &metadata)
+	if err := cloud.ReadMetadataFile(fileName, &metadata); err != nil {
+		return "", "", err //nolint:wrapcheck // No need to wrap here
+	}
+
+	if metadata.RHOS == nil {
+		sections := map[string]json.RawMessage{}
+		if err := cloud.ReadMetadataFile(fileName, &sections); err != nil {
+			return "", "", err //nolint:wrapcheck // No need to wrap here
+		}
+
+		for _, platform := range otherPlatforms {
+			if _, found := sections[platform]; found {
+				return "", "", fmt.Errorf("the file is the metadata of a cluster on %q, not RHOS", platform)
+			}
+		}
+
+		return "", "", errors.New("the rhos section is missing")
+	}
+
+	if metadata.InfraID == "" {
+		return "", "", errors.New("the infraID field is missing or empty")
+	}
+
+	if metadata.RHOS.ProjectID == "" {
+		return "", "", errors.New("the rhos.projectID field is missing or empty")
+	}
 
-	return metadata.InfraID, metadata.RHOS.ProjectID, err //nolint:wrapcheck // No need to wrap here
+	return metadata.InfraID, metadata.RHOS.ProjectID, nil
 }
@@ -0,0 +1,396 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/secgroups"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/groups"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+	"github.com/submariner-io/cloud-prepare/pkg/rhos"
+	"github.com/submariner-io/subctl/internal/constants"
+	v1 "k8s.io/api/core/v1"
+)
+
+const ingressDirection = "ingress"
+
+// findSecurityGroup returns the single security group with the given name.
+func findSecurityGroup(networkClient *gophercloud.ServiceClient, name string) (*groups.SecGroup, error) {
+	pages, err := groups.List(networkClient, groups.ListOpts{Name: name}).AllPages()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing the security groups named %q", name)
+	}
+
+	found, err := groups.ExtractGroups(pages)
+	if err != nil {
+		return nil, errors.Wrap(err, "error extracting the security groups")
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("the security group %q doesn't exist", name)
+	case 1:
+		return &found[0], nil
+	default:
+		return nil, fmt.Errorf("there are %d security groups named %q, the group to use is ambiguous", len(found), name)
+	}
+}
+
+// missingIngressRules returns the ports which the given security group doesn't allow from all networks over IPv4.
+func missingIngressRules(networkClient *gophercloud.ServiceClient, group *groups.SecGroup, ports []api.PortSpec,
+) ([]api.PortSpec, error) {
+	pages, err := rules.List(networkClient, rules.ListOpts{SecGroupID: group.ID, Direction: ingressDirection}).AllPages()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing the rules of the security group %q", group.Name)
+	}
+
+	existing, err := rules.ExtractRules(pages)
+	if err != nil {
+		return nil, errors.Wrap(err, "error extracting the security group rules")
+	}
+
+	missing := []api.PortSpec{}
+
+	for _, port := range ports {
+		allowed := false
+
+		for i := range existing {
+			if ruleAllows(&existing[i], port) {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			missing = append(missing, port)
+		}
+	}
+
+	return missing, nil
+}
+
+// ruleAllows returns whether the given rule allows the port from all networks; rules restricted to a remote group or
+// prefix don't.
+func ruleAllows(rule *rules.SecGroupRule, port api.PortSpec) bool {
+	if rule.EtherType != string(rules.EtherType4) || rule.RemoteGroupID != "" {
+		return false
+	}
+
+	if rule.RemoteIPPrefix != "" && rule.RemoteIPPrefix != allNetworkCIDR {
+		return false
+	}
+
+	if rule.Protocol != "" && rule.Protocol != port.Protocol {
+		return false
+	}
+
+	// Rules without a port range allow all the ports
+	if rule.PortRangeMin == 0 && rule.PortRangeMax == 0 {
+		return true
+	}
+
+	return rule.PortRangeMin <= int(port.Port) && int(port.Port) <= rule.PortRangeMax
+}
+
+func formatRule(port api.PortSpec, groupName string) string {
+	return fmt.Sprintf("an ingress rule allowing %d/%s from %s to security group %q", port.Port, port.Protocol, allNetworkCIDR,
+		groupName)
+}
+
+// existingSecurityGroupGatewayDeployer deploys the gateways with an existing security group instead of creating one:
+// only the rules it lacks for the gateway ports are added, and it's attached to the gateway instances. When cleaning up,
+// the group is only detached from the gateway nodes; neither it nor the rules which were added to it are deleted.
+type existingSecurityGroupGatewayDeployer struct {
+	api.GatewayDeployer
+	cloudInfo     rhos.CloudInfo
+	config        *Config
+	cloudEntry    string
+	msDeployer    ocp.MachineSetDeployer
+	computeClient *gophercloud.ServiceClient
+	networkClient *gophercloud.ServiceClient
+}
+
+func newExistingSecurityGroupGatewayDeployer(cloudInfo rhos.CloudInfo, config *Config, cloudEntry string,
+	msDeployer ocp.MachineSetDeployer, deployer api.GatewayDeployer,
+) (*existingSecurityGroupGatewayDeployer, error) {
+	computeClient, err := openstack.NewComputeV2(cloudInfo.Client, gophercloud.EndpointOpts{Region: config.Region})
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating the compute client")
+	}
+
+	networkClient, err := openstack.NewNetworkV2(cloudInfo.Client, gophercloud.EndpointOpts{Region: config.Region})
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating the network client")
+	}
+
+	return &existingSecurityGroupGatewayDeployer{
+		GatewayDeployer: deployer,
+		cloudInfo:       cloudInfo,
+		config:          config,
+		cloudEntry:      cloudEntry,
+		msDeployer:      msDeployer,
+		computeClient:   computeClient,
+		networkClient:   networkClient,
+	}, nil
+}
+
+//nolint:gocritic // hugeParam: input - match the interface.
+func (d *existingSecurityGroupGatewayDeployer) Deploy(input api.GatewayDeployInput, status reporter.Interface) error {
+	groupName := d.config.SecurityGroupName
+
+	status.Start("Configuring the existing security group %q for inter-cluster traffic", groupName)
+	defer status.End()
+
+	group, err := findSecurityGroup(d.networkClient, groupName)
+	if err != nil {
+		return status.Error(err, "Unable to use the existing security group")
+	}
+
+	missing, err := missingIngressRules(d.networkClient, group, input.PublicPorts)
+	if err != nil {
+		return status.Error(err, "error checking the rules of the existing security group")
+	}
+
+	for _, port := range missing {
+		_, err := rules.Create(d.networkClient, rules.CreateOpts{
+			Direction:      ingressDirection,
+			EtherType:      rules.EtherType4,
+			SecGroupID:     group.ID,
+			PortRangeMin:   int(port.Port),
+			PortRangeMax:   int(port.Port),
+			Protocol:       rules.RuleProtocol(port.Protocol),
+			RemoteIPPrefix: allNetworkCIDR,
+		}).Extract()
+		if err != nil {
+			return status.Error(err, "error adding %s", formatRule(port, groupName))
+		}
+
+		status.Success("Added %s", formatRule(port, groupName))
+	}
+
+	if len(missing) == 0 {
+		status.Success("Security group %q already allows %s from %s", groupName, formatPorts(input.PublicPorts), allNetworkCIDR)
+	}
+
+	machineSets, err := d.msDeployer.List()
+	if err != nil {
+		return status.Error(err, "error getting the gateway machinesets")
+	}
+
+	gwNodes, err := d.cloudInfo.K8sClient.ListGatewayNodes()
+	if err != nil {
+		return status.Error(err, "listing the existing gateway nodes failed")
+	}
+
+	for i := range gwNodes.Items {
+		if err := d.attachSecurityGroup(gwNodes.Items[i].Name); err != nil {
+			return status.Error(err, "error attaching the security group to the existing gateway node")
+		}
+	}
+
+	existing := len(machineSets) + len(ocp.RemoveDuplicates(machineSets, gwNodes.Items))
+	if existing >= input.Gateways {
+		status.Success("Current Submariner gateways match the required number of Submariner gateways")
+		return nil
+	}
+
+	if d.config.DedicatedGateway {
+		return d.deployDedicatedGateways(len(machineSets), input.Gateways-existing, status)
+	}
+
+	return d.labelWorkerNodes(input.Gateways-existing, status)
+}
+
+func (d *existingSecurityGroupGatewayDeployer) deployDedicatedGateways(firstIndex, count int, status reporter.Interface) error {
+	msConfig := &gatewayMachineSetConfig{
+		InfraID:       d.config.InfraID,
+		InstanceType:  d.config.GWInstanceType,
+		CloudName:     d.cloudEntry,
+		SecurityGroup: d.config.SecurityGroupName,
+	}
+
+	for i := firstIndex; i < firstIndex+count; i++ {
+		msConfig.Index = strconv.Itoa(i)
+
+		machineSet, err := newGatewayMachineSet(msConfig)
+		if err != nil {
+			return status.Error(err, "error generating the gateway machine set")
+		}
+
+		if msConfig.Image == "" {
+			msConfig.Image, err = d.msDeployer.GetWorkerNodeImage(machineSet, d.config.InfraID)
+			if err != nil {
+				return status.Error(err, "error getting the worker image")
+			}
+
+			machineSet, err = newGatewayMachineSet(msConfig)
+			if err != nil {
+				return status.Error(err, "error generating the gateway machine set")
+			}
+		}
+
+		if err := d.msDeployer.Deploy(machineSet); err != nil {
+			return status.Error(err, "unable to deploy gateway %q", machineSet.GetName())
+		}
+
+		status.Success("Deployed dedicated Submariner gateway node %q with security group %q", machineSet.GetName(),
+			d.config.SecurityGroupName)
+	}
+
+	return nil
+}
+
+func (d *existingSecurityGroupGatewayDeployer) labelWorkerNodes(count int, status reporter.Interface) error {
+	workerNodes, err := d.cloudInfo.K8sClient.ListNodesWithLabel(workerNodeLabel)
+	if err != nil {
+		return status.Error(err, "failed to list the worker nodes")
+	}
+
+	for i := range workerNodes.Items {
+		if count == 0 {
+			return nil
+		}
+
+		node := &workerNodes.Items[i]
+		if node.Labels[constants.SubmarinerGatewayLabel] == constants.TrueLabel {
+			continue
+		}
+
+		if err := d.cloudInfo.K8sClient.AddGWLabelOnNode(node.Name); err != nil {
+			return status.Error(err, "failed to label the node %q as Submariner gateway node", node.Name)
+		}
+
+		if err := d.attachSecurityGroup(node.Name); err != nil {
+			return status.Error(err, "error attaching the security group to the gateway node")
+		}
+
+		status.Success("Configured worker node %q as Submariner gateway node with security group %q", node.Name,
+			d.config.SecurityGroupName)
+
+		count--
+	}
+
+	if count > 0 {
+		return status.Error(fmt.Errorf("%d more gateway node(s) are needed", count),
+			"there are insufficient nodes to deploy the required number of gateways")
+	}
+
+	return nil
+}
+
+// attachSecurityGroup adds the security group to the instances backing the given node, unless they already have it.
+func (d *existingSecurityGroupGatewayDeployer) attachSecurityGroup(nodeName string) error {
+	instances, err := d.instancesOfNode(nodeName)
+	if err != nil {
+		return err
+	}
+
+	for i := range instances {
+		if instanceHasSecurityGroup(&instances[i], d.config.SecurityGroupName) {
+			continue
+		}
+
+		err := secgroups.AddServer(d.computeClient, instances[i].ID, d.config.SecurityGroupName).ExtractErr()
+		if err != nil {
+			return errors.Wrapf(err, "error adding security group %q to instance %q", d.config.SecurityGroupName, instances[i].Name)
+		}
+	}
+
+	return nil
+}
+
+func (d *existingSecurityGroupGatewayDeployer) instancesOfNode(nodeName string) ([]servers.Server, error) {
+	pages, err := servers.List(d.computeClient, servers.ListOpts{Name: nodeName}).AllPages()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing the instances of node %q", nodeName)
+	}
+
+	instances, err := servers.ExtractServers(pages)
+
+	return instances, errors.Wrap(err, "error extracting the instances")
+}
+
+func instanceHasSecurityGroup(instance *servers.Server, groupName string) bool {
+	for _, group := range instance.SecurityGroups {
+		if group["name"] == groupName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Cleanup detaches the existing security group from the labeled worker nodes, whose instances outlive the cleanup, then
+// lets cloud-prepare remove the gateways.
+func (d *existingSecurityGroupGatewayDeployer) Cleanup(status reporter.Interface) error {
+	status.Start("Detaching the existing security group %q from the Submariner gateway nodes", d.config.SecurityGroupName)
+
+	machineSets, err := d.msDeployer.List()
+	if err != nil {
+		return status.Error(err, "error listing the Submariner gateway machine sets")
+	}
+
+	gwNodes, err := d.cloudInfo.K8sClient.ListGatewayNodes()
+	if err != nil {
+		return status.Error(err, "error listing the Submariner gateway nodes")
+	}
+
+	labeledNodes := ocp.RemoveDuplicates(machineSets, gwNodes.Items)
+	for i := range labeledNodes {
+		if err := d.detachSecurityGroup(&labeledNodes[i]); err != nil {
+			return status.Error(err, "error detaching the security group")
+		}
+
+		status.Success("Detached security group %q from node %q", d.config.SecurityGroupName, labeledNodes[i].Name)
+	}
+
+	status.End()
+
+	return d.GatewayDeployer.Cleanup(status) //nolint:wrapcheck // No need to wrap here
+}
+
+func (d *existingSecurityGroupGatewayDeployer) detachSecurityGroup(node *v1.Node) error {
+	instances, err := d.instancesOfNode(node.Name)
+	if err != nil {
+		return err
+	}
+
+	for i := range instances {
+		if !instanceHasSecurityGroup(&instances[i], d.config.SecurityGroupName) {
+			continue
+		}
+
+		err := secgroups.RemoveServer(d.computeClient, instances[i].ID, d.config.SecurityGroupName).ExtractErr()
+		if err != nil {
+			return errors.Wrapf(err, "error removing security group %q from instance %q", d.config.SecurityGroupName,
+				instances[i].Name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,317 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/attributestags"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/groups"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// submarinerTag marks the RHOS resources created by subctl; only resources carrying it are cleaned up.
+	submarinerTag = "submariner-managed"
+	// Neutron limits the length of tags, which also can't contain commas or slashes.
+	maxTagLength          = 60
+	securityGroupResource = "security-groups"
+)
+
+// resourceTags returns the tags applied to the created resources: Submariner's own, and the given tags as key=value.
+func resourceTags(tags map[string]string) []string {
+	result := sets.New(submarinerTag)
+
+	for key, value := range tags {
+		result.Insert(key + "=" + value)
+	}
+
+	return sets.List(result)
+}
+
+func validateTags(tags map[string]string) error {
+	for _, tag := range resourceTags(tags) {
+		if len(tag) > maxTagLength {
+			return fmt.Errorf("the tag %q is longer than %d characters", tag, maxTagLength)
+		}
+
+		if strings.ContainsAny(tag, ",/") {
+			return fmt.Errorf("the tag %q can't contain commas or slashes", tag)
+		}
+	}
+
+	return nil
+}
+
+// tagger tags the security groups created by cloud-prepare, and ensures those being deleted were created by subctl.
+type tagger struct {
+	networkClient *gophercloud.ServiceClient
+	tags          []string
+}
+
+func newTagger(client *gophercloud.ProviderClient, region string, tags map[string]string) (*tagger, error) {
+	networkClient, err := openstack.NewNetworkV2(client, gophercloud.EndpointOpts{Region: region})
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating the network client")
+	}
+
+	return &tagger{networkClient: networkClient, tags: resourceTags(tags)}, nil
+}
+
+func (t *tagger) findSecurityGroups(name string) ([]groups.SecGroup, error) {
+	pages, err := groups.List(t.networkClient, groups.ListOpts{Name: name}).AllPages()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing the security groups named %q", name)
+	}
+
+	found, err := groups.ExtractGroups(pages)
+
+	return found, errors.Wrap(err, "error extracting the security groups")
+}
+
+func (t *tagger) tagSecurityGroup(name string, status reporter.Interface) error {
+	found, err := t.findSecurityGroups(name)
+	if err != nil {
+		return err
+	}
+
+	for i := range found {
+		tags := sets.List(sets.New(found[i].Tags...).Insert(t.tags...))
+
+		_, err := attributestags.ReplaceAll(t.networkClient, securityGroupResource, found[i].ID,
+			attributestags.ReplaceAllOpts{Tags: tags}).Extract()
+		if err != nil {
+			return errors.Wrapf(err, "error tagging the security group %q (%s)", name, found[i].ID)
+		}
+
+		status.Success("Tagged security group %q with %s", name, strings.Join(t.tags, ", "))
+	}
+
+	return nil
+}
+
+// The descriptions cloud-prepare gives the security groups it creates, by name suffix.
+var cloudPrepareDescriptions = map[string]string{
+	gwSecurityGroupSuffix:       "Submariner Gateway",
+	internalSecurityGroupSuffix: "Submariner Internal",
+}
+
+// securityGroupCheck sorts the security groups with one of the names cloud-prepare deletes by, according to who created
+// them. Groups carrying Submariner's tag are owned; untagged groups with cloud-prepare's description were created before
+// subctl tagged them, and are owned too. The others may have been created by something else: the cleanup doesn't delete
+// the security group when there are any.
+type securityGroupCheck struct {
+	name    string
+	owned   []groups.SecGroup
+	legacy  []groups.SecGroup
+	foreign []groups.SecGroup
+}
+
+func (t *tagger) checkSecurityGroups(infraID, suffix string) (*securityGroupCheck, error) {
+	check := &securityGroupCheck{name: infraID + suffix}
+
+	found, err := t.findSecurityGroups(check.name)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range found {
+		switch {
+		case sets.New(found[i].Tags...).Has(submarinerTag):
+			check.owned = append(check.owned, found[i])
+		case found[i].Description == cloudPrepareDescriptions[suffix]:
+			check.legacy = append(check.legacy, found[i])
+		default:
+			check.foreign = append(check.foreign, found[i])
+		}
+	}
+
+	return check, nil
+}
+
+func (c *securityGroupCheck) deletable() bool {
+	return len(c.foreign) == 0
+}
+
+// warn warns about the untagged groups which are deleted nonetheless, or about those which aren't deleted.
+func (c *securityGroupCheck) warn(status reporter.Interface) {
+	if !c.deletable() {
+		for i := range c.foreign {
+			status.Warning("Not deleting security group %q (%s), which isn't tagged %q and may not have been created by "+
+				"subctl; delete it manually if it's no longer needed", c.name, c.foreign[i].ID, submarinerTag)
+		}
+
+		return
+	}
+
+	for i := range c.legacy {
+		status.Warning("Security group %q (%s) isn't tagged %q, it was created by an earlier version and is deleted",
+			c.name, c.legacy[i].ID, submarinerTag)
+	}
+}
+
+// taggingCloud tags the internal security group after opening the ports, and checks it before closing them.
+type taggingCloud struct {
+	api.Cloud
+	tagger  *tagger
+	infraID string
+}
+
+func (c *taggingCloud) OpenPorts(ports []api.PortSpec, status reporter.Interface) error {
+	if err := c.Cloud.OpenPorts(ports, status); err != nil {
+		return err //nolint:wrapcheck // No need to wrap here
+	}
+
+	return c.tagger.tagSecurityGroup(c.infraID+internalSecurityGroupSuffix, status)
+}
+
+// ClosePorts leaves the ports open when the internal security group may not have been created by subctl, since closing
+// them deletes it.
+func (c *taggingCloud) ClosePorts(status reporter.Interface) error {
+	check, err := c.tagger.checkSecurityGroups(c.infraID, internalSecurityGroupSuffix)
+	if err != nil {
+		return status.Error(err, "Error checking the internal security group")
+	}
+
+	check.warn(status)
+
+	if !check.deletable() {
+		return nil
+	}
+
+	return c.Cloud.ClosePorts(status) //nolint:wrapcheck // No need to wrap here
+}
+
+// taggingGatewayDeployer tags the gateway security group after deploying, and checks it before cleaning up: when it may
+// not have been created by subctl, the gateways are removed but the group is kept.
+type taggingGatewayDeployer struct {
+	api.GatewayDeployer
+	tagger  *tagger
+	infraID string
+	keeper  *securityGroupKeepingGatewayDeployer
+}
+
+//nolint:gocritic // hugeParam: input - match the interface.
+func (d *taggingGatewayDeployer) Deploy(input api.GatewayDeployInput, status reporter.Interface) error {
+	if err := d.GatewayDeployer.Deploy(input, status); err != nil {
+		return err //nolint:wrapcheck // No need to wrap here
+	}
+
+	return d.tagger.tagSecurityGroup(d.infraID+gwSecurityGroupSuffix, status)
+}
+
+func (d *taggingGatewayDeployer) Cleanup(status reporter.Interface) error {
+	check, err := d.tagger.checkSecurityGroups(d.infraID, gwSecurityGroupSuffix)
+	if err != nil {
+		return status.Error(err, "Error checking the gateway security group")
+	}
+
+	check.warn(status)
+	d.keeper.keepSecurityGroup = !check.deletable()
+
+	return d.GatewayDeployer.Cleanup(status) //nolint:wrapcheck // No need to wrap here
+}
+
+// securityGroupKeepingGatewayDeployer wraps cloud-prepare's gateway deployer, whose cleanup deletes the gateway security
+// group along with the gateways. When the group is to be kept, the gateway machine sets are deleted and the gateway nodes
+// unlabeled as cloud-prepare would, leaving the group untouched.
+type securityGroupKeepingGatewayDeployer struct {
+	api.GatewayDeployer
+	msDeployer        ocp.MachineSetDeployer
+	k8sClient         k8s.Interface
+	keepSecurityGroup bool
+}
+
+func (d *securityGroupKeepingGatewayDeployer) Cleanup(status reporter.Interface) error {
+	if !d.keepSecurityGroup {
+		return d.GatewayDeployer.Cleanup(status) //nolint:wrapcheck // No need to wrap here
+	}
+
+	status.Start("Removing the Submariner gateways, keeping the gateway security group")
+	defer status.End()
+
+	machineSets, err := d.msDeployer.List()
+	if err != nil {
+		return status.Error(err, "error listing the Submariner gateway machine sets")
+	}
+
+	gwNodes, err := d.k8sClient.ListGatewayNodes()
+	if err != nil {
+		return status.Error(err, "error listing the Submariner gateway nodes")
+	}
+
+	for i := range machineSets {
+		if err := d.msDeployer.DeleteByName(machineSets[i].GetName(), machineSets[i].GetNamespace()); err != nil {
+			return status.Error(err, "error deleting the gateway machine set %q", machineSets[i].GetName())
+		}
+
+		status.Success("Deleted the gateway machine set %q", machineSets[i].GetName())
+	}
+
+	labeledNodes := ocp.RemoveDuplicates(machineSets, gwNodes.Items)
+	for i := range labeledNodes {
+		if err := d.k8sClient.RemoveGWLabelFromWorkerNode(&labeledNodes[i]); err != nil {
+			return status.Error(err, "error removing the Submariner gateway label from node %q", labeledNodes[i].Name)
+		}
+
+		status.Success("Removed the Submariner gateway label from node %q", labeledNodes[i].Name)
+	}
+
+	return nil
+}
+
+// taggingMachineSetDeployer adds the tags to the gateway instances' metadata.
+type taggingMachineSetDeployer struct {
+	ocp.MachineSetDeployer
+	tags map[string]string
+}
+
+func (d *taggingMachineSetDeployer) Deploy(machineSet *unstructured.Unstructured) error {
+	path := []string{"spec", "template", "spec", "providerSpec", "value", "serverMetadata"}
+
+	metadata, _, err := unstructured.NestedStringMap(machineSet.Object, path...)
+	if err != nil {
+		return errors.Wrap(err, "error reading the server metadata of the machine set")
+	}
+
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	for key, value := range d.tags {
+		metadata[key] = value
+	}
+
+	metadata[submarinerTag] = "true"
+
+	if err := unstructured.SetNestedStringMap(machineSet.Object, metadata, path...); err != nil {
+		return errors.Wrap(err, "error setting the server metadata on the machine set")
+	}
+
+	return d.MachineSetDeployer.Deploy(machineSet) //nolint:wrapcheck // No need to wrap here
+}
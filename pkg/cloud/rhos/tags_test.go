@@ -0,0 +1,113 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"github.com/submariner-io/subctl/pkg/cloud/rhos"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	gwSecurityGroup       = infraID + "-submariner-gw-sg"
+	internalSecurityGroup = infraID + "-submariner-internal-sg"
+)
+
+var _ = Describe("Security group ownership", func() {
+	var (
+		openStack  *fakeOpenStack
+		msDeployer *fakeMachineSetDeployer
+		cloud      api.Cloud
+		gwDeployer api.GatewayDeployer
+		status     *fakeReporter
+	)
+
+	BeforeEach(func() {
+		openStack = newFakeOpenStack()
+		msDeployer = newFakeMachineSetDeployer()
+		status = &fakeReporter{}
+		cloud, gwDeployer = rhos.NewTaggingCloud(openStack.providerClient(), infraID, "regionOne", msDeployer,
+			k8s.NewInterface(fake.NewSimpleClientset()))
+	})
+
+	addGatewayMachineSet := func() {
+		machineSet, err := rhos.NewGatewayMachineSet(infraID, gwSecurityGroup)
+		Expect(err).To(Succeed())
+		Expect(msDeployer.Deploy(machineSet)).To(Succeed())
+	}
+
+	When("the security groups were created and tagged by subctl", func() {
+		BeforeEach(func() {
+			Expect(gwDeployer.Deploy(api.GatewayDeployInput{Gateways: 1}, status)).To(Succeed())
+			Expect(cloud.OpenPorts([]api.PortSpec{}, status)).To(Succeed())
+		})
+
+		It("should tag them", func() {
+			Expect(openStack.securityGroupTags(gwSecurityGroup)).To(ContainElement("submariner-managed"))
+			Expect(openStack.securityGroupTags(internalSecurityGroup)).To(ContainElement("submariner-managed"))
+		})
+
+		It("should delete them along with the gateways", func() {
+			Expect(gwDeployer.Cleanup(status)).To(Succeed())
+			Expect(cloud.ClosePorts(status)).To(Succeed())
+
+			Expect(msDeployer.names()).To(BeEmpty())
+			Expect(openStack.securityGroupNames()).To(BeEmpty())
+			Expect(status.warnings).To(BeEmpty())
+		})
+	})
+
+	When("the security groups were created by cloud-prepare before they were tagged", func() {
+		BeforeEach(func() {
+			openStack.addSecurityGroup(gwSecurityGroup, "Submariner Gateway")
+			openStack.addSecurityGroup(internalSecurityGroup, "Submariner Internal")
+			addGatewayMachineSet()
+		})
+
+		It("should delete them along with the gateways, with a warning", func() {
+			Expect(gwDeployer.Cleanup(status)).To(Succeed())
+			Expect(cloud.ClosePorts(status)).To(Succeed())
+
+			Expect(msDeployer.names()).To(BeEmpty())
+			Expect(openStack.securityGroupNames()).To(BeEmpty())
+			Expect(status.warnings).To(HaveLen(2))
+		})
+	})
+
+	When("the security groups may not have been created by subctl", func() {
+		BeforeEach(func() {
+			openStack.addSecurityGroup(gwSecurityGroup, "Someone else's gateway group")
+			openStack.addSecurityGroup(internalSecurityGroup, "Someone else's internal group")
+			addGatewayMachineSet()
+		})
+
+		It("should delete the gateways but keep the security groups, with a warning", func() {
+			Expect(gwDeployer.Cleanup(status)).To(Succeed())
+			Expect(cloud.ClosePorts(status)).To(Succeed())
+
+			Expect(msDeployer.names()).To(BeEmpty())
+			Expect(openStack.securityGroupNames()).To(ConsistOf(gwSecurityGroup, internalSecurityGroup))
+			Expect(status.warnings).To(ConsistOf(ContainSubstring("Not deleting security group %q", gwSecurityGroup),
+				ContainSubstring("Not deleting security group %q", internalSecurityGroup)))
+		})
+	})
+})
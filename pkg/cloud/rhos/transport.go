@@ -0,0 +1,127 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/utils/openstack/clientconfig"
+	"github.com/pkg/errors"
+)
+
+// httpClient returns an HTTP client which reaches the OpenStack API through the configured proxy, if any, and verifies
+// it using the configured CA bundle, if any.
+func httpClient(config *Config) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if config.HTTPProxy != "" {
+		proxyURL, err := parseProxy(config.HTTPProxy)
+		if err != nil {
+			return nil, err
+		}
+
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: config.InsecureSkipVerify, //nolint:gosec // Only when explicitly requested
+	}
+
+	if config.CACertFile != "" {
+		pool, err := loadCABundle(config.CACertFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{Transport: transport}, nil
+}
+
+func loadCABundle(fileName string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading the CA bundle %q", fileName)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.Errorf("no PEM certificates found in the CA bundle %q", fileName)
+	}
+
+	return pool, nil
+}
+
+func parseProxy(proxy string) (*url.URL, error) {
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid proxy URL %q", proxy)
+	}
+
+	if (proxyURL.Scheme != "http" && proxyURL.Scheme != "https") || proxyURL.Host == "" {
+		return nil, errors.Errorf("invalid proxy URL %q, expected http(s)://host[:port]", proxy)
+	}
+
+	return proxyURL, nil
+}
+
+// authenticatedClient authenticates with RHOS, distinguishing failures to reach the proxy from failures to reach
+// the OpenStack endpoint. Without an explicit proxy or TLS settings, the default client honors the standard proxy
+// environment variables and the clouds.yaml TLS settings.
+func authenticatedClient(opts *clientconfig.ClientOpts, config *Config) (*gophercloud.ProviderClient, error) {
+	if config.HTTPProxy != "" || config.CACertFile != "" || config.InsecureSkipVerify {
+		client, err := httpClient(config)
+		if err != nil {
+			return nil, err
+		}
+
+		opts.HTTPClient = client
+	}
+
+	providerClient, err := clientconfig.AuthenticatedClient(opts)
+	if err == nil {
+		return providerClient, nil
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "proxyconnect" {
+		if config.HTTPProxy != "" {
+			return nil, errors.Wrapf(err, "unable to reach the proxy %q", config.HTTPProxy)
+		}
+
+		return nil, errors.Wrap(err, "unable to reach the proxy configured in the environment")
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return nil, errors.Wrapf(err, "unable to reach the OpenStack endpoint %q", urlErr.URL)
+	}
+
+	return nil, err //nolint:wrapcheck // No need to wrap here
+}
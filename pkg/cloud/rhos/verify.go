@@ -0,0 +1,191 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/floatingips"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/groups"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const verifyInterval = 5 * time.Second
+
+// verifyCleanup checks that the gateway instances and the security groups which the cleanup deletes are gone, waiting up to
+// the configured timeout for asynchronous deletions. The remaining resources are reported, along with the ports and
+// floating IPs which may be keeping the security groups from being deleted.
+func verifyCleanup(resources *inventory, config *Config, status reporter.Interface) error {
+	status.Start("Verifying that the RHOS resources were deleted")
+	defer status.End()
+
+	var remaining []string
+
+	check := func() (bool, error) {
+		var err error
+
+		remaining, err = resources.remainingResources(config.ProjectID)
+
+		return len(remaining) == 0, err
+	}
+
+	var err error
+
+	if config.VerifyTimeout > 0 {
+		err = wait.PollImmediate(verifyInterval, config.VerifyTimeout, check)
+		if errors.Is(err, wait.ErrWaitTimeout) {
+			err = nil
+		}
+	} else {
+		_, err = check()
+	}
+
+	if err != nil {
+		return status.Error(err, "Error looking up the remaining RHOS resources")
+	}
+
+	if len(remaining) == 0 {
+		status.Success("No Submariner resources remain")
+		return nil
+	}
+
+	for _, resource := range remaining {
+		status.Failure("Remaining: %s", resource)
+	}
+
+	return status.Error(fmt.Errorf("%d resource(s) remain", len(remaining)), "Cleanup left RHOS resources behind")
+}
+
+// remainingResources describes the gateway instances, their floating IPs and the Submariner security groups which still exist.
+func (i *inventory) remainingResources(projectID string) ([]string, error) {
+	remaining, err := i.remainingGatewayInstances()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, suffix := range []string{gwSecurityGroupSuffix, internalSecurityGroupSuffix} {
+		check, err := i.tagger.checkSecurityGroups(i.infraID, suffix)
+		if err != nil {
+			return nil, err
+		}
+
+		// The groups which may not have been created by subctl are deliberately kept by the cleanup
+		if !check.deletable() {
+			continue
+		}
+
+		found := append(append([]groups.SecGroup{}, check.owned...), check.legacy...)
+
+		for j := range found {
+			remaining = append(remaining, fmt.Sprintf("security group %q (%s)", check.name, found[j].ID))
+
+			users, err := i.securityGroupUsers(projectID, found[j].ID)
+			if err != nil {
+				return nil, err
+			}
+
+			remaining = append(remaining, users...)
+		}
+	}
+
+	return remaining, nil
+}
+
+func (i *inventory) remainingGatewayInstances() ([]string, error) {
+	found, err := i.gatewayInstances()
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := []string{}
+
+	for j := range found {
+		remaining = append(remaining, fmt.Sprintf("gateway instance %q (%s), in state %s", found[j].Name, found[j].ID, found[j].Status))
+	}
+
+	ips, err := i.gatewayFloatingIPs()
+	if err != nil {
+		return nil, err
+	}
+
+	for j := range ips {
+		remaining = append(remaining, fmt.Sprintf("gateway floating IP %s (%s)", ips[j].FloatingIP, ips[j].ID))
+	}
+
+	return remaining, nil
+}
+
+// securityGroupUsers describes the ports using the given security group, and the floating IPs associated with them.
+func (i *inventory) securityGroupUsers(projectID, groupID string) ([]string, error) {
+	pages, err := ports.List(i.tagger.networkClient, ports.ListOpts{ProjectID: projectID}).AllPages()
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the ports")
+	}
+
+	found, err := ports.ExtractPorts(pages)
+	if err != nil {
+		return nil, errors.Wrap(err, "error extracting the ports")
+	}
+
+	users := []string{}
+
+	for j := range found {
+		if !sets.New(found[j].SecurityGroups...).Has(groupID) {
+			continue
+		}
+
+		users = append(users, fmt.Sprintf("port %q (%s) of device %q, using security group %s",
+			found[j].Name, found[j].ID, found[j].DeviceID, groupID))
+
+		ips, err := i.floatingIPsOf(found[j].ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ip := range ips {
+			users = append(users, fmt.Sprintf("floating IP %s, associated with port %s", ip, found[j].ID))
+		}
+	}
+
+	return users, nil
+}
+
+func (i *inventory) floatingIPsOf(portID string) ([]string, error) {
+	pages, err := floatingips.List(i.tagger.networkClient, floatingips.ListOpts{PortID: portID}).AllPages()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing the floating IPs of port %s", portID)
+	}
+
+	found, err := floatingips.ExtractFloatingIPs(pages)
+	if err != nil {
+		return nil, errors.Wrap(err, "error extracting the floating IPs")
+	}
+
+	ips := make([]string, len(found))
+	for j := range found {
+		ips[j] = found[j].FloatingIP
+	}
+
+	return ips, nil
+}
@@ -0,0 +1,96 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/pkg/cloud/rhos"
+)
+
+var _ = Describe("Cleanup verification", func() {
+	var (
+		openStack *fakeOpenStack
+		config    *rhos.Config
+		status    *fakeReporter
+	)
+
+	BeforeEach(func() {
+		openStack = newFakeOpenStack()
+		config = &rhos.Config{InfraID: infraID, Region: "regionOne", ProjectID: "project"}
+		status = &fakeReporter{}
+	})
+
+	verifyCleanup := func() error {
+		return rhos.VerifyCleanup(openStack.providerClient(), config, newFakeMachineSetDeployer(), status)
+	}
+
+	When("no Submariner resources remain", func() {
+		BeforeEach(func() {
+			openStack.addServer(infraID+"-worker-0", map[string]string{})
+		})
+
+		It("should report success", func() {
+			Expect(verifyCleanup()).To(Succeed())
+			Expect(status.successes).To(ConsistOf("No Submariner resources remain"))
+			Expect(status.failures).To(BeEmpty())
+		})
+	})
+
+	When("Submariner resources remain", func() {
+		BeforeEach(func() {
+			openStack.addSecurityGroup(gwSecurityGroup, "Submariner Gateway", "submariner-managed")
+			openStack.addServer(infraID+"-submariner-gw-0", map[string]string{"submariner-managed": ""})
+			openStack.addFloatingIP("10.0.0.1", "Submariner gateway floating IP for "+infraID, "submariner-managed")
+		})
+
+		It("should report them as failures", func() {
+			Expect(verifyCleanup()).NotTo(Succeed())
+			Expect(status.failures).To(Equal([]string{
+				`Remaining: gateway instance "` + infraID + `-submariner-gw-0" (id-2), in state ACTIVE`,
+				"Remaining: gateway floating IP 10.0.0.1 (id-3)",
+				`Remaining: security group "` + gwSecurityGroup + `" (id-1)`,
+				"Cleanup left RHOS resources behind: 3 resource(s) remain",
+			}))
+			Expect(status.successes).To(BeEmpty())
+		})
+	})
+
+	When("a security group wasn't created by subctl", func() {
+		BeforeEach(func() {
+			openStack.addSecurityGroup(gwSecurityGroup, "Created by hand")
+		})
+
+		It("should not report it", func() {
+			Expect(verifyCleanup()).To(Succeed())
+			Expect(status.successes).To(ConsistOf("No Submariner resources remain"))
+		})
+	})
+
+	When("the resources can't be looked up", func() {
+		BeforeEach(func() {
+			openStack.failRegion(config.Region)
+		})
+
+		It("should return an error", func() {
+			Expect(verifyCleanup()).NotTo(Succeed())
+			Expect(status.successes).To(BeEmpty())
+		})
+	})
+})
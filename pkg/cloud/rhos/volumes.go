@@ -0,0 +1,113 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos
+
+import (
+	"fmt"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/imageservice/v2/images"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const bytesPerGB = 1 << 30
+
+// rootVolumeMachineSetDeployer boots the deployed machine sets from a root volume of the given size, instead of the flavor's
+// ephemeral disk.
+type rootVolumeMachineSetDeployer struct {
+	ocp.MachineSetDeployer
+	sizeGB      int
+	imageClient *gophercloud.ServiceClient
+}
+
+func validateRootVolumeSize(config *Config) error {
+	if config.RootVolumeSizeGB < 0 {
+		return fmt.Errorf("the root volume size can't be negative, got %d GB", config.RootVolumeSizeGB)
+	}
+
+	return nil
+}
+
+func newRootVolumeMachineSetDeployer(client *gophercloud.ProviderClient, config *Config, deployer ocp.MachineSetDeployer,
+) (*rootVolumeMachineSetDeployer, error) {
+	imageClient, err := openstack.NewImageServiceV2(client, gophercloud.EndpointOpts{Region: config.Region})
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating the image client")
+	}
+
+	return &rootVolumeMachineSetDeployer{MachineSetDeployer: deployer, sizeGB: config.RootVolumeSizeGB, imageClient: imageClient}, nil
+}
+
+func (d *rootVolumeMachineSetDeployer) Deploy(machineSet *unstructured.Unstructured) error {
+	providerSpec := []string{"spec", "template", "spec", "providerSpec", "value"}
+
+	imageName, _, err := unstructured.NestedString(machineSet.Object, append(providerSpec, "image")...)
+	if err != nil {
+		return errors.Wrap(err, "error reading the image of the machine set")
+	}
+
+	if imageName != "" {
+		minimumGB, err := d.imageMinimumGB(imageName)
+		if err != nil {
+			return err
+		}
+
+		if d.sizeGB < minimumGB {
+			return fmt.Errorf("the requested root volume size of %d GB is below the %d GB required by image %q",
+				d.sizeGB, minimumGB, imageName)
+		}
+	}
+
+	err = unstructured.SetNestedField(machineSet.Object, int64(d.sizeGB), append(providerSpec, "rootVolume", "diskSize")...)
+	if err != nil {
+		return errors.Wrap(err, "error setting the root volume size on the machine set")
+	}
+
+	return errors.Wrapf(d.MachineSetDeployer.Deploy(machineSet),
+		"error deploying the gateway machine set with a root volume of %d GB", d.sizeGB)
+}
+
+// imageMinimumGB returns the smallest root volume, in GB, which can hold the given image: its declared minimum disk size,
+// or its own size if larger.
+func (d *rootVolumeMachineSetDeployer) imageMinimumGB(imageName string) (int, error) {
+	pages, err := images.List(d.imageClient, images.ListOpts{Name: imageName}).AllPages()
+	if err != nil {
+		return 0, errors.Wrapf(err, "error looking up image %q", imageName)
+	}
+
+	found, err := images.ExtractImages(pages)
+	if err != nil {
+		return 0, errors.Wrapf(err, "error extracting image %q", imageName)
+	}
+
+	if len(found) == 0 {
+		return 0, fmt.Errorf("image %q wasn't found", imageName)
+	}
+
+	minimumGB := found[0].MinDiskGigabytes
+
+	if sizeGB := int((found[0].SizeBytes + bytesPerGB - 1) / bytesPerGB); sizeGB > minimumGB {
+		minimumGB = sizeGB
+	}
+
+	return minimumGB, nil
+}
@@ -0,0 +1,104 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/pkg/cloud"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const workerNodeLabel = "node-role.kubernetes.io/worker"
+
+// LabelWorkerGateways labels existing worker nodes as gateways until the cluster has the configured number of
+// gateways. It is used instead of deploying dedicated gateway nodes, and fails if there aren't enough eligible workers.
+func LabelWorkerGateways(clusterInfo *cluster.Info, config *Config, status reporter.Interface) error {
+	status.Start("Selecting %d worker node(s) as gateways", config.Gateways)
+	defer status.End()
+
+	existing, _, err := cloud.GatewayNodes(clusterInfo)
+	if err != nil {
+		return status.Error(err, "Unable to determine the existing gateway nodes")
+	}
+
+	needed := config.Gateways - existing
+	if needed <= 0 {
+		status.Success("The cluster already has %d gateway node(s)", existing)
+		return nil
+	}
+
+	eligible, err := eligibleWorkers(clusterInfo)
+	if err != nil {
+		return status.Error(err, "Unable to list the worker nodes")
+	}
+
+	if len(eligible) < needed {
+		return status.Error(fmt.Errorf("found %d eligible worker node(s) but %d are needed", len(eligible), needed),
+			"Insufficient worker nodes to use as gateways")
+	}
+
+	chosen := eligible[:needed]
+
+	if config.Preview {
+		status.Success("Would label worker node(s) %s as gateways", strings.Join(chosen, ", "))
+		return nil
+	}
+
+	k8sClient := k8s.NewInterface(clusterInfo.ClientProducer.ForKubernetes())
+
+	for _, name := range chosen {
+		if err := k8sClient.AddGWLabelOnNode(name); err != nil {
+			return status.Error(err, "Unable to label worker node %q as a gateway", name)
+		}
+	}
+
+	status.Success("Labeled worker node(s) %s as gateways", strings.Join(chosen, ", "))
+
+	return nil
+}
+
+// eligibleWorkers returns the names of the schedulable worker nodes which aren't gateways yet.
+func eligibleWorkers(clusterInfo *cluster.Info) ([]string, error) {
+	nodes, err := clusterInfo.ClientProducer.ForKubernetes().CoreV1().Nodes().List(context.TODO(),
+		metav1.ListOptions{LabelSelector: workerNodeLabel})
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the worker nodes")
+	}
+
+	names := []string{}
+
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if node.Spec.Unschedulable || node.Labels[constants.SubmarinerGatewayLabel] == constants.TrueLabel {
+			continue
+		}
+
+		names = append(names, node.Name)
+	}
+
+	return names, nil
+}
@@ -0,0 +1,84 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhos
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/availabilityzones"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// zonalMachineSetDeployer pins the deployed machine sets to an availability zone.
+type zonalMachineSetDeployer struct {
+	ocp.MachineSetDeployer
+	availabilityZone string
+}
+
+func (d *zonalMachineSetDeployer) Deploy(machineSet *unstructured.Unstructured) error {
+	err := unstructured.SetNestedField(machineSet.Object, d.availabilityZone,
+		"spec", "template", "spec", "providerSpec", "value", "availabilityZone")
+	if err != nil {
+		return errors.Wrap(err, "error setting the availability zone on the machine set")
+	}
+
+	return d.MachineSetDeployer.Deploy(machineSet) //nolint:wrapcheck // No need to wrap here
+}
+
+func validateAvailabilityZone(client *gophercloud.ProviderClient, region, availabilityZone string) error {
+	computeClient, err := openstack.NewComputeV2(client, gophercloud.EndpointOpts{Region: region})
+	if err != nil {
+		return errors.Wrap(err, "error creating the compute client")
+	}
+
+	pages, err := availabilityzones.List(computeClient).AllPages()
+	if err != nil {
+		return errors.Wrap(err, "error listing the availability zones")
+	}
+
+	zones, err := availabilityzones.ExtractAvailabilityZones(pages)
+	if err != nil {
+		return errors.Wrap(err, "error extracting the availability zones")
+	}
+
+	available := make([]string, 0, len(zones))
+
+	for i := range zones {
+		if !zones[i].ZoneState.Available {
+			continue
+		}
+
+		if zones[i].ZoneName == availabilityZone {
+			return nil
+		}
+
+		available = append(available, zones[i].ZoneName)
+	}
+
+	sort.Strings(available)
+
+	return fmt.Errorf("availability zone %q isn't available in region %q, the available zones are: %s",
+		availabilityZone, region, strings.Join(available, ", "))
+}
@@ -201,6 +201,11 @@ var validOverrides = []string{
 	names.MetricsProxyComponent,
 }
 
+// IsValidImageOverride returns true if images can be overridden for the given component.
+func IsValidImageOverride(component string) bool {
+	return slices.Contains(validOverrides, component)
+}
+
 func MergeImageOverrides(imageOverrides map[string]string, localImageOverrides []string) (map[string]string, error) {
 	if imageOverrides == nil {
 		imageOverrides = make(map[string]string, len(localImageOverrides))
@@ -212,7 +217,7 @@ func MergeImageOverrides(imageOverrides map[string]string, localImageOverrides [
 			return nil, fmt.Errorf("invalid override %s provided. Please use `a=b` syntax", s)
 		}
 
-		if !slices.Contains(validOverrides, component) {
+		if !IsValidImageOverride(component) {
 			return nil, fmt.Errorf("invalid override component %s provided. Please choose from %q", component, validOverrides)
 		}
 
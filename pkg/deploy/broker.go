@@ -21,40 +21,146 @@ package deploy
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/submariner-io/admiral/pkg/reporter"
 	"github.com/submariner-io/subctl/internal/component"
 	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/internal/rbac"
 	"github.com/submariner-io/subctl/pkg/broker"
 	"github.com/submariner-io/subctl/pkg/brokercr"
 	"github.com/submariner-io/subctl/pkg/client"
+	"github.com/submariner-io/subctl/pkg/cluster"
 	"github.com/submariner-io/subctl/pkg/image"
+	"github.com/submariner-io/subctl/pkg/namespace"
 	"github.com/submariner-io/subctl/pkg/operator"
+	"github.com/submariner-io/subctl/pkg/operator/crds"
+	"github.com/submariner-io/subctl/pkg/operator/deployment"
 	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
 	"github.com/submariner-io/submariner-operator/pkg/crd"
 	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
 )
 
 type BrokerOptions struct {
-	OperatorDebug   bool
-	Repository      string
-	ImageVersion    string
-	BrokerNamespace string
-	BrokerSpec      operatorv1alpha1.BrokerSpec
+	OperatorDebug          bool
+	DryRun                 bool
+	Retry                  RetryConfig
+	AdoptExistingNamespace bool
+	Repository             string
+	ImageVersion           string
+	BrokerNamespace        string
+	BrokerSpec             operatorv1alpha1.BrokerSpec
+	GlobalnetClusterSizes  map[string]uint
+	// GlobalnetExcludedCIDRs are sub-ranges of the globalnet CIDR range which are never allocated to clusters.
+	GlobalnetExcludedCIDRs []string
+	// ImageOverrides maps component names, as accepted by the image-override flags (e.g. "submariner-operator"),
+	// to the full image to use for that component. Unknown components are ignored with a warning.
+	ImageOverrides map[string]string
+	// VerifyTimeout, when positive, enables waiting up to the given duration for the deployed broker to become ready.
+	VerifyTimeout time.Duration
+	// OperatorNamespace overrides the namespace in which the operator is deployed, by default constants.OperatorNamespace.
+	OperatorNamespace string
+	// OperatorNodeSelector and OperatorTolerations, when set, are applied to the operator's pods.
+	OperatorNodeSelector map[string]string
+	OperatorTolerations  []corev1.Toleration
+	// OperatorResources, when set, are the CPU and memory requests and limits of the operator's container.
+	OperatorResources OperatorResources
+	// OperatorEnv are environment variables added to the operator's container, e.g. HTTP_PROXY and NO_PROXY; the variables
+	// set by subctl, deployment.ReservedEnvNames, can't be overridden.
+	OperatorEnv map[string]string
+	// StrictCRDs aborts the deployment if the CRDs already present are incompatible with those which would be applied,
+	// instead of updating them.
+	StrictCRDs bool
+	// SkipRBAC assumes the broker namespace, RBAC and service accounts are managed externally and already exist;
+	// the broker administrator service account and its token are checked instead of being created.
+	SkipRBAC bool
+	// PinImageDigests resolves the image tags to their digests by querying the registry, and deploys the images by digest.
+	PinImageDigests bool
+	// Timeout, when positive, bounds the whole deployment; if it expires, a TimeoutError is returned.
+	Timeout time.Duration
+	// CRDEstablishTimeout bounds the wait for the Broker CRD to be established before creating the Broker resource, by
+	// default DefaultCRDEstablishTimeout.
+	CRDEstablishTimeout time.Duration
+	// BrokerAPIVersion is the submariner.io API version with which the Broker resource is created, e.g. "v1alpha1"; by
+	// default, that of the submariner API package. It must be served by the Broker CRD.
+	BrokerAPIVersion string
+	// CA, or the TLS secret named CASecret in the broker namespace, provides the CA used as the broker's trust anchor
+	// instead of the broker cluster's generated one. Its certificate is distributed to the joining clusters.
+	CA       *brokercr.CA
+	CASecret string
+	// NamespaceLabels and NamespaceAnnotations are merged onto the broker namespace, and BrokerLabels onto the Broker resource.
+	// Keys in the submariner.io domain are reserved.
+	NamespaceLabels      map[string]string
+	NamespaceAnnotations map[string]string
+	BrokerLabels         map[string]string
+	// IPsec are the NAT-T port and preferred ESP encapsulation written to the join information, which the joining clusters
+	// use unless overridden when joining. They require the connectivity component.
+	IPsec broker.IPsecSettings
+	// TokenTTL, when positive, gives the joining clusters a bound broker administrator token expiring after that duration,
+	// at least broker.MinTokenTTL, instead of the long-lived one; its expiry is recorded in the join information. On clusters
+	// which don't support bound service account tokens, the long-lived token is given with a warning.
+	TokenTTL time.Duration
+	// FeatureGates enable the experimental components listed in ExperimentalComponents.
+	FeatureGates []string
+	// JoinInfo, if set, describes the file to which the join information is written once the broker is deployed. If that
+	// fails, the broker remains deployed and a JoinInfoExportError is returned.
+	JoinInfo *JoinInfoExport
+	// PostDeployHook, if set, is run once the broker is deployed and its join information exported; it isn't run on dry
+	// runs. If it fails, the broker remains deployed and a PostDeployHookError is returned.
+	PostDeployHook PostDeployHook
+	// DetectDriftOnly compares the live Broker resource and globalnet ConfigMap with the intended ones and reports the
+	// differences, without deploying anything; a DriftError is returned if the live state diverged.
+	DetectDriftOnly bool
+}
+
+// OperatorResources are resource quantities (e.g. "100m", "128Mi"); those left empty aren't set.
+type OperatorResources struct {
+	CPURequest    string
+	CPULimit      string
+	MemoryRequest string
+	MemoryLimit   string
 }
 
 var ValidComponents = []string{component.ServiceDiscovery, component.Connectivity}
 
-func Broker(options *BrokerOptions, clientProducer client.Producer, status reporter.Interface,
+// Broker deploys the broker. If the context is cancelled, or options.Timeout expires, the interrupted step is reported;
+// timeouts are returned as TimeoutErrors, other interruptions wrap the context's error.
+func Broker(ctx context.Context, options *BrokerOptions, clientProducer client.Producer, status reporter.Interface,
 ) error {
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
 	componentSet := sets.New(options.BrokerSpec.Components...)
-	ctx := context.TODO()
 
-	if err := isValidComponents(componentSet); err != nil {
+	if err := checkFeatureGates(options.FeatureGates); err != nil {
+		return status.Error(err, "invalid feature gates")
+	}
+
+	if err := ValidateComponents(options.BrokerSpec.Components, options.FeatureGates...); err != nil {
 		return status.Error(err, "invalid components parameter")
 	}
 
+	if experimental := options.experimentalComponents(); len(experimental) > 0 {
+		status.Warning("Deploying the experimental component(s) %s, which aren't generally available", strings.Join(experimental, ", "))
+	}
+
 	if options.BrokerSpec.GlobalnetEnabled {
 		componentSet.Insert(component.Globalnet)
 	}
@@ -63,62 +169,618 @@ func Broker(options *BrokerOptions, clientProducer client.Producer, status repor
 		return status.Error(err, "invalid GlobalCIDR configuration")
 	}
 
-	err := deploy(ctx, options, status, clientProducer)
+	if err := checkOperatorNamespace(options); err != nil {
+		return status.Error(err, "invalid operator namespace")
+	}
+
+	if err := checkOperatorPlacement(options); err != nil {
+		return status.Error(err, "invalid operator placement")
+	}
+
+	warnUnknownImageOverrides(options.ImageOverrides, status)
+
+	if err := checkBrokerCAOptions(options); err != nil {
+		return status.Error(err, "invalid broker CA")
+	}
+
+	if err := checkBrokerMetadata(options); err != nil {
+		return status.Error(err, "invalid broker metadata")
+	}
+
+	if err := checkIPsecSettings(options); err != nil {
+		return status.Error(err, "invalid IPsec settings")
+	}
+
+	if options.IPsec.IsSet() && options.JoinInfo == nil {
+		status.Warning("The IPsec settings are only given to the joining clusters through the join information, which isn't written")
+	}
+
+	if err := checkTokenTTL(options.TokenTTL); err != nil {
+		return status.Error(err, "invalid token TTL")
+	}
+
+	if options.TokenTTL > 0 && options.JoinInfo == nil {
+		status.Warning("The token TTL only applies to the token in the join information, which isn't written")
+	}
+
+	clusterCIDRs, err := allocateClusterGlobalCIDRs(options)
+	if err != nil {
+		return status.Error(err, "invalid per-cluster globalnet configuration")
+	}
+
+	if options.DryRun {
+		return dryRun(options, clusterCIDRs, status)
+	}
+
+	if err := checkDrift(ctx, clientProducer.ForGeneral(), options, status); err != nil || options.DetectDriftOnly {
+		return err
+	}
+
+	err = deploy(ctx, options, status, clientProducer)
 	if err != nil {
 		return err
 	}
 
 	if options.BrokerSpec.GlobalnetEnabled {
-		if err = globalnet.ValidateExistingGlobalNetworks(ctx, clientProducer.ForGeneral(), options.BrokerNamespace); err != nil {
-			return status.Error(err, "error validating existing globalCIDR configmap")
+		err = globalnet.ValidateExistingGlobalNetworks(ctx, clientProducer.ForGeneral(), options.BrokerNamespace)
+		if err != nil {
+			return stepError(ctx, status, err, "validating the existing globalCIDR configmap",
+				"error validating existing globalCIDR configmap")
 		}
 	}
 
-	if err = globalnet.CreateConfigMap(ctx, clientProducer.ForGeneral(), options.BrokerSpec.GlobalnetEnabled,
-		options.BrokerSpec.GlobalnetCIDRRange, options.BrokerSpec.DefaultGlobalnetClusterSize, options.BrokerNamespace); err != nil {
-		return status.Error(err, "error creating globalCIDR configmap on Broker")
+	if err = createGlobalnetConfigMap(ctx, clientProducer.ForGeneral(), options, clusterCIDRs, status); err != nil {
+		return stepError(ctx, status, err, "creating the globalCIDR configmap", "error creating globalCIDR configmap on Broker")
 	}
 
-	return nil
+	if options.VerifyTimeout > 0 {
+		if err := verifyBroker(ctx, clientProducer.ForKubernetes(), options, status); err != nil {
+			return err
+		}
+	}
+
+	if err := exportJoinInfo(ctx, clientProducer.ForKubernetes(), options, status); err != nil {
+		return err
+	}
+
+	return runPostDeployHook(ctx, options, status)
 }
 
 func deploy(ctx context.Context, options *BrokerOptions, status reporter.Interface, clientProducer client.Producer) error {
-	status.Start("Setting up broker RBAC")
+	status.Start("Checking the broker namespace")
+	defer status.End()
+
+	if err := checkBrokerNamespace(ctx, clientProducer.ForKubernetes(), options, status); err != nil {
+		if ctx.Err() != nil {
+			return interruptionError(ctx, status, "checking the broker namespace")
+		}
+
+		return err
+	}
+
+	ca, err := resolveBrokerCA(ctx, clientProducer.ForKubernetes(), options, status)
+	if err != nil {
+		return err
+	}
+
+	if options.SkipRBAC {
+		status.Start("Checking the existing broker RBAC")
+
+		if err := checkExistingRBAC(ctx, clientProducer.ForKubernetes(), options, status); err != nil {
+			return err
+		}
+	} else {
+		status.Start("Setting up broker RBAC")
+
+		err = broker.Ensure(ctx, crd.UpdaterFromControllerClient(clientProducer.ForGeneral()), clientProducer.ForKubernetes(),
+			options.BrokerSpec.Components, false, options.BrokerNamespace, options.NamespaceLabels, options.NamespaceAnnotations)
+		if err != nil {
+			return stepError(ctx, status, err, "setting up the broker RBAC", "error setting up broker RBAC")
+		}
+	}
+
+	if err := checkCRDCompatibility(ctx, crd.UpdaterFromControllerClient(clientProducer.ForGeneral()), options, status); err != nil {
+		return err
+	}
+
+	repositoryInfo := image.NewRepositoryInfo(options.Repository, options.ImageVersion, options.ImageOverrides)
+
+	operatorImage := repositoryInfo.GetOperatorImage()
+
+	if options.PinImageDigests {
+		if operatorImage, err = pinImageDigest(ctx, operatorImage, status); err != nil {
+			return err
+		}
+	}
+
+	err = withRetry(ctx, options.Retry, status, "Deploying the Submariner operator", func() error {
+		return operator.Ensure(ctx, status, clientProducer, options.operatorNamespace(), operatorImage,
+			options.OperatorDebug, options.operatorPlacement())
+	})
+	if err != nil {
+		return stepError(ctx, status, err, "deploying the Submariner operator", "error deploying Submariner operator")
+	}
+
+	reportOperatorPlacement(options, "Applied", status)
+
+	if err := waitForBrokerCRD(ctx, crd.UpdaterFromControllerClient(clientProducer.ForGeneral()), options, status); err != nil {
+		return err
+	}
+
+	apiVersion, err := checkBrokerAPIVersion(ctx, crd.UpdaterFromControllerClient(clientProducer.ForGeneral()), options, status)
+	if err != nil {
+		return err
+	}
+
+	err = withRetry(ctx, options.Retry, status, "Deploying the broker", func() error {
+		return brokercr.Ensure(ctx, clientProducer.ForGeneral(), options.BrokerNamespace, apiVersion, options.BrokerSpec,
+			options.BrokerLabels, options.brokerAnnotations(), ca)
+	})
+	if err == nil {
+		reportBrokerMetadata(options, "Applied", status)
+	}
+
+	return stepError(ctx, status, err, "deploying the broker", "The Broker CRD is established, but creating the Broker resource failed")
+}
+
+// pinImageDigest resolves the given image to its digest; failing to reach the registry is an error, the tag isn't used
+// as a fallback.
+func pinImageDigest(ctx context.Context, imageRef string, status reporter.Interface) (string, error) {
+	status.Start("Resolving the digest of image %q", imageRef)
 	defer status.End()
 
-	err := broker.Ensure(ctx, crd.UpdaterFromControllerClient(clientProducer.ForGeneral()), clientProducer.ForKubernetes(),
-		options.BrokerSpec.Components, false, options.BrokerNamespace)
+	pinned, err := image.ResolveDigest(ctx, imageRef)
+	if err != nil {
+		return "", stepError(ctx, status, err, "resolving the image digests", "error resolving the digest of image %q", imageRef)
+	}
+
+	status.Success("Resolved image %q to %q", imageRef, pinned)
+
+	return pinned, nil
+}
+
+func checkCRDCompatibility(ctx context.Context, crdUpdater crd.Updater, options *BrokerOptions, status reporter.Interface) error {
+	status.Start("Checking the compatibility of the existing CRDs")
+
+	comparisons, err := crds.CompareVersions(ctx, crdUpdater)
+	if err != nil {
+		return stepError(ctx, status, err, "checking the existing CRDs", "error checking the existing CRDs")
+	}
+
+	incompatible := []string{}
+
+	for i := range comparisons {
+		comparison := &comparisons[i]
+		existing := strings.Join(comparison.Existing, ", ")
+		intended := strings.Join(comparison.Intended, ", ")
+
+		if comparison.Compatible {
+			status.Success("The existing %s CRD (versions %s) is compatible with the intended versions %s", comparison.Name,
+				existing, intended)
+			continue
+		}
+
+		incompatible = append(incompatible, comparison.Name)
+
+		status.Warning("The existing %s CRD (versions %s) is incompatible with the intended versions %s", comparison.Name,
+			existing, intended)
+	}
+
+	if len(incompatible) == 0 {
+		return nil
+	}
+
+	if options.StrictCRDs {
+		return status.Error(fmt.Errorf("the existing CRDs %s were installed by an incompatible Submariner release",
+			strings.Join(incompatible, ", ")), "Aborting the deployment in strict mode")
+	}
+
+	status.Warning("Proceeding with updating the incompatible CRDs, which may break the existing deployment")
+
+	return nil
+}
+
+// stepError reports the given step's error with the message. If the context was cancelled, the interruption is reported
+// instead and the returned error wraps the context's error, allowing callers to distinguish cancellation from failure.
+func stepError(ctx context.Context, status reporter.Interface, err error, step, message string, args ...interface{}) error {
+	if err != nil && ctx.Err() != nil {
+		return interruptionError(ctx, status, step)
+	}
+
+	return status.Error(err, message, args...)
+}
+
+// TimeoutError is returned when the broker deployment doesn't complete in time; Step describes the step in progress.
+type TimeoutError struct {
+	Step string
+	Err  error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("timed out while %s", e.Step)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// interruptionError reports the interruption of the given step by the context; deadlines result in TimeoutErrors.
+func interruptionError(ctx context.Context, status reporter.Interface, step string) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return status.Error(&TimeoutError{Step: step, Err: ctx.Err()}, "")
+	}
+
+	return status.Error(ctx.Err(), "interrupted while %s", step)
+}
+
+func checkBrokerNamespace(ctx context.Context, kubeClient kubernetes.Interface, options *BrokerOptions, status reporter.Interface) error {
+	ns, err := kubeClient.CoreV1().Namespaces().Get(ctx, options.BrokerNamespace, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) && options.SkipRBAC {
+		return status.Error(fmt.Errorf("the broker namespace %q doesn't exist", options.BrokerNamespace),
+			"The broker RBAC must be created before deploying with it skipped")
+	}
+
+	if apierrors.IsNotFound(err) {
+		status.Success("The broker namespace %q will be created", options.BrokerNamespace)
+		return nil
+	}
+
+	if err != nil {
+		return status.Error(err, "error retrieving the broker namespace %q", options.BrokerNamespace)
+	}
+
+	if ns.Labels[constants.SubmarinerBrokerLabel] == constants.TrueLabel {
+		status.Success("Using the existing broker namespace %q", options.BrokerNamespace)
+		return nil
+	}
+
+	// Namespaces created by earlier versions aren't labeled, but already contain the broker administrator account
+	_, err = kubeClient.CoreV1().ServiceAccounts(options.BrokerNamespace).Get(ctx, constants.SubmarinerBrokerAdminSA, metav1.GetOptions{})
+	if err == nil {
+		status.Success("Using the existing broker namespace %q", options.BrokerNamespace)
+		return nil
+	}
+
+	if !apierrors.IsNotFound(err) {
+		return status.Error(err, "error checking the broker namespace %q", options.BrokerNamespace)
+	}
+
+	if !options.AdoptExistingNamespace {
+		return status.Error(fmt.Errorf("the namespace %q already exists but isn't labeled %s=%s; "+
+			"choose another broker namespace or allow it to be adopted", options.BrokerNamespace, constants.SubmarinerBrokerLabel,
+			constants.TrueLabel), "")
+	}
+
+	_, err = namespace.EnsureWithAnnotations(ctx, kubeClient, options.BrokerNamespace,
+		broker.NamespaceLabels(options.NamespaceLabels), options.NamespaceAnnotations)
 	if err != nil {
-		return status.Error(err, "error setting up broker RBAC")
+		return status.Error(err, "error adopting the broker namespace %q", options.BrokerNamespace)
 	}
 
-	status.Start("Deploying the Submariner operator")
+	status.Success("Adopted the existing namespace %q as the broker namespace", options.BrokerNamespace)
 
-	repositoryInfo := image.NewRepositoryInfo(options.Repository, options.ImageVersion, nil)
+	return nil
+}
+
+// checkExistingRBAC ensures that the externally-managed broker administrator service account exists and has a token,
+// without which the broker information file can't be written and clusters can't join.
+func checkExistingRBAC(ctx context.Context, kubeClient kubernetes.Interface, options *BrokerOptions, status reporter.Interface) error {
+	_, err := kubeClient.CoreV1().ServiceAccounts(options.BrokerNamespace).Get(ctx, constants.SubmarinerBrokerAdminSA, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return status.Error(fmt.Errorf("the service account %q doesn't exist in the broker namespace %q",
+			constants.SubmarinerBrokerAdminSA, options.BrokerNamespace), "The broker RBAC must be created before deploying with it skipped")
+	}
 
-	err = operator.Ensure(ctx, status, clientProducer, constants.OperatorNamespace, repositoryInfo.GetOperatorImage(), options.OperatorDebug)
 	if err != nil {
-		return status.Error(err, "error deploying Submariner operator")
+		return stepError(ctx, status, err, "checking the broker RBAC", "error retrieving the broker service account %q",
+			constants.SubmarinerBrokerAdminSA)
 	}
 
-	status.Start("Deploying the broker")
+	if _, err := rbac.GetClientTokenSecret(ctx, kubeClient, options.BrokerNamespace, constants.SubmarinerBrokerAdminSA); err != nil {
+		return stepError(ctx, status, err, "checking the broker RBAC", "error retrieving the token of the broker service account %q",
+			constants.SubmarinerBrokerAdminSA)
+	}
 
-	err = brokercr.Ensure(ctx, clientProducer.ForGeneral(), options.BrokerNamespace, options.BrokerSpec)
+	status.Success("Using the existing broker service account %q", constants.SubmarinerBrokerAdminSA)
 
-	return status.Error(err, "Broker deployment failed")
+	return nil
 }
 
-func isValidComponents(componentSet sets.Set[string]) error {
-	validComponentSet := sets.New(ValidComponents...)
+func dryRun(options *BrokerOptions, clusterCIDRs []clusterGlobalCIDRs, status reporter.Interface) error {
+	status.Start("Rendering the broker deployment (dry run, nothing will be applied)")
+	defer status.End()
+
+	repositoryInfo := image.NewRepositoryInfo(options.Repository, options.ImageVersion, options.ImageOverrides)
+
+	if options.SkipRBAC {
+		status.Success("Would use the existing broker RBAC in namespace %q", options.BrokerNamespace)
+	} else {
+		status.Success("Would set up the broker RBAC in namespace %q", options.BrokerNamespace)
+	}
+
+	status.Success("Would deploy the Submariner operator in namespace %q using image %q", options.operatorNamespace(),
+		repositoryInfo.GetOperatorImage())
+
+	if options.PinImageDigests {
+		status.Success("Would resolve the operator image to its digest and deploy it by digest")
+	}
+
+	reportOperatorPlacement(options, "Would apply", status)
+
+	switch {
+	case options.CA != nil:
+		status.Success("Would store the provided CA %q in secret %q and use it as the broker's trust anchor",
+			options.CA.Subject(), brokercr.CASecretName)
+	case options.CASecret != "":
+		status.Success("Would use the CA from secret %q as the broker's trust anchor", options.CASecret)
+	default:
+		status.Success("Would use the broker cluster's generated CA as the broker's trust anchor")
+	}
+
+	reportBrokerMetadata(options, "Would apply", status)
+
+	if options.JoinInfo != nil {
+		status.Success("Would write the join information to %q", options.JoinInfo.fileName())
+
+		if options.IPsec.IsSet() {
+			status.Success("Would give the joining clusters the IPsec defaults: %s", describeIPsecSettings(options.IPsec))
+		}
+
+		if options.TokenTTL > 0 {
+			status.Success("Would give the joining clusters a broker administrator token expiring after %v, if the cluster "+
+				"supports bound service account tokens", options.TokenTTL)
+		}
+	}
+
+	brokerCR := brokercr.New(options.BrokerNamespace, options.BrokerSpec)
+	brokerCR.Labels = options.BrokerLabels
+	brokerCR.Annotations = options.brokerAnnotations()
+	brokerCR.TypeMeta = metav1.TypeMeta{
+		APIVersion: operatorv1alpha1.GroupVersion.Group + "/" + options.brokerAPIVersion(),
+		Kind:       "Broker",
+	}
+
+	if err := reportRendered(brokerCR, "the Broker resource", status); err != nil {
+		return err
+	}
+
+	gnConfigMap, err := newGlobalnetConfigMap(options, clusterCIDRs)
+	if err != nil {
+		return status.Error(err, "error rendering the globalCIDR configmap")
+	}
+
+	gnConfigMap.TypeMeta = metav1.TypeMeta{
+		APIVersion: corev1.SchemeGroupVersion.String(),
+		Kind:       "ConfigMap",
+	}
+
+	if len(options.GlobalnetExcludedCIDRs) > 0 {
+		total, allocatable := allocatableGlobalnetSize(options)
+		status.Success("%d of the %d addresses in the globalnet CIDR range %s would remain allocatable", allocatable, total,
+			options.BrokerSpec.GlobalnetCIDRRange)
+	}
+
+	return reportRendered(gnConfigMap, "the globalCIDR configmap", status)
+}
+
+func reportRendered(obj interface{}, what string, status reporter.Interface) error {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return status.Error(err, "error rendering %s", what)
+	}
+
+	status.Success("Would create or update %s:\n%s", what, string(data))
+
+	return nil
+}
+
+// ValidateComponents checks that the given components can be deployed on a broker, the experimental components only being
+// valid with their feature gate enabled. All unknown components are reported in the returned error.
+func ValidateComponents(components []string, featureGates ...string) error {
+	componentSet := sets.New(components...)
 
 	if componentSet.Len() < 1 {
 		return fmt.Errorf("at least one component must be provided for deployment")
 	}
 
-	for _, component := range componentSet.UnsortedList() {
-		if !validComponentSet.Has(component) {
-			return fmt.Errorf("unknown component: %s", component)
+	if err := gatedComponentsError(componentSet, featureGates); err != nil {
+		return err
+	}
+
+	unknown := componentSet.Difference(sets.New(ValidComponents...)).Difference(sets.KeySet(ExperimentalComponents))
+
+	switch unknown.Len() {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("unknown component: %s", sets.List(unknown)[0])
+	default:
+		return fmt.Errorf("unknown components: %s", strings.Join(sets.List(unknown), ", "))
+	}
+}
+
+func (options *BrokerOptions) brokerAPIVersion() string {
+	if options.BrokerAPIVersion == "" {
+		return operatorv1alpha1.GroupVersion.Version
+	}
+
+	return options.BrokerAPIVersion
+}
+
+func (options *BrokerOptions) operatorNamespace() string {
+	if options.OperatorNamespace == "" {
+		return constants.OperatorNamespace
+	}
+
+	return options.OperatorNamespace
+}
+
+func checkOperatorNamespace(options *BrokerOptions) error {
+	if options.OperatorNamespace != "" && options.OperatorNamespace == options.BrokerNamespace {
+		return fmt.Errorf("the operator namespace %q can't be the same as the broker namespace", options.OperatorNamespace)
+	}
+
+	return nil
+}
+
+func (options *BrokerOptions) operatorPlacement() deployment.Placement {
+	// The resources are validated beforehand
+	resources, _ := options.OperatorResources.requirements()
+
+	return deployment.Placement{
+		NodeSelector: options.OperatorNodeSelector,
+		Tolerations:  options.OperatorTolerations,
+		Resources:    resources,
+		Env:          options.OperatorEnv,
+	}
+}
+
+func (r *OperatorResources) requirements() (corev1.ResourceRequirements, error) {
+	requirements := corev1.ResourceRequirements{}
+
+	quantities := []struct {
+		list  *corev1.ResourceList
+		name  corev1.ResourceName
+		value string
+		desc  string
+	}{
+		{&requirements.Requests, corev1.ResourceCPU, r.CPURequest, "CPU request"},
+		{&requirements.Limits, corev1.ResourceCPU, r.CPULimit, "CPU limit"},
+		{&requirements.Requests, corev1.ResourceMemory, r.MemoryRequest, "memory request"},
+		{&requirements.Limits, corev1.ResourceMemory, r.MemoryLimit, "memory limit"},
+	}
+
+	for _, q := range quantities {
+		if q.value == "" {
+			continue
+		}
+
+		quantity, err := resource.ParseQuantity(q.value)
+		if err != nil {
+			return requirements, errors.Wrapf(err, "invalid operator %s %q", q.desc, q.value)
+		}
+
+		if *q.list == nil {
+			*q.list = corev1.ResourceList{}
+		}
+
+		(*q.list)[q.name] = quantity
+	}
+
+	for name, limit := range requirements.Limits {
+		if request, ok := requirements.Requests[name]; ok && limit.Cmp(request) < 0 {
+			return requirements, fmt.Errorf("the operator %s limit %s is below its request %s", name, limit.String(), request.String())
+		}
+	}
+
+	return requirements, nil
+}
+
+func checkOperatorPlacement(options *BrokerOptions) error {
+	if errs := metav1validation.ValidateLabels(options.OperatorNodeSelector, field.NewPath("nodeSelector")); len(errs) > 0 {
+		return errors.Wrap(errs.ToAggregate(), "invalid operator node selector")
+	}
+
+	if _, err := options.OperatorResources.requirements(); err != nil {
+		return err
+	}
+
+	if err := checkOperatorEnv(options.OperatorEnv); err != nil {
+		return err
+	}
+
+	for i := range options.OperatorTolerations {
+		toleration := &options.OperatorTolerations[i]
+
+		switch toleration.Effect {
+		case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		default:
+			return fmt.Errorf("invalid effect %q in the operator toleration for key %q", toleration.Effect, toleration.Key)
+		}
+
+		if toleration.Operator == corev1.TolerationOpExists && toleration.Value != "" {
+			return fmt.Errorf("the operator toleration for key %q can't have a value with the Exists operator", toleration.Key)
+		}
+	}
+
+	return nil
+}
+
+func checkOperatorEnv(env map[string]string) error {
+	reserved := sets.New(deployment.ReservedEnvNames...)
+
+	for _, name := range sets.List(sets.KeySet(env)) {
+		if reserved.Has(name) {
+			return fmt.Errorf("the operator environment variable %q is managed by Submariner and can't be overridden", name)
 		}
+
+		if errs := validation.IsEnvVarName(name); len(errs) > 0 {
+			return fmt.Errorf("invalid operator environment variable name %q: %s", name, strings.Join(errs, ", "))
+		}
+	}
+
+	return nil
+}
+
+func reportOperatorPlacement(options *BrokerOptions, verb string, status reporter.Interface) {
+	if len(options.OperatorNodeSelector) > 0 {
+		status.Success("%s the node selector %q to the operator", verb, labels.SelectorFromSet(options.OperatorNodeSelector).String())
+	}
+
+	if len(options.OperatorTolerations) > 0 {
+		status.Success("%s %d toleration(s) to the operator", verb, len(options.OperatorTolerations))
+	}
+
+	if len(options.OperatorEnv) > 0 {
+		// Only the names are reported, the values may contain credentials, e.g. in proxy URLs
+		status.Success("%s the environment variable(s) %s to the operator", verb, strings.Join(sets.List(sets.KeySet(options.OperatorEnv)), ", "))
+	}
+
+	resources := options.operatorPlacement().Resources
+
+	if len(resources.Requests) > 0 {
+		status.Success("%s the resource requests %s to the operator", verb, formatResourceList(resources.Requests))
+	}
+
+	if len(resources.Limits) > 0 {
+		status.Success("%s the resource limits %s to the operator", verb, formatResourceList(resources.Limits))
+	}
+}
+
+func formatResourceList(list corev1.ResourceList) string {
+	formatted := []string{}
+
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if quantity, ok := list[name]; ok {
+			formatted = append(formatted, fmt.Sprintf("%s=%s", name, quantity.String()))
+		}
+	}
+
+	return strings.Join(formatted, ",")
+}
+
+func warnUnknownImageOverrides(overrides map[string]string, status reporter.Interface) {
+	unknown := sets.New[string]()
+
+	for component := range overrides {
+		if !cluster.IsValidImageOverride(component) {
+			unknown.Insert(component)
+		}
+	}
+
+	if unknown.Len() > 0 {
+		status.Warning("Ignoring image overrides for unknown components: %s", strings.Join(sets.List(unknown), ", "))
+	}
+}
+
+func checkTokenTTL(ttl time.Duration) error {
+	if ttl < 0 {
+		return fmt.Errorf("the token TTL %v can't be negative", ttl)
+	}
+
+	if ttl > 0 && ttl < broker.MinTokenTTL {
+		return fmt.Errorf("the token TTL %v is below the minimum of %v accepted for bound service account tokens", ttl,
+			broker.MinTokenTTL)
 	}
 
 	return nil
@@ -0,0 +1,322 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/component"
+	"github.com/submariner-io/subctl/pkg/deploy"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+)
+
+var _ = Describe("ValidateComponents", func() {
+	When("all the components are valid", func() {
+		It("should succeed", func() {
+			Expect(deploy.ValidateComponents([]string{component.Connectivity, component.ServiceDiscovery})).To(Succeed())
+		})
+	})
+
+	When("no components are provided", func() {
+		It("should return an error", func() {
+			Expect(deploy.ValidateComponents(nil)).To(MatchError(ContainSubstring("at least one component")))
+		})
+	})
+
+	When("one component is unknown", func() {
+		It("should return an error naming it", func() {
+			Expect(deploy.ValidateComponents([]string{component.Connectivity, "foo"})).To(MatchError("unknown component: foo"))
+		})
+	})
+
+	When("several components are unknown", func() {
+		It("should return an error naming all of them", func() {
+			Expect(deploy.ValidateComponents([]string{"foo", component.Connectivity, "bar"})).To(
+				MatchError("unknown components: bar, foo"))
+		})
+	})
+})
+
+var _ = Describe("ValidateComponents with experimental components", func() {
+	BeforeEach(func() {
+		deploy.ExperimentalComponents["experimental"] = "ExperimentalGate"
+
+		DeferCleanup(func() {
+			delete(deploy.ExperimentalComponents, "experimental")
+		})
+	})
+
+	When("the experimental component's gate is enabled", func() {
+		It("should succeed", func() {
+			Expect(deploy.ValidateComponents([]string{component.Connectivity, "experimental"}, "ExperimentalGate")).To(Succeed())
+		})
+	})
+
+	When("the experimental component's gate isn't enabled", func() {
+		It("should return an error naming the gate", func() {
+			Expect(deploy.ValidateComponents([]string{component.Connectivity, "experimental"})).To(
+				MatchError(ContainSubstring(`enable the feature gate "ExperimentalGate"`)))
+		})
+	})
+
+	When("an unknown feature gate is enabled", func() {
+		It("should return an error", func() {
+			_, err := deploy.NewBrokerOptions(func(options *deploy.BrokerOptions) {
+				options.FeatureGates = []string{"UnknownGate"}
+			})
+			Expect(err).To(MatchError(ContainSubstring("unknown feature gate(s) UnknownGate")))
+		})
+	})
+})
+
+var _ = Describe("Broker with per-cluster globalnet sizes", func() {
+	var options *deploy.BrokerOptions
+
+	BeforeEach(func() {
+		options = &deploy.BrokerOptions{
+			DryRun:          true,
+			BrokerNamespace: "submariner-k8s-broker",
+			BrokerSpec: operatorv1alpha1.BrokerSpec{
+				Components:                  []string{component.Connectivity},
+				GlobalnetEnabled:            true,
+				GlobalnetCIDRRange:          "242.0.0.0/16",
+				DefaultGlobalnetClusterSize: 8192,
+			},
+		}
+	})
+
+	When("the sizes fit in the globalnet CIDR range", func() {
+		It("should succeed", func() {
+			options.GlobalnetClusterSizes = map[string]uint{"east": 16384, "west": 32768}
+			Expect(deploy.Broker(context.TODO(), options, nil, reporter.Silent())).To(Succeed())
+		})
+	})
+
+	When("the sizes overflow the globalnet CIDR range", func() {
+		It("should return an error naming the overflowing cluster", func() {
+			options.GlobalnetClusterSizes = map[string]uint{"east": 32768, "north": 16384, "west": 32768}
+			Expect(deploy.Broker(context.TODO(), options, nil, reporter.Silent())).To(MatchError(ContainSubstring("cluster \"west\"")))
+		})
+	})
+
+	When("globalnet isn't enabled", func() {
+		It("should return an error", func() {
+			options.BrokerSpec.GlobalnetEnabled = false
+			options.GlobalnetClusterSizes = map[string]uint{"east": 1024}
+			Expect(deploy.Broker(context.TODO(), options, nil, reporter.Silent())).NotTo(Succeed())
+		})
+	})
+
+	When("the sizes fit in the space left by the excluded CIDRs", func() {
+		It("should succeed", func() {
+			options.GlobalnetExcludedCIDRs = []string{"242.0.0.0/17"}
+			options.GlobalnetClusterSizes = map[string]uint{"east": 16384, "west": 16384}
+			Expect(deploy.Broker(context.TODO(), options, nil, reporter.Silent())).To(Succeed())
+		})
+	})
+
+	When("the sizes overflow the space left by the excluded CIDRs", func() {
+		It("should return an error", func() {
+			options.GlobalnetExcludedCIDRs = []string{"242.0.0.0/17"}
+			options.GlobalnetClusterSizes = map[string]uint{"east": 32768, "west": 32768}
+			Expect(deploy.Broker(context.TODO(), options, nil, reporter.Silent())).To(MatchError(ContainSubstring("cluster \"west\"")))
+		})
+	})
+
+	When("an excluded CIDR is outside the globalnet CIDR range", func() {
+		It("should return an error", func() {
+			options.GlobalnetExcludedCIDRs = []string{"243.0.0.0/24"}
+			Expect(deploy.Broker(context.TODO(), options, nil, reporter.Silent())).To(MatchError(ContainSubstring("isn't within")))
+		})
+	})
+
+	When("excluded CIDRs overlap", func() {
+		It("should return an error", func() {
+			options.GlobalnetExcludedCIDRs = []string{"242.0.0.0/20", "242.0.8.0/24"}
+			Expect(deploy.Broker(context.TODO(), options, nil, reporter.Silent())).To(MatchError(ContainSubstring("overlap")))
+		})
+	})
+})
+
+var _ = Describe("NewBrokerOptions", func() {
+	When("no modifications are requested", func() {
+		It("should return the defaults", func() {
+			options, err := deploy.NewBrokerOptions()
+			Expect(err).To(Succeed())
+			Expect(options.BrokerNamespace).To(Equal("submariner-k8s-broker"))
+			Expect(options.BrokerSpec.Components).To(ConsistOf(component.Connectivity, component.ServiceDiscovery))
+			Expect(options.BrokerSpec.GlobalnetEnabled).To(BeFalse())
+		})
+	})
+
+	When("globalnet is enabled with a CIDR range", func() {
+		It("should succeed", func() {
+			options, err := deploy.NewBrokerOptions(deploy.WithGlobalnet("242.0.0.0/16", 8192))
+			Expect(err).To(Succeed())
+			Expect(options.BrokerSpec.GlobalnetEnabled).To(BeTrue())
+			Expect(options.BrokerSpec.GlobalnetCIDRRange).To(Equal("242.0.0.0/16"))
+		})
+	})
+
+	When("globalnet is enabled with an empty CIDR range", func() {
+		It("should return an error", func() {
+			_, err := deploy.NewBrokerOptions(deploy.WithGlobalnet("", 8192))
+			Expect(err).To(MatchError(ContainSubstring("no globalnet CIDR range")))
+		})
+	})
+
+	When("an unknown component is requested", func() {
+		It("should return an error", func() {
+			_, err := deploy.NewBrokerOptions(deploy.WithComponents("foo"))
+			Expect(err).To(MatchError("unknown component: foo"))
+		})
+	})
+
+	When("the operator namespace is the broker namespace", func() {
+		It("should return an error", func() {
+			_, err := deploy.NewBrokerOptions(func(options *deploy.BrokerOptions) {
+				options.OperatorNamespace = options.BrokerNamespace
+			})
+			Expect(err).To(MatchError(ContainSubstring("same as the broker namespace")))
+		})
+	})
+
+	When("the operator node selector has an invalid label", func() {
+		It("should return an error", func() {
+			_, err := deploy.NewBrokerOptions(func(options *deploy.BrokerOptions) {
+				options.OperatorNodeSelector = map[string]string{"invalid label": "infra"}
+			})
+			Expect(err).To(MatchError(ContainSubstring("invalid operator node selector")))
+		})
+	})
+
+	When("the operator resources are valid", func() {
+		It("should succeed", func() {
+			_, err := deploy.NewBrokerOptions(func(options *deploy.BrokerOptions) {
+				options.OperatorResources = deploy.OperatorResources{CPURequest: "100m", CPULimit: "1", MemoryLimit: "256Mi"}
+			})
+			Expect(err).To(Succeed())
+		})
+	})
+
+	When("an operator resource quantity can't be parsed", func() {
+		It("should return an error", func() {
+			_, err := deploy.NewBrokerOptions(func(options *deploy.BrokerOptions) {
+				options.OperatorResources = deploy.OperatorResources{MemoryRequest: "lots"}
+			})
+			Expect(err).To(MatchError(ContainSubstring("invalid operator memory request")))
+		})
+	})
+
+	When("an operator resource limit is below its request", func() {
+		It("should return an error", func() {
+			_, err := deploy.NewBrokerOptions(func(options *deploy.BrokerOptions) {
+				options.OperatorResources = deploy.OperatorResources{MemoryRequest: "1Gi", MemoryLimit: "512Mi"}
+			})
+			Expect(err).To(MatchError(ContainSubstring("below its request")))
+		})
+	})
+
+	When("the broker metadata is valid", func() {
+		It("should succeed", func() {
+			_, err := deploy.NewBrokerOptions(func(options *deploy.BrokerOptions) {
+				options.NamespaceLabels = map[string]string{"example.com/owner": "team-a"}
+				options.NamespaceAnnotations = map[string]string{"example.com/cost-center": "1234"}
+				options.BrokerLabels = map[string]string{"owner": "team-a"}
+			})
+			Expect(err).To(Succeed())
+		})
+	})
+
+	When("a broker namespace label uses a reserved key", func() {
+		It("should return an error naming it", func() {
+			_, err := deploy.NewBrokerOptions(func(options *deploy.BrokerOptions) {
+				options.NamespaceLabels = map[string]string{"submariner.io/broker": "false"}
+			})
+			Expect(err).To(MatchError(ContainSubstring("submariner.io/broker are reserved")))
+		})
+	})
+
+	When("a Broker resource label uses a key in a reserved subdomain", func() {
+		It("should return an error", func() {
+			_, err := deploy.NewBrokerOptions(func(options *deploy.BrokerOptions) {
+				options.BrokerLabels = map[string]string{"operator.submariner.io/owner": "team-a"}
+			})
+			Expect(err).To(MatchError(ContainSubstring("reserved")))
+		})
+	})
+
+	When("an operator environment variable is reserved", func() {
+		It("should return an error naming it", func() {
+			_, err := deploy.NewBrokerOptions(func(options *deploy.BrokerOptions) {
+				options.OperatorEnv = map[string]string{"HTTP_PROXY": "http://proxy:3128", "WATCH_NAMESPACE": "other"}
+			})
+			Expect(err).To(MatchError(ContainSubstring(`"WATCH_NAMESPACE" is managed by Submariner`)))
+		})
+	})
+
+	When("the IPsec settings are valid", func() {
+		It("should succeed", func() {
+			options, err := deploy.NewBrokerOptions(deploy.WithIPsec(4501, "udp"))
+			Expect(err).To(Succeed())
+			Expect(options.IPsec.NATTPort).To(Equal(4501))
+		})
+	})
+
+	When("the IPsec NAT-T port is out of range", func() {
+		It("should return an error", func() {
+			_, err := deploy.NewBrokerOptions(deploy.WithIPsec(70000, ""))
+			Expect(err).To(MatchError(ContainSubstring("out of range")))
+		})
+	})
+
+	When("an IPsec NAT-T port is set with the esp encapsulation", func() {
+		It("should return an error", func() {
+			_, err := deploy.NewBrokerOptions(deploy.WithIPsec(4501, "esp"))
+			Expect(err).To(MatchError(ContainSubstring("disables NAT traversal")))
+		})
+	})
+
+	When("IPsec settings are set without the connectivity component", func() {
+		It("should return an error", func() {
+			_, err := deploy.NewBrokerOptions(deploy.WithComponents(component.ServiceDiscovery), deploy.WithIPsec(0, "udp"))
+			Expect(err).To(MatchError(ContainSubstring("only apply to the connectivity component")))
+		})
+	})
+
+	When("the token TTL is below the minimum", func() {
+		It("should return an error", func() {
+			_, err := deploy.NewBrokerOptions(func(options *deploy.BrokerOptions) {
+				options.TokenTTL = time.Minute
+			})
+			Expect(err).To(MatchError(ContainSubstring("below the minimum")))
+		})
+	})
+
+	When("the broker namespace is empty", func() {
+		It("should return an error", func() {
+			_, err := deploy.NewBrokerOptions(deploy.WithBrokerNamespace(""))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,78 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/pkg/brokercr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// checkBrokerCAOptions validates a CA provided directly; a CA referenced by secret is validated once it's retrieved.
+func checkBrokerCAOptions(options *BrokerOptions) error {
+	if options.CA == nil {
+		return nil
+	}
+
+	if options.CASecret != "" {
+		return errors.New("a CA can't be provided both directly and by secret")
+	}
+
+	return options.CA.Validate() //nolint:wrapcheck // No need to wrap here
+}
+
+// resolveBrokerCA returns the CA to use as the broker's trust anchor, or nil if the broker cluster's generated CA is used.
+func resolveBrokerCA(ctx context.Context, kubeClient kubernetes.Interface, options *BrokerOptions, status reporter.Interface,
+) (*brokercr.CA, error) {
+	status.Start("Checking the broker CA")
+
+	ca := options.CA
+
+	if options.CASecret != "" {
+		secret, err := kubeClient.CoreV1().Secrets(options.BrokerNamespace).Get(ctx, options.CASecret, metav1.GetOptions{})
+		if err != nil {
+			return nil, stepError(ctx, status, err, "retrieving the broker CA",
+				"error retrieving the CA secret %q in namespace %q", options.CASecret, options.BrokerNamespace)
+		}
+
+		if secret.Type != corev1.SecretTypeTLS {
+			return nil, status.Error(errors.Errorf("the secret is of type %q, expected %q", secret.Type, corev1.SecretTypeTLS),
+				"invalid CA secret %q", options.CASecret)
+		}
+
+		ca = brokercr.CAFromSecret(secret)
+
+		if err := ca.Validate(); err != nil {
+			return nil, status.Error(err, "invalid CA in secret %q", options.CASecret)
+		}
+	}
+
+	if ca == nil {
+		status.Success("No CA was provided, the broker cluster's generated CA will be used")
+	} else {
+		status.Success("Using the provided CA %q", ca.Subject())
+	}
+
+	return ca, nil
+}
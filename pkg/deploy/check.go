@@ -0,0 +1,248 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/component"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/pkg/brokercr"
+	"github.com/submariner-io/subctl/pkg/client"
+	"github.com/submariner-io/subctl/pkg/operator/crds"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/crd"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type CheckStatus string
+
+const (
+	CheckPassed  CheckStatus = "pass"
+	CheckWarning CheckStatus = "warn"
+	CheckFailed  CheckStatus = "fail"
+)
+
+// CheckResult is the outcome of checking one aspect of a deployed broker.
+type CheckResult struct {
+	Aspect  string      `json:"aspect"`
+	Status  CheckStatus `json:"status"`
+	Message string      `json:"message"`
+}
+
+// The CRDs installed by the operator when it reconciles the Broker, per component.
+var brokerComponentCRDs = map[string][]string{
+	component.Connectivity:     {"clusters.submariner.io", "endpoints.submariner.io"},
+	component.ServiceDiscovery: {"serviceimports.multicluster.x-k8s.io"},
+}
+
+// CheckBroker assesses the health of an already-deployed broker, without modifying anything: its RBAC, the operator,
+// the operator CRDs, the Broker resource and the CRDs installed when reconciling it, and the globalnet ConfigMap.
+// Each aspect is reported as it's checked; the returned error is non-nil if any of them failed.
+func CheckBroker(ctx context.Context, brokerNamespace, operatorNamespace string, clientProducer client.Producer,
+	status reporter.Interface,
+) ([]CheckResult, error) {
+	if operatorNamespace == "" {
+		operatorNamespace = constants.OperatorNamespace
+	}
+
+	status.Start("Checking the broker in namespace %q", brokerNamespace)
+	defer status.End()
+
+	results := []CheckResult{}
+
+	for _, resource := range expectedBrokerResources(clientProducer.ForKubernetes(), brokerNamespace, operatorNamespace) {
+		ready, err := resource.isReady(ctx)
+
+		switch {
+		case err != nil:
+			results = append(results, failed(resource.description, "error checking: %v", err))
+		case ready:
+			results = append(results, passed(resource.description, "ready"))
+		default:
+			results = append(results, failed(resource.description, "missing or not ready"))
+		}
+	}
+
+	crdUpdater := crd.UpdaterFromControllerClient(clientProducer.ForGeneral())
+
+	results = append(results, checkOperatorCRDs(ctx, crdUpdater))
+
+	brokerCR := &operatorv1alpha1.Broker{}
+
+	err := clientProducer.ForGeneral().Get(ctx, controllerClient.ObjectKey{Namespace: brokerNamespace, Name: brokercr.Name}, brokerCR)
+	if err != nil {
+		results = append(results, failed("Broker resource", "error retrieving it: %v", err))
+	} else {
+		results = append(results, checkBrokerReconciled(ctx, crdUpdater, brokerCR),
+			checkGlobalnetConfigMap(ctx, clientProducer.ForGeneral(), brokerNamespace, &brokerCR.Spec))
+	}
+
+	failures := []string{}
+
+	for i := range results {
+		switch results[i].Status {
+		case CheckPassed:
+			status.Success("%s: %s", results[i].Aspect, results[i].Message)
+		case CheckWarning:
+			status.Warning("%s: %s", results[i].Aspect, results[i].Message)
+		case CheckFailed:
+			status.Failure("%s: %s", results[i].Aspect, results[i].Message)
+			failures = append(failures, results[i].Aspect)
+		}
+	}
+
+	if len(failures) > 0 {
+		return results, fmt.Errorf("the broker check failed for: %s", strings.Join(failures, ", "))
+	}
+
+	return results, nil
+}
+
+func checkOperatorCRDs(ctx context.Context, crdUpdater crd.Updater) CheckResult {
+	const aspect = "Operator CRDs"
+
+	comparisons, err := crds.CompareVersions(ctx, crdUpdater)
+	if err != nil {
+		return failed(aspect, "error checking them: %v", err)
+	}
+
+	if len(comparisons) < crds.OperatorCRDCount() {
+		return failed(aspect, "only %d of the %d CRDs are installed", len(comparisons), crds.OperatorCRDCount())
+	}
+
+	for i := range comparisons {
+		if !comparisons[i].Compatible {
+			return warned(aspect, "the %s CRD (versions %s) doesn't match this release's versions %s", comparisons[i].Name,
+				strings.Join(comparisons[i].Existing, ", "), strings.Join(comparisons[i].Intended, ", "))
+		}
+	}
+
+	return passed(aspect, "installed")
+}
+
+// checkBrokerReconciled checks that the CRDs which the operator installs for the Broker's components are present.
+func checkBrokerReconciled(ctx context.Context, crdUpdater crd.Updater, brokerCR *operatorv1alpha1.Broker) CheckResult {
+	const aspect = "Broker resource"
+
+	if brokerCR.DeletionTimestamp != nil {
+		return warned(aspect, "it's being deleted")
+	}
+
+	missing := []string{}
+
+	for _, comp := range brokerCR.Spec.Components {
+		for _, name := range brokerComponentCRDs[comp] {
+			_, err := crdUpdater.Get(ctx, name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				missing = append(missing, name)
+				continue
+			}
+
+			if err != nil {
+				return failed(aspect, "error retrieving the %s CRD: %v", name, err)
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return failed(aspect, "not reconciled, the CRDs %s are missing", strings.Join(missing, ", "))
+	}
+
+	return passed(aspect, "reconciled for components %s", strings.Join(brokerCR.Spec.Components, ", "))
+}
+
+// checkGlobalnetConfigMap checks that the globalnet ConfigMap matches the Broker's settings, and that the allocated
+// global CIDRs are within the range and don't overlap.
+func checkGlobalnetConfigMap(ctx context.Context, client controllerClient.Client, brokerNamespace string,
+	spec *operatorv1alpha1.BrokerSpec,
+) CheckResult {
+	const aspect = "Globalnet ConfigMap"
+
+	info, _, err := globalnet.GetGlobalNetworks(ctx, client, brokerNamespace)
+	if err != nil {
+		return failed(aspect, "error retrieving it: %v", err)
+	}
+
+	if info.Enabled != spec.GlobalnetEnabled {
+		return failed(aspect, "globalnet is %s in the ConfigMap but %s in the Broker", enabledString(info.Enabled),
+			enabledString(spec.GlobalnetEnabled))
+	}
+
+	if !info.Enabled {
+		return passed(aspect, "globalnet is disabled")
+	}
+
+	_, cidrRange, err := net.ParseCIDR(info.CidrRange)
+	if err != nil {
+		return failed(aspect, "invalid globalnet CIDR range %q", info.CidrRange)
+	}
+
+	if spec.GlobalnetCIDRRange != "" && spec.GlobalnetCIDRRange != info.CidrRange {
+		return warned(aspect, "the CIDR range %s differs from the Broker's %s", info.CidrRange, spec.GlobalnetCIDRRange)
+	}
+
+	allocated := []*net.IPNet{}
+
+	for clusterID, network := range info.CidrInfo {
+		for _, cidr := range network.GlobalCIDRs {
+			_, allocation, err := net.ParseCIDR(cidr)
+			if err != nil || !cidrRange.Contains(allocation.IP) {
+				return failed(aspect, "the global CIDR %q of %q isn't within the range %s", cidr, clusterID, cidrRange)
+			}
+
+			for _, other := range allocated {
+				if other.Contains(allocation.IP) || allocation.Contains(other.IP) {
+					return failed(aspect, "the global CIDR %s of %q overlaps with %s", cidr, clusterID, other)
+				}
+			}
+
+			allocated = append(allocated, allocation)
+		}
+	}
+
+	return passed(aspect, "consistent, %d global CIDRs allocated in %s", len(allocated), cidrRange)
+}
+
+func enabledString(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+
+	return "disabled"
+}
+
+func passed(aspect, message string, args ...interface{}) CheckResult {
+	return CheckResult{Aspect: aspect, Status: CheckPassed, Message: fmt.Sprintf(message, args...)}
+}
+
+func warned(aspect, message string, args ...interface{}) CheckResult {
+	return CheckResult{Aspect: aspect, Status: CheckWarning, Message: fmt.Sprintf(message, args...)}
+}
+
+func failed(aspect, message string, args ...interface{}) CheckResult {
+	return CheckResult{Aspect: aspect, Status: CheckFailed, Message: fmt.Sprintf(message, args...)}
+}
@@ -0,0 +1,146 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/submariner-operator/pkg/crd"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	brokerCRDName = "brokers.submariner.io"
+
+	// DefaultCRDEstablishTimeout is how long the deployment waits for the Broker CRD to be established by default.
+	DefaultCRDEstablishTimeout = 2 * time.Minute
+
+	crdEstablishInterval = time.Second
+)
+
+// waitForBrokerCRD polls the Broker CRD, applied along with the operator, until the API server reports it as established;
+// creating the Broker resource before that fails with "no matches for kind".
+func waitForBrokerCRD(ctx context.Context, crdUpdater crd.Updater, options *BrokerOptions, status reporter.Interface) error {
+	status.Start("Waiting for the %s CRD to be established", brokerCRDName)
+	defer status.End()
+
+	timeout := options.CRDEstablishTimeout
+	if timeout <= 0 {
+		timeout = DefaultCRDEstablishTimeout
+	}
+
+	lastState := "the CRD doesn't exist"
+
+	err := wait.PollImmediateWithContext(ctx, crdEstablishInterval, timeout, func(ctx context.Context) (bool, error) {
+		brokerCRD, err := crdUpdater.Get(ctx, brokerCRDName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+
+		if err != nil {
+			return false, err //nolint:wrapcheck // No need to wrap here
+		}
+
+		established, state := crdEstablishment(brokerCRD)
+		lastState = state
+
+		return established, nil
+	})
+
+	if ctx.Err() != nil {
+		return interruptionError(ctx, status, "waiting for the Broker CRD")
+	}
+
+	if goerrors.Is(err, wait.ErrWaitTimeout) {
+		return status.Error(fmt.Errorf("the %s CRD was never established within %v (%s)", brokerCRDName, timeout, lastState),
+			"The Broker CRD isn't available, the Broker resource can't be created")
+	}
+
+	if err != nil {
+		return status.Error(err, "error retrieving the %s CRD", brokerCRDName)
+	}
+
+	status.Success("The %s CRD is established", brokerCRDName)
+
+	return nil
+}
+
+// checkBrokerAPIVersion checks that the Broker CRD serves the API version with which the Broker resource is to be created,
+// and returns that version.
+func checkBrokerAPIVersion(ctx context.Context, crdUpdater crd.Updater, options *BrokerOptions, status reporter.Interface,
+) (string, error) {
+	apiVersion := options.brokerAPIVersion()
+
+	status.Start("Checking that the %s CRD serves API version %q", brokerCRDName, apiVersion)
+	defer status.End()
+
+	brokerCRD, err := crdUpdater.Get(ctx, brokerCRDName, metav1.GetOptions{})
+	if err != nil {
+		return "", status.Error(err, "error retrieving the %s CRD", brokerCRDName)
+	}
+
+	served := []string{}
+
+	for i := range brokerCRD.Spec.Versions {
+		if brokerCRD.Spec.Versions[i].Served {
+			served = append(served, brokerCRD.Spec.Versions[i].Name)
+		}
+	}
+
+	if !sets.New(served...).Has(apiVersion) {
+		return "", status.Error(fmt.Errorf("API version %q isn't served by the %s CRD, which serves %s", apiVersion, brokerCRDName,
+			strings.Join(served, ", ")), "Invalid Broker API version")
+	}
+
+	status.Success("The Broker resource will be created with API version %s/%s", brokerCRD.Spec.Group, apiVersion)
+
+	return apiVersion, nil
+}
+
+// crdEstablishment returns whether the given CRD is established and, if not, a description of its state.
+func crdEstablishment(crd *apiextensions.CustomResourceDefinition) (bool, string) {
+	for _, condition := range crd.Status.Conditions {
+		if condition.Type == apiextensions.NamesAccepted && condition.Status == apiextensions.ConditionFalse {
+			return false, fmt.Sprintf("its names weren't accepted: %s", condition.Message)
+		}
+	}
+
+	for _, condition := range crd.Status.Conditions {
+		if condition.Type != apiextensions.Established {
+			continue
+		}
+
+		if condition.Status == apiextensions.ConditionTrue {
+			return true, ""
+		}
+
+		return false, fmt.Sprintf("established is %s: %s", condition.Status, condition.Message)
+	}
+
+	return false, "the CRD has no established condition yet"
+}
@@ -0,0 +1,289 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/pkg/brokercr"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DriftAction is what deploying the broker does to one of its resources.
+type DriftAction string
+
+const (
+	DriftNoOp   DriftAction = "no-op"
+	DriftUpdate DriftAction = "update"
+	DriftCreate DriftAction = "create"
+)
+
+// ResourceDrift describes how a live broker resource differs from the intended one.
+type ResourceDrift struct {
+	// Resource identifies the resource, as kind namespace/name.
+	Resource string
+	Action   DriftAction
+	// Differences list the fields which differ, with their live and intended values.
+	Differences []string
+	// Unreconciled is set when the differences aren't changed by deploying, e.g. the globalnet CIDR range of an existing
+	// broker.
+	Unreconciled bool
+}
+
+// DriftReport compares the live Broker resource and globalnet ConfigMap with those the deployment would apply.
+type DriftReport struct {
+	Resources []ResourceDrift
+}
+
+// HasDrift returns whether any resource is missing or differs from its intended state.
+func (r *DriftReport) HasDrift() bool {
+	for i := range r.Resources {
+		if r.Resources[i].Action != DriftNoOp || len(r.Resources[i].Differences) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DriftError is returned when only detecting drift, and the live state has diverged from the intended state.
+type DriftError struct {
+	Report *DriftReport
+}
+
+func (e *DriftError) Error() string {
+	drifted := []string{}
+
+	for i := range e.Report.Resources {
+		if e.Report.Resources[i].Action != DriftNoOp || len(e.Report.Resources[i].Differences) > 0 {
+			drifted = append(drifted, e.Report.Resources[i].Resource)
+		}
+	}
+
+	return fmt.Sprintf("the live broker state has drifted from the intended state: %s", strings.Join(drifted, ", "))
+}
+
+// DetectDrift compares the live Broker resource and globalnet ConfigMap with those which deploying the broker with the
+// given options would apply. The per-cluster globalnet allocations aren't compared, they evolve as clusters join.
+func DetectDrift(ctx context.Context, client controllerClient.Client, options *BrokerOptions) (*DriftReport, error) {
+	report := &DriftReport{}
+
+	brokerDrift, err := detectBrokerDrift(ctx, client, options)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Resources = append(report.Resources, *brokerDrift)
+
+	allocations, err := allocateClusterGlobalCIDRs(options)
+	if err != nil {
+		return nil, err
+	}
+
+	configMapDrift, err := detectGlobalnetConfigMapDrift(ctx, client, options, allocations)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Resources = append(report.Resources, *configMapDrift)
+
+	return report, nil
+}
+
+func detectBrokerDrift(ctx context.Context, client controllerClient.Client, options *BrokerOptions) (*ResourceDrift, error) {
+	desired := brokercr.New(options.BrokerNamespace, options.BrokerSpec)
+	drift := &ResourceDrift{Resource: "Broker " + options.BrokerNamespace + "/" + desired.Name, Action: DriftNoOp}
+
+	live := &operatorv1alpha1.Broker{}
+
+	err := client.Get(ctx, controllerClient.ObjectKeyFromObject(desired), live)
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		drift.Action = DriftCreate
+		return drift, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "error retrieving the Broker resource")
+	}
+
+	desiredSpec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&desired.Spec)
+	if err != nil {
+		return nil, errors.Wrap(err, "error converting the intended Broker spec")
+	}
+
+	liveSpec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&live.Spec)
+	if err != nil {
+		return nil, errors.Wrap(err, "error converting the live Broker spec")
+	}
+
+	drift.Differences = append(drift.Differences, mapDifferences("spec", liveSpec, desiredSpec)...)
+	drift.Differences = append(drift.Differences, stringMapDifferences("metadata.labels", live.Labels, options.BrokerLabels)...)
+	drift.Differences = append(drift.Differences, stringMapDifferences("metadata.annotations", live.Annotations,
+		options.brokerAnnotations())...)
+
+	if len(drift.Differences) > 0 {
+		// The Broker resource is recreated rather than patched
+		drift.Action = DriftUpdate
+	}
+
+	return drift, nil
+}
+
+func detectGlobalnetConfigMapDrift(ctx context.Context, client controllerClient.Client, options *BrokerOptions,
+	allocations []clusterGlobalCIDRs,
+) (*ResourceDrift, error) {
+	desired, err := newGlobalnetConfigMap(options, allocations)
+	if err != nil {
+		return nil, err
+	}
+
+	drift := &ResourceDrift{Resource: "ConfigMap " + options.BrokerNamespace + "/" + desired.Name, Action: DriftNoOp}
+
+	live := &corev1.ConfigMap{}
+
+	err = client.Get(ctx, controllerClient.ObjectKeyFromObject(desired), live)
+	if apierrors.IsNotFound(err) {
+		drift.Action = DriftCreate
+		return drift, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "error retrieving the globalnet ConfigMap")
+	}
+
+	liveData := map[string]string{}
+	desiredData := map[string]string{}
+
+	for key, value := range live.Data {
+		if key != globalnetClusterInfoKey {
+			liveData[key] = value
+		}
+	}
+
+	for key, value := range desired.Data {
+		if key != globalnetClusterInfoKey {
+			desiredData[key] = value
+		}
+	}
+
+	drift.Differences = stringMapDifferences("data", liveData, desiredData)
+
+	if len(drift.Differences) == 0 {
+		return drift, nil
+	}
+
+	// Only enabling globalnet is applied to an existing ConfigMap, see reconcileGlobalnetConfigMap
+	if options.BrokerSpec.GlobalnetEnabled && live.Data["globalnetEnabled"] != "true" {
+		drift.Action = DriftUpdate
+	} else {
+		drift.Unreconciled = true
+	}
+
+	return drift, nil
+}
+
+// mapDifferences lists the top-level fields whose values differ between the live and intended maps.
+func mapDifferences(prefix string, live, desired map[string]interface{}) []string {
+	differences := []string{}
+
+	for _, key := range sets.List(sets.KeySet(live).Union(sets.KeySet(desired))) {
+		if !reflect.DeepEqual(live[key], desired[key]) {
+			differences = append(differences, fmt.Sprintf("%s.%s: live %s, intended %s", prefix, key, formatValue(live[key]),
+				formatValue(desired[key])))
+		}
+	}
+
+	return differences
+}
+
+// stringMapDifferences lists the intended keys whose live values differ; extra live keys, e.g. added by other tools,
+// aren't drift.
+func stringMapDifferences(prefix string, live, desired map[string]string) []string {
+	differences := []string{}
+
+	for _, key := range sets.List(sets.KeySet(desired)) {
+		liveValue, found := live[key]
+		if !found {
+			differences = append(differences, fmt.Sprintf("%s.%s: live <unset>, intended %q", prefix, key, desired[key]))
+		} else if liveValue != desired[key] {
+			differences = append(differences, fmt.Sprintf("%s.%s: live %q, intended %q", prefix, key, liveValue, desired[key]))
+		}
+	}
+
+	return differences
+}
+
+func formatValue(value interface{}) string {
+	if value == nil {
+		return "<unset>"
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
+// reportDrift reports what deploying does to each resource.
+func reportDrift(report *DriftReport, status reporter.Interface) {
+	for i := range report.Resources {
+		drift := &report.Resources[i]
+
+		switch {
+		case drift.Action == DriftCreate:
+			status.Success("%s doesn't exist and will be created", drift.Resource)
+		case drift.Action == DriftUpdate:
+			status.Success("%s differs from the intended state and will be updated: %s", drift.Resource,
+				strings.Join(drift.Differences, "; "))
+		case drift.Unreconciled:
+			status.Warning("%s differs from the intended state, but won't be changed: %s", drift.Resource,
+				strings.Join(drift.Differences, "; "))
+		default:
+			status.Success("%s matches the intended state, deploying it is a no-op", drift.Resource)
+		}
+	}
+}
+
+// checkDrift detects and reports the drift of the broker resources; when only detecting drift, a DriftError is returned if
+// the live state diverged.
+func checkDrift(ctx context.Context, client controllerClient.Client, options *BrokerOptions, status reporter.Interface) error {
+	status.Start("Comparing the live broker resources with the intended state")
+	defer status.End()
+
+	report, err := DetectDrift(ctx, client, options)
+	if err != nil {
+		return stepError(ctx, status, err, "detecting the broker drift", "error detecting the broker drift")
+	}
+
+	reportDrift(report, status)
+
+	if options.DetectDriftOnly && report.HasDrift() {
+		return status.Error(&DriftError{Report: report}, "")
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/subctl/internal/component"
+	"github.com/submariner-io/subctl/pkg/brokercr"
+	"github.com/submariner-io/subctl/pkg/deploy"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("DetectDrift", func() {
+	var (
+		client  controllerClient.Client
+		options *deploy.BrokerOptions
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		options, err = deploy.NewBrokerOptions()
+		Expect(err).To(Succeed())
+
+		testScheme := runtime.NewScheme()
+		Expect(scheme.AddToScheme(testScheme)).To(Succeed())
+		Expect(operatorv1alpha1.AddToScheme(testScheme)).To(Succeed())
+
+		configMap, err := globalnet.NewGlobalnetConfigMap(false, "", 0, options.BrokerNamespace)
+		Expect(err).To(Succeed())
+
+		client = fake.NewClientBuilder().WithScheme(testScheme).WithObjects(configMap,
+			brokercr.New(options.BrokerNamespace, options.BrokerSpec)).Build()
+	})
+
+	detect := func() *deploy.DriftReport {
+		report, err := deploy.DetectDrift(context.TODO(), client, options)
+		Expect(err).To(Succeed())
+
+		return report
+	}
+
+	When("the live resources match the intended ones", func() {
+		It("should report no-ops", func() {
+			report := detect()
+			Expect(report.HasDrift()).To(BeFalse())
+			Expect(report.Resources).To(HaveEach(HaveField("Action", deploy.DriftNoOp)))
+		})
+	})
+
+	When("the Broker resource doesn't exist", func() {
+		It("should report its creation", func() {
+			options.BrokerNamespace = "other-broker"
+			report := detect()
+			Expect(report.HasDrift()).To(BeTrue())
+			Expect(report.Resources).To(HaveEach(HaveField("Action", deploy.DriftCreate)))
+		})
+	})
+
+	When("the Broker spec differs", func() {
+		It("should report an update listing the differences", func() {
+			options.BrokerSpec.Components = []string{component.Connectivity}
+			report := detect()
+			Expect(report.HasDrift()).To(BeTrue())
+			Expect(report.Resources[0].Action).To(Equal(deploy.DriftUpdate))
+			Expect(report.Resources[0].Differences).To(ConsistOf(ContainSubstring("spec.components")))
+		})
+	})
+
+	When("globalnet is enabled on a broker deployed without it", func() {
+		It("should report an update of the globalnet ConfigMap", func() {
+			options.BrokerSpec.GlobalnetEnabled = true
+			report := detect()
+			Expect(report.Resources[1].Action).To(Equal(deploy.DriftUpdate))
+			Expect(report.Resources[1].Differences).To(ContainElement(ContainSubstring("data.globalnetEnabled")))
+		})
+	})
+})
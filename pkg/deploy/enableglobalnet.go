@@ -0,0 +1,216 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/pkg/brokercr"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
+	submarinerv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// EnableGlobalnet transitions the globalnet ConfigMap of a broker deployed with globalnet disabled to globalnet enabled with
+// the given CIDR range and default cluster size, without recreating the broker; the Broker resource is updated to match.
+// The clusters which already joined keep their connectivity settings: the transition is refused if any of their cluster or
+// service CIDRs overlap the globalnet CIDR range, or if the ConfigMap records allocations outside it. The joined clusters
+// must rejoin with globalnet to obtain a global CIDR.
+func EnableGlobalnet(ctx context.Context, client controllerClient.Client, brokerNamespace, cidrRange string, clusterSize uint,
+	status reporter.Interface,
+) error {
+	status.Start("Enabling globalnet on the broker in namespace %q", brokerNamespace)
+	defer status.End()
+
+	if err := globalnet.IsValidCIDR(cidrRange); err != nil {
+		return status.Error(err, "Invalid globalnet CIDR range")
+	}
+
+	clusterSize, err := globalnet.GetValidClusterSize(cidrRange, clusterSize)
+	if err != nil {
+		return status.Error(err, "Invalid globalnet cluster size")
+	}
+
+	globalnetInfo, _, err := globalnet.GetGlobalNetworks(ctx, client, brokerNamespace)
+	if err != nil {
+		return status.Error(err, "error reading the globalnet ConfigMap")
+	}
+
+	if globalnetInfo.Enabled {
+		if globalnetInfo.CidrRange != cidrRange {
+			return status.Error(fmt.Errorf("globalnet is already enabled with the CIDR range %s", globalnetInfo.CidrRange),
+				"Globalnet can't be enabled with a different CIDR range")
+		}
+
+		status.Success("Globalnet is already enabled with the CIDR range %s", cidrRange)
+
+		return nil
+	}
+
+	joined, err := checkJoinedClustersForGlobalnet(ctx, client, brokerNamespace, cidrRange, globalnetInfo)
+	if err != nil {
+		return status.Error(err, "Globalnet can't be enabled with the clusters which already joined")
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		return updateGlobalnetConfigMap(ctx, client, brokerNamespace, cidrRange, clusterSize)
+	})
+	if err != nil {
+		return status.Error(err, "error updating the globalnet ConfigMap")
+	}
+
+	status.Success("The globalnet ConfigMap now records globalnet as enabled with the CIDR range %s and a default cluster "+
+		"size of %d", cidrRange, clusterSize)
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		return updateBrokerGlobalnet(ctx, client, brokerNamespace, cidrRange, clusterSize)
+	})
+	if err != nil {
+		return status.Error(err, "error updating the Broker resource")
+	}
+
+	if len(joined) > 0 {
+		status.Warning("The cluster(s) %s joined without globalnet and have no global CIDR; rejoin them with globalnet to use it",
+			strings.Join(joined, ", "))
+	}
+
+	return nil
+}
+
+// checkJoinedClustersForGlobalnet returns the clusters which joined the broker, after checking that their networks and the
+// recorded allocations don't conflict with the globalnet CIDR range.
+func checkJoinedClustersForGlobalnet(ctx context.Context, client controllerClient.Client, brokerNamespace, cidrRange string,
+	globalnetInfo *globalnet.Info,
+) ([]string, error) {
+	_, globalnetRange, err := net.ParseCIDR(cidrRange)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid globalnet CIDR range %q", cidrRange)
+	}
+
+	conflicts := []string{}
+
+	for clusterID, network := range globalnetInfo.CidrInfo {
+		for _, cidr := range network.GlobalCIDRs {
+			if !cidrWithin(cidr, globalnetRange) {
+				conflicts = append(conflicts, fmt.Sprintf("the global CIDR %s recorded for %q is outside the range", cidr, clusterID))
+			}
+		}
+	}
+
+	clusters := &submarinerv1.ClusterList{}
+
+	if err := client.List(ctx, clusters, controllerClient.InNamespace(brokerNamespace)); err != nil {
+		return nil, errors.Wrap(err, "error listing the joined clusters")
+	}
+
+	joined := make([]string, 0, len(clusters.Items))
+
+	for i := range clusters.Items {
+		spec := &clusters.Items[i].Spec
+		joined = append(joined, spec.ClusterID)
+
+		for _, cidr := range append(append([]string{}, spec.ClusterCIDR...), spec.ServiceCIDR...) {
+			if cidrsOverlap(cidr, globalnetRange) {
+				conflicts = append(conflicts, fmt.Sprintf("cluster %q uses %s, which overlaps the range", spec.ClusterID, cidr))
+			}
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("the globalnet CIDR range %s conflicts with the existing clusters: %s", cidrRange,
+			strings.Join(conflicts, "; "))
+	}
+
+	return joined, nil
+}
+
+func cidrWithin(cidr string, cidrRange *net.IPNet) bool {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return false
+	}
+
+	networkOnes, _ := network.Mask.Size()
+	rangeOnes, _ := cidrRange.Mask.Size()
+
+	return cidrRange.Contains(network.IP) && networkOnes >= rangeOnes
+}
+
+func cidrsOverlap(cidr string, cidrRange *net.IPNet) bool {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return false
+	}
+
+	return network.Contains(cidrRange.IP) || cidrRange.Contains(network.IP)
+}
+
+// updateGlobalnetConfigMap switches the existing ConfigMap to globalnet enabled, keeping its cluster allocations.
+func updateGlobalnetConfigMap(ctx context.Context, client controllerClient.Client, brokerNamespace, cidrRange string,
+	clusterSize uint,
+) error {
+	configMap, err := globalnet.GetConfigMap(ctx, client, brokerNamespace)
+	if err != nil {
+		return err //nolint:wrapcheck // No need to wrap here
+	}
+
+	enabled, err := globalnet.NewGlobalnetConfigMap(true, cidrRange, clusterSize, brokerNamespace)
+	if err != nil {
+		return err //nolint:wrapcheck // No need to wrap here
+	}
+
+	for key, value := range enabled.Data {
+		if key == globalnetClusterInfoKey && configMap.Data[key] != "" {
+			continue
+		}
+
+		configMap.Data[key] = value
+	}
+
+	return client.Update(ctx, configMap) //nolint:wrapcheck // No need to wrap here
+}
+
+func updateBrokerGlobalnet(ctx context.Context, client controllerClient.Client, brokerNamespace, cidrRange string,
+	clusterSize uint,
+) error {
+	broker := &operatorv1alpha1.Broker{}
+
+	err := client.Get(ctx, controllerClient.ObjectKey{Namespace: brokerNamespace, Name: brokercr.Name}, broker)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err //nolint:wrapcheck // No need to wrap here
+	}
+
+	broker.Spec.GlobalnetEnabled = true
+	broker.Spec.GlobalnetCIDRRange = cidrRange
+	broker.Spec.DefaultGlobalnetClusterSize = clusterSize
+
+	return client.Update(ctx, broker) //nolint:wrapcheck // No need to wrap here
+}
@@ -0,0 +1,103 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/pkg/brokercr"
+	"github.com/submariner-io/subctl/pkg/deploy"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
+	submarinerv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("EnableGlobalnet", func() {
+	const brokerNamespace = "submariner-k8s-broker"
+
+	var client controllerClient.Client
+
+	BeforeEach(func() {
+		testScheme := runtime.NewScheme()
+		Expect(scheme.AddToScheme(testScheme)).To(Succeed())
+		Expect(submarinerv1.AddToScheme(testScheme)).To(Succeed())
+		Expect(operatorv1alpha1.AddToScheme(testScheme)).To(Succeed())
+
+		configMap, err := globalnet.NewGlobalnetConfigMap(false, "", 0, brokerNamespace)
+		Expect(err).To(Succeed())
+
+		client = fake.NewClientBuilder().WithScheme(testScheme).WithObjects(configMap,
+			brokercr.New(brokerNamespace, operatorv1alpha1.BrokerSpec{})).Build()
+	})
+
+	enable := func() error {
+		return deploy.EnableGlobalnet(context.TODO(), client, brokerNamespace, "242.0.0.0/8", 65536, reporter.Silent())
+	}
+
+	When("no cluster has joined", func() {
+		It("should record globalnet as enabled", func() {
+			Expect(enable()).To(Succeed())
+
+			globalnetInfo, _, err := globalnet.GetGlobalNetworks(context.TODO(), client, brokerNamespace)
+			Expect(err).To(Succeed())
+			Expect(globalnetInfo.Enabled).To(BeTrue())
+			Expect(globalnetInfo.CidrRange).To(Equal("242.0.0.0/8"))
+			Expect(globalnetInfo.ClusterSize).To(Equal(uint(65536)))
+
+			broker := &operatorv1alpha1.Broker{}
+			Expect(client.Get(context.TODO(), controllerClient.ObjectKey{Namespace: brokerNamespace, Name: brokercr.Name},
+				broker)).To(Succeed())
+			Expect(broker.Spec.GlobalnetEnabled).To(BeTrue())
+			Expect(broker.Spec.GlobalnetCIDRRange).To(Equal("242.0.0.0/8"))
+		})
+	})
+
+	When("a joined cluster's CIDRs don't overlap the globalnet range", func() {
+		It("should succeed", func() {
+			Expect(client.Create(context.TODO(), newJoinedCluster(brokerNamespace, "east", "10.0.0.0/16"))).To(Succeed())
+			Expect(enable()).To(Succeed())
+		})
+	})
+
+	When("a joined cluster's CIDRs overlap the globalnet range", func() {
+		It("should return an error naming the cluster", func() {
+			Expect(client.Create(context.TODO(), newJoinedCluster(brokerNamespace, "east", "242.1.0.0/16"))).To(Succeed())
+			Expect(enable()).To(MatchError(ContainSubstring(`cluster "east" uses 242.1.0.0/16`)))
+		})
+	})
+})
+
+func newJoinedCluster(namespace, clusterID, clusterCIDR string) *submarinerv1.Cluster {
+	return &submarinerv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterID, Namespace: namespace},
+		Spec: submarinerv1.ClusterSpec{
+			ClusterID:   clusterID,
+			ClusterCIDR: []string{clusterCIDR},
+			ServiceCIDR: []string{"100.64.0.0/16"},
+		},
+	}
+}
@@ -0,0 +1,100 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ExperimentalComponentsAnnotation lists, on the Broker resource, the experimental components it was deployed with.
+const ExperimentalComponentsAnnotation = "submariner.io/experimental-components"
+
+// ExperimentalComponents maps the components which aren't generally available yet to the feature gate enabling each of them.
+// A gated component is only valid when its gate is enabled.
+var ExperimentalComponents = map[string]string{}
+
+// FeatureGates returns the names of the available feature gates, sorted.
+func FeatureGates() []string {
+	gates := sets.New[string]()
+
+	for _, gate := range ExperimentalComponents {
+		gates.Insert(gate)
+	}
+
+	return sets.List(gates)
+}
+
+func checkFeatureGates(featureGates []string) error {
+	unknown := sets.New(featureGates...).Difference(sets.New(FeatureGates()...))
+	if unknown.Len() == 0 {
+		return nil
+	}
+
+	available := "none are available"
+	if gates := FeatureGates(); len(gates) > 0 {
+		available = "the available gates are " + strings.Join(gates, ", ")
+	}
+
+	return fmt.Errorf("unknown feature gate(s) %s; %s", strings.Join(sets.List(unknown), ", "), available)
+}
+
+// gatedComponentsError returns an error pointing at the gates to enable for the requested experimental components, if any
+// aren't enabled.
+func gatedComponentsError(components sets.Set[string], featureGates []string) error {
+	enabled := sets.New(featureGates...)
+	missing := []string{}
+
+	for _, component := range sets.List(components) {
+		if gate, found := ExperimentalComponents[component]; found && !enabled.Has(gate) {
+			missing = append(missing, fmt.Sprintf("%s (enable the feature gate %q)", component, gate))
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("experimental component(s) requested without their feature gate: %s", strings.Join(missing, ", "))
+}
+
+// experimentalComponents returns the experimental components among those requested.
+func (options *BrokerOptions) experimentalComponents() []string {
+	experimental := []string{}
+
+	for _, component := range sets.List(sets.New(options.BrokerSpec.Components...)) {
+		if _, found := ExperimentalComponents[component]; found {
+			experimental = append(experimental, component)
+		}
+	}
+
+	return experimental
+}
+
+// brokerAnnotations returns the annotations recording the experimental status of the Broker resource, if any.
+func (options *BrokerOptions) brokerAnnotations() map[string]string {
+	experimental := options.experimentalComponents()
+	if len(experimental) == 0 {
+		return nil
+	}
+
+	return map[string]string{ExperimentalComponentsAnnotation: strings.Join(experimental, ",")}
+}
@@ -0,0 +1,277 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/pkg/cluster"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The globalnet ConfigMap key holding the per-cluster allocations, and the format of its entries.
+const globalnetClusterInfoKey = "clusterinfo"
+
+// Excluded CIDRs are recorded as allocations to pseudo-clusters, so that they're never handed out to joining clusters.
+// The prefix isn't valid in cluster IDs, preventing any clash with real clusters.
+const excludedCIDRPrefix = "excluded:"
+
+type clusterGlobalCIDRs struct {
+	ClusterID  string   `json:"cluster_id"`
+	GlobalCidr []string `json:"global_cidr"`
+}
+
+// allocateClusterGlobalCIDRs pre-allocates global CIDRs for the clusters listed in GlobalnetClusterSizes,
+// in cluster ID order, after reserving the GlobalnetExcludedCIDRs. The allocations are seeded in the globalnet
+// ConfigMap, so that the clusters obtain them when they join and the excluded CIDRs are never allocated.
+func allocateClusterGlobalCIDRs(options *BrokerOptions) ([]clusterGlobalCIDRs, error) {
+	if len(options.GlobalnetClusterSizes) == 0 && len(options.GlobalnetExcludedCIDRs) == 0 {
+		return nil, nil
+	}
+
+	if !options.BrokerSpec.GlobalnetEnabled {
+		return nil, errors.New("per-cluster globalnet sizes and excluded CIDRs can only be specified when globalnet is enabled")
+	}
+
+	_, cidrRange, err := net.ParseCIDR(options.BrokerSpec.GlobalnetCIDRRange)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid globalnet CIDR range %q", options.BrokerSpec.GlobalnetCIDRRange)
+	}
+
+	totalSize := cidrSize(cidrRange)
+
+	allocations, err := excludeGlobalCIDRs(cidrRange, options.GlobalnetExcludedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterIDs := make([]string, 0, len(options.GlobalnetClusterSizes))
+	for clusterID := range options.GlobalnetClusterSizes {
+		clusterIDs = append(clusterIDs, clusterID)
+	}
+
+	sort.Strings(clusterIDs)
+
+	globalnetInfo := &globalnet.Info{
+		Enabled:   true,
+		CidrRange: options.BrokerSpec.GlobalnetCIDRRange,
+		CidrInfo:  map[string]*globalnet.GlobalNetwork{},
+	}
+
+	allocatedSize := uint(0)
+
+	for i := range allocations {
+		globalnetInfo.CidrInfo[allocations[i].ClusterID] = &globalnet.GlobalNetwork{
+			ClusterID: allocations[i].ClusterID, GlobalCIDRs: allocations[i].GlobalCidr,
+		}
+
+		_, excluded, _ := net.ParseCIDR(allocations[i].GlobalCidr[0])
+		allocatedSize += cidrSize(excluded)
+	}
+
+	for _, clusterID := range clusterIDs {
+		if err := cluster.IsValidID(clusterID); err != nil {
+			return nil, err //nolint:wrapcheck // No need to wrap here
+		}
+
+		clusterSize, err := globalnet.GetValidClusterSize(options.BrokerSpec.GlobalnetCIDRRange, options.GlobalnetClusterSizes[clusterID])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid globalnet size for cluster %q", clusterID)
+		}
+
+		if allocatedSize+clusterSize > totalSize {
+			return nil, fmt.Errorf("the globalnet size %d requested for cluster %q overflows the globalnet CIDR range %s: "+
+				"%d of its %d addresses are already allocated to other clusters or excluded", clusterSize, clusterID,
+				options.BrokerSpec.GlobalnetCIDRRange, allocatedSize, totalSize)
+		}
+
+		globalnetInfo.ClusterSize = clusterSize
+
+		globalCIDR, err := globalnet.AllocateGlobalCIDR(globalnetInfo)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to allocate a global CIDR of size %d for cluster %q in %s", clusterSize, clusterID,
+				options.BrokerSpec.GlobalnetCIDRRange)
+		}
+
+		globalnetInfo.CidrInfo[clusterID] = &globalnet.GlobalNetwork{ClusterID: clusterID, GlobalCIDRs: []string{globalCIDR}}
+		allocations = append(allocations, clusterGlobalCIDRs{ClusterID: clusterID, GlobalCidr: []string{globalCIDR}})
+		allocatedSize += clusterSize
+	}
+
+	return allocations, nil
+}
+
+// excludeGlobalCIDRs validates the CIDRs excluded from the globalnet CIDR range, which must be within it and mustn't
+// overlap, and returns their reservations.
+func excludeGlobalCIDRs(cidrRange *net.IPNet, excludedCIDRs []string) ([]clusterGlobalCIDRs, error) {
+	reservations := make([]clusterGlobalCIDRs, 0, len(excludedCIDRs))
+	excludedNets := make([]*net.IPNet, 0, len(excludedCIDRs))
+
+	for _, cidr := range excludedCIDRs {
+		_, excluded, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid excluded globalnet CIDR %q", cidr)
+		}
+
+		if excluded.String() != cidr {
+			return nil, fmt.Errorf("the excluded globalnet CIDR %q isn't a network address, did you mean %q?", cidr, excluded)
+		}
+
+		excludedOnes, _ := excluded.Mask.Size()
+		rangeOnes, _ := cidrRange.Mask.Size()
+
+		if !cidrRange.Contains(excluded.IP) || excludedOnes < rangeOnes {
+			return nil, fmt.Errorf("the excluded CIDR %s isn't within the globalnet CIDR range %s", cidr, cidrRange)
+		}
+
+		for _, other := range excludedNets {
+			if other.Contains(excluded.IP) || excluded.Contains(other.IP) {
+				return nil, fmt.Errorf("the excluded CIDRs %s and %s overlap", other, cidr)
+			}
+		}
+
+		excludedNets = append(excludedNets, excluded)
+		reservations = append(reservations, clusterGlobalCIDRs{ClusterID: excludedCIDRPrefix + cidr, GlobalCidr: []string{cidr}})
+	}
+
+	return reservations, nil
+}
+
+// allocatableGlobalnetSize returns the number of addresses in the globalnet CIDR range, and how many remain allocatable
+// once the excluded CIDRs are reserved.
+func allocatableGlobalnetSize(options *BrokerOptions) (total, allocatable uint) {
+	_, cidrRange, err := net.ParseCIDR(options.BrokerSpec.GlobalnetCIDRRange)
+	if err != nil {
+		return 0, 0
+	}
+
+	total = cidrSize(cidrRange)
+	allocatable = total
+
+	for _, cidr := range options.GlobalnetExcludedCIDRs {
+		if _, excluded, err := net.ParseCIDR(cidr); err == nil {
+			allocatable -= cidrSize(excluded)
+		}
+	}
+
+	return total, allocatable
+}
+
+func cidrSize(cidr *net.IPNet) uint {
+	ones, totalBits := cidr.Mask.Size()
+	return uint(1) << uint(totalBits-ones)
+}
+
+func newGlobalnetConfigMap(options *BrokerOptions, allocations []clusterGlobalCIDRs) (*corev1.ConfigMap, error) {
+	configMap, err := globalnet.NewGlobalnetConfigMap(options.BrokerSpec.GlobalnetEnabled, options.BrokerSpec.GlobalnetCIDRRange,
+		options.BrokerSpec.DefaultGlobalnetClusterSize, options.BrokerNamespace)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // No need to wrap here
+	}
+
+	if len(allocations) > 0 {
+		data, err := json.MarshalIndent(allocations, "", "\t")
+		if err != nil {
+			return nil, errors.Wrap(err, "error marshalling the cluster globalnet allocations")
+		}
+
+		configMap.Data[globalnetClusterInfoKey] = string(data)
+	}
+
+	return configMap, nil
+}
+
+// createGlobalnetConfigMap creates the globalnet ConfigMap, which is always present on a broker: with globalnet disabled,
+// it records globalnetEnabled as "false", without a CIDR range or cluster size, and no allocations. An existing ConfigMap
+// is kept, except that one recording globalnet as disabled is transitioned with EnableGlobalnet when globalnet is requested.
+func createGlobalnetConfigMap(ctx context.Context, client controllerClient.Client, options *BrokerOptions,
+	allocations []clusterGlobalCIDRs, status reporter.Interface,
+) error {
+	configMap, err := newGlobalnetConfigMap(options, allocations)
+	if err != nil {
+		return errors.Wrap(err, "error creating config map")
+	}
+
+	err = client.Create(ctx, configMap)
+	if apierrors.IsAlreadyExists(err) {
+		return reconcileGlobalnetConfigMap(ctx, client, options, allocations, status)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "error creating ConfigMap")
+	}
+
+	if !options.BrokerSpec.GlobalnetEnabled {
+		status.Success("The globalnet ConfigMap records globalnet as disabled")
+	}
+
+	for i := range allocations {
+		if strings.HasPrefix(allocations[i].ClusterID, excludedCIDRPrefix) {
+			status.Success("Excluded global CIDR %s from the allocations", allocations[i].GlobalCidr[0])
+		} else {
+			status.Success("Allocated global CIDR %s to cluster %q", allocations[i].GlobalCidr[0], allocations[i].ClusterID)
+		}
+	}
+
+	if len(options.GlobalnetExcludedCIDRs) > 0 {
+		total, allocatable := allocatableGlobalnetSize(options)
+		status.Success("%d of the %d addresses in the globalnet CIDR range %s remain allocatable", allocatable, total,
+			options.BrokerSpec.GlobalnetCIDRRange)
+	}
+
+	return nil
+}
+
+func reconcileGlobalnetConfigMap(ctx context.Context, client controllerClient.Client, options *BrokerOptions,
+	allocations []clusterGlobalCIDRs, status reporter.Interface,
+) error {
+	existing, _, err := globalnet.GetGlobalNetworks(ctx, client, options.BrokerNamespace)
+	if err != nil {
+		return errors.Wrap(err, "error reading the existing globalnet ConfigMap")
+	}
+
+	switch {
+	case options.BrokerSpec.GlobalnetEnabled && !existing.Enabled:
+		if len(allocations) > 0 {
+			status.Warning("The per-cluster globalnet sizes and excluded CIDRs aren't applied when enabling globalnet on an " +
+				"existing broker")
+		}
+
+		return EnableGlobalnet(ctx, client, options.BrokerNamespace, options.BrokerSpec.GlobalnetCIDRRange,
+			options.BrokerSpec.DefaultGlobalnetClusterSize, status)
+	case !options.BrokerSpec.GlobalnetEnabled && existing.Enabled:
+		status.Warning("The existing globalnet ConfigMap records globalnet as enabled with the CIDR range %s; globalnet can't "+
+			"be disabled on an existing broker, it remains enabled for the joining clusters", existing.CidrRange)
+	case len(allocations) > 0:
+		status.Warning("The globalCIDR configmap already exists, the per-cluster globalnet sizes and excluded CIDRs " +
+			"were not applied")
+	}
+
+	return nil
+}
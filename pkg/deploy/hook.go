@@ -0,0 +1,81 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/submariner-io/admiral/pkg/reporter"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+)
+
+// DeployedBroker describes the broker once it's deployed, as passed to the post-deploy hook.
+type DeployedBroker struct {
+	Namespace         string
+	OperatorNamespace string
+	Spec              operatorv1alpha1.BrokerSpec
+	// JoinInfoFile is the file to which the join information was written, empty if it wasn't exported.
+	JoinInfoFile string
+}
+
+// PostDeployHook is run once the broker is successfully deployed.
+type PostDeployHook func(ctx context.Context, deployed *DeployedBroker) error
+
+// PostDeployHookError is returned when the broker was deployed, but the post-deploy hook failed.
+type PostDeployHookError struct {
+	Err error
+}
+
+func (e *PostDeployHookError) Error() string {
+	return fmt.Sprintf("the broker was deployed, but the post-deploy hook failed: %v", e.Err)
+}
+
+func (e *PostDeployHookError) Unwrap() error {
+	return e.Err
+}
+
+// runPostDeployHook runs the hook, if any; failures are returned as PostDeployHookErrors, the broker is left deployed.
+func runPostDeployHook(ctx context.Context, options *BrokerOptions, status reporter.Interface) error {
+	if options.PostDeployHook == nil {
+		return nil
+	}
+
+	status.Start("Running the post-deploy hook")
+	defer status.End()
+
+	deployed := &DeployedBroker{
+		Namespace:         options.BrokerNamespace,
+		OperatorNamespace: options.operatorNamespace(),
+		Spec:              options.BrokerSpec,
+	}
+
+	if options.JoinInfo != nil {
+		deployed.JoinInfoFile = options.JoinInfo.fileName()
+	}
+
+	if err := options.PostDeployHook(ctx, deployed); err != nil {
+		return status.Error(&PostDeployHookError{Err: err}, "The broker is deployed and is left in place, only the post-deploy "+
+			"hook failed")
+	}
+
+	status.Success("The post-deploy hook completed")
+
+	return nil
+}
@@ -0,0 +1,71 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/subctl/internal/component"
+	"github.com/submariner-io/subctl/pkg/broker"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// checkIPsecSettings validates the IPsec settings given to the joining clusters: the NAT-T port must be a valid port, the
+// encapsulation a known one, and both must be consistent.
+func checkIPsecSettings(options *BrokerOptions) error {
+	ipsec := options.IPsec
+	if !ipsec.IsSet() {
+		return nil
+	}
+
+	if !sets.New(options.BrokerSpec.Components...).Has(component.Connectivity) {
+		return fmt.Errorf("the IPsec settings only apply to the %s component, which isn't deployed", component.Connectivity)
+	}
+
+	if ipsec.NATTPort < 0 || ipsec.NATTPort > 65535 {
+		return fmt.Errorf("the NAT-T port %d is out of range, it must be between 1 and 65535", ipsec.NATTPort)
+	}
+
+	if ipsec.Encapsulation != "" && !sets.New(broker.Encapsulations...).Has(ipsec.Encapsulation) {
+		return fmt.Errorf("unknown encapsulation %q, it must be one of %s", ipsec.Encapsulation,
+			strings.Join(broker.Encapsulations, ", "))
+	}
+
+	if ipsec.Encapsulation == broker.EncapsulationESP && ipsec.NATTPort != 0 {
+		return errors.New("a NAT-T port can't be set with the esp encapsulation, which disables NAT traversal")
+	}
+
+	return nil
+}
+
+func describeIPsecSettings(ipsec broker.IPsecSettings) string {
+	settings := []string{}
+
+	if ipsec.NATTPort != 0 {
+		settings = append(settings, fmt.Sprintf("NAT-T port %d", ipsec.NATTPort))
+	}
+
+	if ipsec.Encapsulation != "" {
+		settings = append(settings, fmt.Sprintf("%s encapsulation", ipsec.Encapsulation))
+	}
+
+	return strings.Join(settings, ", ")
+}
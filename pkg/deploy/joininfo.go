@@ -0,0 +1,83 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/pkg/broker"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+)
+
+// JoinInfoExport describes the file to which the information needed to join the broker is written once it's deployed,
+// in the format consumed by the join command.
+type JoinInfoExport struct {
+	// File is the path of the file, by default broker.InfoFileName.
+	File string
+	// BrokerURL is the URL of the broker's API server, as given to the joining clusters.
+	BrokerURL string
+	// IPsecPSKFile, if set, is an existing broker information file whose IPsec PSK is reused.
+	IPsecPSKFile string
+}
+
+// JoinInfoExportError is returned when the broker was deployed, but its join information couldn't be written.
+type JoinInfoExportError struct {
+	File string
+	Err  error
+}
+
+func (e *JoinInfoExportError) Error() string {
+	return fmt.Sprintf("the broker was deployed, but writing its join information to %q failed: %v", e.File, e.Err)
+}
+
+func (e *JoinInfoExportError) Unwrap() error {
+	return e.Err
+}
+
+func (export *JoinInfoExport) fileName() string {
+	if export.File == "" {
+		return broker.InfoFileName
+	}
+
+	return export.File
+}
+
+// exportJoinInfo writes the join information, if requested; failures are returned as JoinInfoExportErrors.
+func exportJoinInfo(ctx context.Context, kubeClient kubernetes.Interface, options *BrokerOptions, status reporter.Interface) error {
+	if options.JoinInfo == nil {
+		return nil
+	}
+
+	fileName := options.JoinInfo.fileName()
+
+	err := broker.WriteInfo(ctx, kubeClient, options.JoinInfo.BrokerURL, fileName, options.BrokerNamespace,
+		options.JoinInfo.IPsecPSKFile, sets.New(options.BrokerSpec.Components...), options.BrokerSpec.DefaultCustomDomains,
+		broker.WriteSettings{IPsec: options.IPsec, TokenTTL: options.TokenTTL}, status)
+	if err != nil {
+		status.Warning("The broker is deployed, only writing its join information failed; fix the cause and re-run the " +
+			"deployment to write it")
+
+		return &JoinInfoExportError{File: fileName, Err: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// The keys in Submariner's domain are reserved, so that the custom metadata can't clobber the labels Submariner relies on.
+const reservedKeyDomain = "submariner.io"
+
+// checkBrokerMetadata validates the custom labels and annotations applied to the broker namespace and Broker resource.
+func checkBrokerMetadata(options *BrokerOptions) error {
+	if errs := metav1validation.ValidateLabels(options.NamespaceLabels, field.NewPath("namespaceLabels")); len(errs) > 0 {
+		return errors.Wrap(errs.ToAggregate(), "invalid broker namespace labels")
+	}
+
+	if errs := apivalidation.ValidateAnnotations(options.NamespaceAnnotations, field.NewPath("namespaceAnnotations")); len(errs) > 0 {
+		return errors.Wrap(errs.ToAggregate(), "invalid broker namespace annotations")
+	}
+
+	if errs := metav1validation.ValidateLabels(options.BrokerLabels, field.NewPath("brokerLabels")); len(errs) > 0 {
+		return errors.Wrap(errs.ToAggregate(), "invalid Broker resource labels")
+	}
+
+	for _, metadata := range []struct {
+		desc string
+		keys map[string]string
+	}{
+		{"broker namespace label", options.NamespaceLabels},
+		{"broker namespace annotation", options.NamespaceAnnotations},
+		{"Broker resource label", options.BrokerLabels},
+	} {
+		if reserved := reservedKeys(metadata.keys); len(reserved) > 0 {
+			return fmt.Errorf("the %s key(s) %s are reserved for Submariner's own use, choose keys outside the %q domain",
+				metadata.desc, strings.Join(reserved, ", "), reservedKeyDomain)
+		}
+	}
+
+	if options.SkipRBAC && (len(options.NamespaceLabels) > 0 || len(options.NamespaceAnnotations) > 0) {
+		return errors.New("the broker namespace labels and annotations can't be applied when the broker namespace is managed " +
+			"externally with the RBAC")
+	}
+
+	return nil
+}
+
+func reservedKeys(metadata map[string]string) []string {
+	reserved := sets.New[string]()
+
+	for key := range metadata {
+		prefix, _, found := strings.Cut(key, "/")
+		if found && (prefix == reservedKeyDomain || strings.HasSuffix(prefix, "."+reservedKeyDomain)) {
+			reserved.Insert(key)
+		}
+	}
+
+	return sets.List(reserved)
+}
+
+func reportBrokerMetadata(options *BrokerOptions, verb string, status reporter.Interface) {
+	if len(options.NamespaceLabels) > 0 {
+		status.Success("%s the labels %q to the broker namespace", verb, labels.SelectorFromSet(options.NamespaceLabels).String())
+	}
+
+	if len(options.NamespaceAnnotations) > 0 {
+		status.Success("%s %d annotation(s) to the broker namespace", verb, len(options.NamespaceAnnotations))
+	}
+
+	if len(options.BrokerLabels) > 0 {
+		status.Success("%s the labels %q to the Broker resource", verb, labels.SelectorFromSet(options.BrokerLabels).String())
+	}
+}
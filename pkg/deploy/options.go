@@ -0,0 +1,147 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"github.com/pkg/errors"
+	"github.com/submariner-io/subctl/internal/component"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/pkg/broker"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
+)
+
+// BrokerOption modifies the BrokerOptions built by NewBrokerOptions.
+type BrokerOption func(*BrokerOptions)
+
+// WithBrokerNamespace sets the namespace in which the broker is deployed.
+func WithBrokerNamespace(namespace string) BrokerOption {
+	return func(options *BrokerOptions) {
+		options.BrokerNamespace = namespace
+	}
+}
+
+// WithComponents sets the components deployed on the broker.
+func WithComponents(components ...string) BrokerOption {
+	return func(options *BrokerOptions) {
+		options.BrokerSpec.Components = components
+	}
+}
+
+// WithGlobalnet enables globalnet, using the given CIDR range and default cluster size.
+func WithGlobalnet(cidrRange string, clusterSize uint) BrokerOption {
+	return func(options *BrokerOptions) {
+		options.BrokerSpec.GlobalnetEnabled = true
+		options.BrokerSpec.GlobalnetCIDRRange = cidrRange
+		options.BrokerSpec.DefaultGlobalnetClusterSize = clusterSize
+	}
+}
+
+// WithIPsec sets the IPsec NAT-T port and preferred ESP encapsulation picked up by the joining clusters.
+func WithIPsec(nattPort int, encapsulation string) BrokerOption {
+	return func(options *BrokerOptions) {
+		options.IPsec = broker.IPsecSettings{NATTPort: nattPort, Encapsulation: encapsulation}
+	}
+}
+
+// WithImages sets the repository and version of the images deployed.
+func WithImages(repository, version string) BrokerOption {
+	return func(options *BrokerOptions) {
+		options.Repository = repository
+		options.ImageVersion = version
+	}
+}
+
+// WithPostDeployHook sets the hook run once the broker is deployed.
+func WithPostDeployHook(hook PostDeployHook) BrokerOption {
+	return func(options *BrokerOptions) {
+		options.PostDeployHook = hook
+	}
+}
+
+// NewBrokerOptions returns broker options using the default namespace, the connectivity and service discovery components,
+// and the default globalnet settings, with the given modifications applied. The resulting options are validated.
+func NewBrokerOptions(modifiers ...BrokerOption) (*BrokerOptions, error) {
+	options := &BrokerOptions{
+		BrokerNamespace: constants.DefaultBrokerNamespace,
+		Retry: RetryConfig{
+			MaxAttempts:     5,
+			InitialInterval: defaultRetryInterval,
+		},
+	}
+
+	options.BrokerSpec.Components = []string{component.ServiceDiscovery, component.Connectivity}
+	options.BrokerSpec.GlobalnetCIDRRange = globalnet.DefaultGlobalnetCIDR
+	options.BrokerSpec.DefaultGlobalnetClusterSize = globalnet.DefaultGlobalnetClusterSize
+
+	for _, modify := range modifiers {
+		modify(options)
+	}
+
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
+
+func (options *BrokerOptions) validate() error {
+	if options.BrokerNamespace == "" {
+		return errors.New("the broker namespace can't be empty")
+	}
+
+	if err := checkFeatureGates(options.FeatureGates); err != nil {
+		return err
+	}
+
+	if err := ValidateComponents(options.BrokerSpec.Components, options.FeatureGates...); err != nil {
+		return err
+	}
+
+	if err := checkOperatorNamespace(options); err != nil {
+		return err
+	}
+
+	if err := checkOperatorPlacement(options); err != nil {
+		return err
+	}
+
+	if err := checkBrokerMetadata(options); err != nil {
+		return err
+	}
+
+	if err := checkIPsecSettings(options); err != nil {
+		return errors.Wrap(err, "invalid IPsec settings")
+	}
+
+	if err := checkTokenTTL(options.TokenTTL); err != nil {
+		return errors.Wrap(err, "invalid token TTL")
+	}
+
+	if options.BrokerSpec.GlobalnetEnabled && options.BrokerSpec.GlobalnetCIDRRange == "" {
+		return errors.New("globalnet is enabled but no globalnet CIDR range was provided")
+	}
+
+	if err := checkGlobalnetConfig(options); err != nil {
+		return errors.Wrap(err, "invalid GlobalCIDR configuration")
+	}
+
+	_, err := allocateClusterGlobalCIDRs(options)
+
+	return err
+}
@@ -0,0 +1,101 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	goerrors "errors"
+	"syscall"
+	"time"
+
+	"github.com/submariner-io/admiral/pkg/reporter"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// RetryConfig controls how transient failures are retried during deployment.
+// A zero MaxAttempts means the operation is only attempted once.
+type RetryConfig struct {
+	MaxAttempts     int
+	InitialInterval time.Duration
+}
+
+const defaultRetryInterval = 2 * time.Second
+
+// isRetryable returns true if the given error is a transient API server error which is worth retrying.
+func isRetryable(err error) bool {
+	return apierrors.IsServerTimeout(err) || apierrors.IsTooManyRequests(err) || goerrors.Is(err, syscall.ECONNREFUSED) ||
+		goerrors.Is(err, syscall.ECONNRESET)
+}
+
+// withRetry starts a status phase describing the step and runs the function, retrying it with exponential
+// backoff as long as it fails with a retryable error and attempts remain. The backoff is interrupted, returning the context's
+// error, if the context is done.
+func withRetry(ctx context.Context, config RetryConfig, status reporter.Interface, step string, function func() error) error {
+	attempts := config.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	interval := config.InitialInterval
+	if interval <= 0 {
+		interval = defaultRetryInterval
+	}
+
+	backoff := wait.Backoff{
+		Steps:    attempts,
+		Duration: interval,
+		Factor:   2,
+		Jitter:   0.1,
+	}
+
+	attempt := 0
+
+	var lastErr error
+
+	status.Start(step)
+
+	err := wait.ExponentialBackoffWithContext(ctx, backoff, func() (bool, error) {
+		attempt++
+
+		if attempt > 1 {
+			status.Start("%s (attempt %d of %d)", step, attempt, attempts)
+		}
+
+		lastErr = function()
+		if lastErr == nil {
+			return true, nil
+		}
+
+		if !isRetryable(lastErr) || attempt == attempts {
+			return false, lastErr
+		}
+
+		status.Warning("Attempt %d of %d failed with a transient error, retrying: %v", attempt, attempts, lastErr)
+		status.End()
+
+		return false, nil
+	})
+
+	if goerrors.Is(err, wait.ErrWaitTimeout) {
+		return lastErr
+	}
+
+	return err //nolint:wrapcheck // No need to wrap here
+}
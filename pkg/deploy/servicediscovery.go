@@ -39,7 +39,17 @@ type ServiceDiscoveryOptions struct {
 	CoreDNSCustomConfigMap string
 	Repository             string
 	ImageVersion           string
-	CustomDomains          []string
+	// OperatorNamespace is the namespace in which the operator is deployed, by default constants.OperatorNamespace.
+	OperatorNamespace string
+	CustomDomains     []string
+}
+
+func (options *ServiceDiscoveryOptions) operatorNamespace() string {
+	if options.OperatorNamespace == "" {
+		return constants.OperatorNamespace
+	}
+
+	return options.OperatorNamespace
 }
 
 func ServiceDiscovery(ctx context.Context, clientProducer client.Producer, options *ServiceDiscoveryOptions, brokerInfo *broker.Info,
@@ -47,7 +57,7 @@ func ServiceDiscovery(ctx context.Context, clientProducer client.Producer, optio
 ) error {
 	serviceDiscoverySpec := populateServiceDiscoverySpec(options, brokerInfo, brokerSecret, repositoryInfo)
 
-	err := servicediscoverycr.Ensure(ctx, clientProducer.ForGeneral(), constants.OperatorNamespace, serviceDiscoverySpec)
+	err := servicediscoverycr.Ensure(ctx, clientProducer.ForGeneral(), options.operatorNamespace(), serviceDiscoverySpec)
 	if err != nil {
 		return status.Error(err, "Service discovery deployment failed")
 	}
@@ -71,7 +81,7 @@ func populateServiceDiscoverySpec(options *ServiceDiscoveryOptions, brokerInfo *
 		BrokerK8sInsecure:        options.BrokerK8sInsecure,
 		Debug:                    options.SubmarinerDebug,
 		ClusterID:                options.ClusterID,
-		Namespace:                constants.OperatorNamespace,
+		Namespace:                options.operatorNamespace(),
 		ImageOverrides:           repositoryInfo.Overrides,
 	}
 
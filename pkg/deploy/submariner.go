@@ -55,20 +55,30 @@ type SubmarinerOptions struct {
 	ImageVersion                  string
 	ServiceCIDR                   string
 	ClusterCIDR                   string
-	CustomDomains                 []string
+	// OperatorNamespace is the namespace in which the operator is deployed, by default constants.OperatorNamespace.
+	OperatorNamespace string
+	CustomDomains     []string
+}
+
+func (options *SubmarinerOptions) operatorNamespace() string {
+	if options.OperatorNamespace == "" {
+		return constants.OperatorNamespace
+	}
+
+	return options.OperatorNamespace
 }
 
 func Submariner(ctx context.Context, clientProducer client.Producer, options *SubmarinerOptions, brokerInfo *broker.Info,
 	brokerSecret *v1.Secret, netconfig globalnet.Config, repositoryInfo *image.RepositoryInfo, status reporter.Interface,
 ) error {
-	pskSecret, err := secret.Ensure(ctx, clientProducer.ForKubernetes(), constants.OperatorNamespace, brokerInfo.IPSecPSK)
+	pskSecret, err := secret.Ensure(ctx, clientProducer.ForKubernetes(), options.operatorNamespace(), brokerInfo.IPSecPSK)
 	if err != nil {
 		return status.Error(err, "Error creating PSK secret for cluster")
 	}
 
 	submarinerSpec := populateSubmarinerSpec(options, brokerInfo, brokerSecret, pskSecret, netconfig, repositoryInfo)
 
-	err = submarinercr.Ensure(ctx, clientProducer.ForGeneral(), constants.OperatorNamespace, submarinerSpec)
+	err = submarinercr.Ensure(ctx, clientProducer.ForGeneral(), options.operatorNamespace(), submarinerSpec)
 	if err != nil {
 		return status.Error(err, "Submariner deployment failed")
 	}
@@ -103,7 +113,7 @@ func populateSubmarinerSpec(options *SubmarinerOptions, brokerInfo *broker.Info,
 		ClusterID:                options.ClusterID,
 		ServiceCIDR:              options.ServiceCIDR,
 		ClusterCIDR:              options.ClusterCIDR,
-		Namespace:                constants.OperatorNamespace,
+		Namespace:                options.operatorNamespace(),
 		CableDriver:              options.CableDriver,
 		ServiceDiscoveryEnabled:  brokerInfo.IsServiceDiscoveryEnabled(),
 		ImageOverrides:           repositoryInfo.Overrides,
@@ -0,0 +1,218 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/pkg/brokercr"
+	"github.com/submariner-io/subctl/pkg/client"
+	operatorv1alpha1 "github.com/submariner-io/submariner-operator/api/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+	submarinerv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// This matches the name used by the operator's globalnet support.
+	globalnetConfigMapName = "submariner-globalnet-info"
+	deletionTimeout        = 2 * time.Minute
+)
+
+type BrokerUninstallOptions struct {
+	BrokerNamespace string
+	// OperatorNamespace is the namespace in which the operator was deployed, by default constants.OperatorNamespace.
+	OperatorNamespace string
+	// Force proceeds even if clusters are still connected to the broker.
+	Force bool
+}
+
+// UninstallBroker removes the broker deployed by Broker, in the reverse order: the Broker resource, the globalnet
+// ConfigMap, the operator unless it's still used by the cluster's own Submariner components, and the broker namespace
+// with its RBAC. Resources which are already gone are skipped.
+func UninstallBroker(ctx context.Context, options *BrokerUninstallOptions, clientProducer client.Producer,
+	status reporter.Interface,
+) error {
+	if err := checkNoConnectedClusters(ctx, clientProducer.ForGeneral(), options, status); err != nil {
+		return err
+	}
+
+	status.Start("Deleting the Broker resource")
+
+	err := deleteAndAwait(ctx, clientProducer.ForGeneral(), &operatorv1alpha1.Broker{
+		ObjectMeta: metav1.ObjectMeta{Name: brokercr.Name, Namespace: options.BrokerNamespace},
+	}, status)
+	if err != nil {
+		return stepError(ctx, status, err, "deleting the Broker resource", "error deleting the Broker resource")
+	}
+
+	status.Start("Deleting the globalnet ConfigMap")
+
+	err = deleteAndAwait(ctx, clientProducer.ForGeneral(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: globalnetConfigMapName, Namespace: options.BrokerNamespace},
+	}, status)
+	if err != nil {
+		return stepError(ctx, status, err, "deleting the globalnet ConfigMap", "error deleting the globalnet ConfigMap")
+	}
+
+	if err := deleteOperatorIfUnused(ctx, clientProducer.ForGeneral(), options, status); err != nil {
+		return err
+	}
+
+	status.Start("Deleting the broker namespace %q and its RBAC", options.BrokerNamespace)
+	defer status.End()
+
+	err = deleteAndAwait(ctx, clientProducer.ForGeneral(), &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: options.BrokerNamespace},
+	}, status)
+
+	return stepError(ctx, status, err, "deleting the broker namespace", "error deleting the broker namespace")
+}
+
+func (options *BrokerUninstallOptions) operatorNamespace() string {
+	if options.OperatorNamespace == "" {
+		return constants.OperatorNamespace
+	}
+
+	return options.OperatorNamespace
+}
+
+func checkNoConnectedClusters(ctx context.Context, client controllerClient.Client, options *BrokerUninstallOptions,
+	status reporter.Interface,
+) error {
+	status.Start("Checking for clusters connected to the broker")
+	defer status.End()
+
+	clusters := &submarinerv1.ClusterList{}
+
+	err := client.List(ctx, clusters, controllerClient.InNamespace(options.BrokerNamespace))
+	if meta.IsNoMatchError(err) {
+		status.Success("No clusters are connected to the broker")
+		return nil
+	}
+
+	if err != nil {
+		return stepError(ctx, status, err, "checking for connected clusters", "error listing the connected clusters")
+	}
+
+	if len(clusters.Items) == 0 {
+		status.Success("No clusters are connected to the broker")
+		return nil
+	}
+
+	connected := make([]string, len(clusters.Items))
+	for i := range clusters.Items {
+		connected[i] = clusters.Items[i].Spec.ClusterID
+	}
+
+	if !options.Force {
+		return status.Error(fmt.Errorf("clusters %s are still connected to the broker", strings.Join(connected, ", ")),
+			"Refusing to uninstall the broker, unjoin the clusters first or force the uninstallation")
+	}
+
+	status.Warning("Uninstalling the broker although clusters %s are still connected to it", strings.Join(connected, ", "))
+
+	return nil
+}
+
+// deleteOperatorIfUnused deletes the operator's namespace, unless the cluster's own Submariner components still use it.
+func deleteOperatorIfUnused(ctx context.Context, client controllerClient.Client, options *BrokerUninstallOptions,
+	status reporter.Interface,
+) error {
+	namespace := options.operatorNamespace()
+
+	status.Start("Deleting the Submariner operator in namespace %q", namespace)
+	defer status.End()
+
+	for _, obj := range []controllerClient.Object{&operatorv1alpha1.Submariner{}, &operatorv1alpha1.ServiceDiscovery{}} {
+		name := names.SubmarinerCrName
+		if _, ok := obj.(*operatorv1alpha1.ServiceDiscovery); ok {
+			name = names.ServiceDiscoveryCrName
+		}
+
+		err := client.Get(ctx, controllerClient.ObjectKey{Namespace: namespace, Name: name}, obj)
+		if err == nil {
+			status.Success("Keeping the operator, which still manages this cluster's Submariner components")
+			return nil
+		}
+
+		if !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			return stepError(ctx, status, err, "checking the operator's use", "error checking whether the operator is still in use")
+		}
+	}
+
+	err := deleteAndAwait(ctx, client, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}, status)
+
+	return stepError(ctx, status, err, "deleting the Submariner operator", "error deleting the Submariner operator")
+}
+
+// deleteAndAwait deletes the given object and waits for it to be gone, including its finalizers being processed.
+// Objects which don't exist are reported as such.
+func deleteAndAwait(ctx context.Context, client controllerClient.Client, obj controllerClient.Object, status reporter.Interface,
+) error {
+	description := fmt.Sprintf("%T %q", obj, obj.GetName())
+	if obj.GetNamespace() != "" {
+		description = fmt.Sprintf("%T %s/%s", obj, obj.GetNamespace(), obj.GetName())
+	}
+
+	description = strings.TrimPrefix(description, "*")
+
+	err := client.Delete(ctx, obj)
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		status.Success("%s is already gone", description)
+		return nil
+	}
+
+	if err != nil {
+		return err //nolint:wrapcheck // The caller reports the error
+	}
+
+	err = wait.PollImmediateWithContext(ctx, verifyInterval, deletionTimeout, func(ctx context.Context) (bool, error) {
+		err := client.Get(ctx, controllerClient.ObjectKeyFromObject(obj), obj)
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+
+		return false, err
+	})
+
+	if goerrors.Is(err, wait.ErrWaitTimeout) {
+		return fmt.Errorf("timed out waiting for %s to be deleted, its finalizers %v weren't processed", description,
+			obj.GetFinalizers())
+	}
+
+	if err != nil {
+		return err //nolint:wrapcheck // The caller reports the error
+	}
+
+	status.Success("Deleted %s", description)
+
+	return nil
+}
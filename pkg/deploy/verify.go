@@ -0,0 +1,164 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/internal/constants"
+	"github.com/submariner-io/subctl/pkg/broker"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+const verifyInterval = 2 * time.Second
+
+type brokerResource struct {
+	description string
+	isReady     func(ctx context.Context) (bool, error)
+}
+
+// verifyBroker polls for the resources which make up a deployed broker until they're all ready or the timeout expires.
+// Each resource is reported as it becomes ready; on timeout, the returned error lists those which never did.
+func verifyBroker(ctx context.Context, kubeClient kubernetes.Interface, options *BrokerOptions, status reporter.Interface) error {
+	status.Start("Verifying the broker deployment")
+	defer status.End()
+
+	pending := expectedBrokerResources(kubeClient, options.BrokerNamespace, options.operatorNamespace())
+
+	err := wait.PollImmediateWithContext(ctx, verifyInterval, options.VerifyTimeout, func(ctx context.Context) (bool, error) {
+		remaining := pending[:0]
+
+		for _, resource := range pending {
+			ready, err := resource.isReady(ctx)
+			if err != nil {
+				return false, err
+			}
+
+			if ready {
+				status.Success("%s is ready", resource.description)
+			} else {
+				remaining = append(remaining, resource)
+			}
+		}
+
+		pending = remaining
+
+		return len(pending) == 0, nil
+	})
+
+	if ctx.Err() != nil {
+		return interruptionError(ctx, status, "verifying the broker deployment")
+	}
+
+	if goerrors.Is(err, wait.ErrWaitTimeout) {
+		missing := make([]string, len(pending))
+		for i := range pending {
+			missing[i] = pending[i].description
+		}
+
+		err = fmt.Errorf("timed out after %v, the following never became ready: %s", options.VerifyTimeout,
+			strings.Join(missing, ", "))
+	}
+
+	return status.Error(err, "Broker verification failed")
+}
+
+func expectedBrokerResources(kubeClient kubernetes.Interface, brokerNS, operatorNS string) []brokerResource {
+	resources := []brokerResource{}
+
+	for _, sa := range []string{constants.SubmarinerBrokerAdminSA, broker.SubmarinerBrokerClusterDefaultSA} {
+		name := sa
+		resources = append(resources, brokerResource{
+			description: fmt.Sprintf("Service account %q", name),
+			isReady: exists(func(ctx context.Context) error {
+				_, err := kubeClient.CoreV1().ServiceAccounts(brokerNS).Get(ctx, name, metav1.GetOptions{})
+				return err //nolint:wrapcheck // No need to wrap here
+			}),
+		})
+	}
+
+	bindings := []struct{ serviceAccount, role string }{
+		{constants.SubmarinerBrokerAdminSA, broker.NewBrokerAdminRole().Name},
+		{broker.SubmarinerBrokerClusterDefaultSA, broker.NewBrokerClusterRole().Name},
+	}
+
+	for i := range bindings {
+		role := bindings[i].role
+		binding := broker.NewBrokerRoleBinding(bindings[i].serviceAccount, role, brokerNS).Name
+
+		resources = append(resources, brokerResource{
+			description: fmt.Sprintf("Role %q", role),
+			isReady: exists(func(ctx context.Context) error {
+				_, err := kubeClient.RbacV1().Roles(brokerNS).Get(ctx, role, metav1.GetOptions{})
+				return err //nolint:wrapcheck // No need to wrap here
+			}),
+		}, brokerResource{
+			description: fmt.Sprintf("Role binding %q", binding),
+			isReady: exists(func(ctx context.Context) error {
+				_, err := kubeClient.RbacV1().RoleBindings(brokerNS).Get(ctx, binding, metav1.GetOptions{})
+				return err //nolint:wrapcheck // No need to wrap here
+			}),
+		})
+	}
+
+	return append(resources, brokerResource{
+		description: fmt.Sprintf("Deployment %q", names.OperatorComponent),
+		isReady: func(ctx context.Context) (bool, error) {
+			dp, err := kubeClient.AppsV1().Deployments(operatorNS).Get(ctx, names.OperatorComponent,
+				metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+
+			if err != nil {
+				return false, err //nolint:wrapcheck // No need to wrap here
+			}
+
+			for _, cond := range dp.Status.Conditions {
+				if cond.Type == appsv1.DeploymentAvailable && cond.Status == corev1.ConditionTrue {
+					return true, nil
+				}
+			}
+
+			return false, nil
+		},
+	})
+}
+
+func exists(get func(ctx context.Context) error) func(ctx context.Context) (bool, error) {
+	return func(ctx context.Context) (bool, error) {
+		err := get(ctx)
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+
+		return err == nil, err
+	}
+}
@@ -0,0 +1,238 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	dockerHubDomain   = "docker.io"
+	dockerHubRegistry = "registry-1.docker.io"
+	defaultTag        = "latest"
+	digestSeparator   = "@"
+	digestHeader      = "Docker-Content-Digest"
+	resolveTimeout    = 30 * time.Second
+)
+
+// The manifest types accepted when resolving a tag; multi-architecture indexes are preferred, so that the resolved
+// digest is valid on all nodes.
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// Reference is an image reference split into its registry, repository, and tag or digest.
+type Reference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseReference parses an image reference of the form [registry/]repository[:tag][@digest]; references without
+// a registry refer to Docker Hub.
+func ParseReference(image string) (*Reference, error) {
+	ref := &Reference{}
+
+	remainder, digest, found := strings.Cut(image, digestSeparator)
+	if found {
+		ref.Digest = digest
+	}
+
+	slash := strings.LastIndex(remainder, "/")
+	if colon := strings.LastIndex(remainder, ":"); colon > slash {
+		ref.Tag = remainder[colon+1:]
+		remainder = remainder[:colon]
+	}
+
+	first, rest, found := strings.Cut(remainder, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		ref.Registry = first
+		ref.Repository = rest
+	} else {
+		ref.Registry = dockerHubDomain
+		ref.Repository = remainder
+	}
+
+	if ref.Registry == dockerHubDomain && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+
+	if ref.Repository == "" {
+		return nil, fmt.Errorf("invalid image reference %q", image)
+	}
+
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = defaultTag
+	}
+
+	return ref, nil
+}
+
+// Pinned returns the reference by digest of the image.
+func (r *Reference) Pinned() string {
+	return r.Registry + "/" + r.Repository + digestSeparator + r.Digest
+}
+
+// ResolveDigest queries the image's registry to resolve its tag to the immutable digest of its manifest, and returns
+// the image reference pinned to that digest. Images already referenced by digest are returned unchanged.
+func ResolveDigest(ctx context.Context, image string) (string, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+
+	if ref.Digest != "" {
+		return image, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+
+	registry := ref.Registry
+	if registry == dockerHubDomain {
+		registry = dockerHubRegistry
+	}
+
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", registry, ref.Repository, ref.Tag)
+
+	resp, err := headManifest(ctx, manifestURL, "")
+	if err != nil {
+		return "", errors.Wrapf(err, "error querying the registry %q for image %q", ref.Registry, image)
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		token, err := fetchToken(ctx, resp.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return "", errors.Wrapf(err, "error authenticating with the registry %q for image %q", ref.Registry, image)
+		}
+
+		resp, err = headManifest(ctx, manifestURL, token)
+		if err != nil {
+			return "", errors.Wrapf(err, "error querying the registry %q for image %q", ref.Registry, image)
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("the registry %q returned %q for image %q", ref.Registry, resp.Status, image)
+	}
+
+	ref.Digest = resp.Header.Get(digestHeader)
+	if ref.Digest == "" {
+		return "", fmt.Errorf("the registry %q didn't return the digest of image %q", ref.Registry, image)
+	}
+
+	return ref.Pinned(), nil
+}
+
+func headManifest(ctx context.Context, manifestURL, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, manifestURL, http.NoBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating the manifest request")
+	}
+
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // The caller wraps it
+	}
+
+	resp.Body.Close()
+
+	return resp, nil
+}
+
+// fetchToken obtains an anonymous token from the authorization service described by the given bearer challenge.
+func fetchToken(ctx context.Context, challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+
+	values := url.Values{}
+	realm := ""
+
+	for _, param := range strings.Split(params, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		value = strings.Trim(value, `"`)
+
+		if key == "realm" {
+			realm = value
+		} else {
+			values.Set(key, value)
+		}
+	}
+
+	if realm == "" {
+		return "", fmt.Errorf("the authentication challenge %q has no realm", challenge)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm+"?"+values.Encode(), http.NoBody)
+	if err != nil {
+		return "", errors.Wrap(err, "error creating the token request")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "error requesting a token")
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("the token request returned %q", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Wrap(err, "error reading the token")
+	}
+
+	tokens := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+
+	if err := json.Unmarshal(body, &tokens); err != nil {
+		return "", errors.Wrap(err, "error parsing the token")
+	}
+
+	if tokens.Token != "" {
+		return tokens.Token, nil
+	}
+
+	return tokens.AccessToken, nil
+}
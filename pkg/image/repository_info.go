@@ -22,6 +22,7 @@ import (
 	submariner "github.com/submariner-io/submariner-operator/api/v1alpha1"
 	"github.com/submariner-io/submariner-operator/pkg/images"
 	"github.com/submariner-io/submariner-operator/pkg/names"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 type RepositoryInfo struct {
@@ -55,3 +56,33 @@ func (i *RepositoryInfo) GetNettestImage() string {
 func (i *RepositoryInfo) GetOperatorImage() string {
 	return images.GetImagePath(i.Name, i.Version, names.OperatorImage, names.OperatorComponent, i.Overrides)
 }
+
+// componentImages maps the components which subctl and the operator may deploy to their image names.
+var componentImages = map[string]string{
+	names.OperatorComponent:            names.OperatorImage,
+	names.GatewayComponent:             names.GatewayImage,
+	names.RouteAgentComponent:          names.RouteAgentImage,
+	names.GlobalnetComponent:           names.GlobalnetImage,
+	names.NetworkPluginSyncerComponent: names.NetworkPluginSyncerImage,
+	names.ServiceDiscoveryComponent:    names.ServiceDiscoveryImage,
+	names.LighthouseCoreDNSComponent:   names.LighthouseCoreDNSImage,
+	names.MetricsProxyComponent:        names.MetricsProxyImage,
+	names.NettestComponent:             names.NettestImage,
+}
+
+// GetAllImages returns the sorted, deduplicated references of all the images which may be deployed.
+func (i *RepositoryInfo) GetAllImages() []string {
+	paths := sets.New[string]()
+
+	for component, image := range componentImages {
+		paths.Insert(images.GetImagePath(i.Name, i.Version, image, component, i.Overrides))
+	}
+
+	return sets.List(paths)
+}
+
+// AllImages returns the references of all the images which may be deployed from the given repository and version,
+// taking the given overrides into account. This matches the images used by the broker and join deployments.
+func AllImages(repository, version string, overrides map[string]string) []string {
+	return NewRepositoryInfo(repository, version, overrides).GetAllImages()
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/submariner-io/subctl/pkg/deploy"
 	"github.com/submariner-io/subctl/pkg/image"
 	"github.com/submariner-io/subctl/pkg/operator"
+	"github.com/submariner-io/subctl/pkg/operator/deployment"
 	"github.com/submariner-io/subctl/pkg/secret"
 	"github.com/submariner-io/subctl/pkg/version"
 	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
@@ -89,9 +90,23 @@ func ClusterToBroker(ctx context.Context, brokerInfo *broker.Info, clusterInfo *
 
 	status.Start("Deploying the Submariner operator")
 
+	// On a cluster which is also the broker, the operator may already be deployed, in its own namespace and with its own
+	// placement; they're kept
+	operatorNamespace, placement, err := deployment.Existing(ctx, clientProducer.ForKubernetes())
+	if err != nil {
+		return status.Error(err, "Error retrieving the existing operator")
+	}
+
+	if operatorNamespace == "" {
+		operatorNamespace = constants.OperatorNamespace
+	} else {
+		status.Success("Keeping the namespace %q and the placement of the existing operator", operatorNamespace)
+	}
+
 	repositoryInfo := image.NewRepositoryInfo(options.Repository, options.ImageVersion, imageOverrides)
 
-	err = operator.Ensure(ctx, status, clientProducer, constants.OperatorNamespace, repositoryInfo.GetOperatorImage(), options.OperatorDebug)
+	err = operator.Ensure(ctx, status, clientProducer, operatorNamespace, repositoryInfo.GetOperatorImage(), options.OperatorDebug,
+		placement)
 	if err != nil {
 		return status.Error(err, "Error deploying the operator")
 	}
@@ -106,7 +121,7 @@ func ClusterToBroker(ctx context.Context, brokerInfo *broker.Info, clusterInfo *
 	status.Start("Connecting to Broker")
 
 	// We need to connect to the broker in all cases
-	brokerSecret, err := secret.Ensure(ctx, clientProducer.ForKubernetes(), constants.OperatorNamespace, populateBrokerSecret(brokerInfo))
+	brokerSecret, err := secret.Ensure(ctx, clientProducer.ForKubernetes(), operatorNamespace, populateBrokerSecret(brokerInfo))
 	if err != nil {
 		return status.Error(err, "Error creating broker secret for cluster")
 	}
@@ -114,7 +129,7 @@ func ClusterToBroker(ctx context.Context, brokerInfo *broker.Info, clusterInfo *
 	if brokerInfo.IsConnectivityEnabled() {
 		status.Start("Deploying submariner")
 
-		err := deploy.Submariner(ctx, clientProducer, submarinerOptionsFrom(options), brokerInfo, brokerSecret, netconfig,
+		err := deploy.Submariner(ctx, clientProducer, submarinerOptionsFrom(options, operatorNamespace), brokerInfo, brokerSecret, netconfig,
 			repositoryInfo, status)
 		if err != nil {
 			return status.Error(err, "Error deploying the Submariner resource")
@@ -124,7 +139,7 @@ func ClusterToBroker(ctx context.Context, brokerInfo *broker.Info, clusterInfo *
 	} else if brokerInfo.IsServiceDiscoveryEnabled() {
 		status.Start("Deploying service discovery only")
 
-		err := deploy.ServiceDiscovery(ctx, clientProducer, serviceDiscoveryOptionsFrom(options), brokerInfo, brokerSecret,
+		err := deploy.ServiceDiscovery(ctx, clientProducer, serviceDiscoveryOptionsFrom(options, operatorNamespace), brokerInfo, brokerSecret,
 			repositoryInfo, status)
 		if err != nil {
 			return status.Error(err, "Error deploying the ServiceDiscovery resource")
@@ -136,7 +151,7 @@ func ClusterToBroker(ctx context.Context, brokerInfo *broker.Info, clusterInfo *
 	return nil
 }
 
-func submarinerOptionsFrom(joinOptions *Options) *deploy.SubmarinerOptions {
+func submarinerOptionsFrom(joinOptions *Options, operatorNamespace string) *deploy.SubmarinerOptions {
 	return &deploy.SubmarinerOptions{
 		PreferredServer:               joinOptions.PreferredServer,
 		ForceUDPEncaps:                joinOptions.ForceUDPEncaps,
@@ -158,10 +173,11 @@ func submarinerOptionsFrom(joinOptions *Options) *deploy.SubmarinerOptions {
 		ServiceCIDR:                   joinOptions.ServiceCIDR,
 		ClusterCIDR:                   joinOptions.ClusterCIDR,
 		BrokerK8sInsecure:             !joinOptions.BrokerK8sSecure,
+		OperatorNamespace:             operatorNamespace,
 	}
 }
 
-func serviceDiscoveryOptionsFrom(joinOptions *Options) *deploy.ServiceDiscoveryOptions {
+func serviceDiscoveryOptionsFrom(joinOptions *Options, operatorNamespace string) *deploy.ServiceDiscoveryOptions {
 	return &deploy.ServiceDiscoveryOptions{
 		SubmarinerDebug:        joinOptions.SubmarinerDebug,
 		ClusterID:              joinOptions.ClusterID,
@@ -170,6 +186,7 @@ func serviceDiscoveryOptionsFrom(joinOptions *Options) *deploy.ServiceDiscoveryO
 		ImageVersion:           joinOptions.ImageVersion,
 		CustomDomains:          joinOptions.CustomDomains,
 		BrokerK8sInsecure:      !joinOptions.BrokerK8sSecure,
+		OperatorNamespace:      operatorNamespace,
 	}
 }
 
@@ -33,7 +33,14 @@ import (
 
 // Ensure functions updates or installs the operator CRDs in the cluster.
 func Ensure(ctx context.Context, kubeClient kubernetes.Interface, namespace string, namespaceLabels map[string]string) (bool, error) {
-	ns := &v1.Namespace{ObjectMeta: v1meta.ObjectMeta{Name: namespace, Labels: namespaceLabels}}
+	return EnsureWithAnnotations(ctx, kubeClient, namespace, namespaceLabels, nil)
+}
+
+// EnsureWithAnnotations ensures the namespace exists, merging the given labels and annotations onto those it already has.
+func EnsureWithAnnotations(ctx context.Context, kubeClient kubernetes.Interface, namespace string,
+	namespaceLabels, namespaceAnnotations map[string]string,
+) (bool, error) {
+	ns := &v1.Namespace{ObjectMeta: v1meta.ObjectMeta{Name: namespace, Labels: namespaceLabels, Annotations: namespaceAnnotations}}
 
 	_, err := util.CreateOrUpdate(ctx, resource.ForNamespace(kubeClient), ns, func(existing runtime.Object) (runtime.Object,
 		error,
@@ -47,6 +54,15 @@ func Ensure(ctx context.Context, kubeClient kubernetes.Interface, namespace stri
 		for k, v := range namespaceLabels {
 			ns.Labels[k] = v
 		}
+
+		if len(namespaceAnnotations) > 0 && ns.Annotations == nil {
+			ns.Annotations = map[string]string{}
+		}
+
+		for k, v := range namespaceAnnotations {
+			ns.Annotations[k] = v
+		}
+
 		return existing, nil
 	})
 
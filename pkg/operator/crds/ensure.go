@@ -20,31 +20,181 @@ package crds
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/reporter"
 	"github.com/submariner-io/submariner-operator/pkg/crd"
 	"github.com/submariner-io/submariner-operator/pkg/embeddedyamls"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
-// Ensure functions updates or installs the operator CRDs in the cluster.
-func Ensure(ctx context.Context, crdUpdater crd.Updater) (bool, error) {
-	// Attempt to update or create the CRD definitions.
-	// TODO(majopela): In the future we may want to report when we have updated the existing
-	//                 CRD definition with new versions
-	submarinerCreated, err := crdUpdater.CreateOrUpdateFromEmbedded(ctx,
-		embeddedyamls.Deploy_crds_submariner_io_submariners_yaml)
+var operatorCRDs = []string{
+	embeddedyamls.Deploy_crds_submariner_io_submariners_yaml,
+	embeddedyamls.Deploy_crds_submariner_io_servicediscoveries_yaml,
+	embeddedyamls.Deploy_crds_submariner_io_brokers_yaml,
+}
+
+// VersionComparison compares the API versions of a CRD already present in the cluster with those which would be applied.
+type VersionComparison struct {
+	Name     string
+	Existing []string
+	Intended []string
+	// Compatible is false if applying the CRD would remove versions which are already in use, e.g. when downgrading.
+	Compatible bool
+}
+
+// OperatorCRDCount returns the number of CRDs which the operator needs.
+func OperatorCRDCount() int {
+	return len(operatorCRDs)
+}
+
+// CompareVersions compares the operator CRDs which Ensure would apply with those already present in the cluster.
+// CRDs which aren't present yet aren't included.
+func CompareVersions(ctx context.Context, crdUpdater crd.Updater) ([]VersionComparison, error) {
+	comparisons := []VersionComparison{}
+
+	crds, err := intendedCRDs()
 	if err != nil {
-		return false, errors.Wrap(err, "error provisioning Submariner CRD")
+		return nil, err
+	}
+
+	for _, intended := range crds {
+		existing, err := crdUpdater.Get(ctx, intended.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "error retrieving the existing %s CRD", intended.Name)
+		}
+
+		existingVersions := crdVersions(existing)
+		intendedVersions := crdVersions(intended)
+
+		comparisons = append(comparisons, VersionComparison{
+			Name:       intended.Name,
+			Existing:   sets.List(existingVersions),
+			Intended:   sets.List(intendedVersions),
+			Compatible: intendedVersions.IsSuperset(existingVersions),
+		})
 	}
 
-	serviceDiscoveryCreated, err := crdUpdater.CreateOrUpdateFromEmbedded(ctx,
-		embeddedyamls.Deploy_crds_submariner_io_servicediscoveries_yaml)
+	return comparisons, nil
+}
+
+func crdVersions(crd *apiextensions.CustomResourceDefinition) sets.Set[string] {
+	versions := sets.New[string]()
+	for i := range crd.Spec.Versions {
+		versions.Insert(crd.Spec.Versions[i].Name)
+	}
+
+	return versions
+}
+
+// PartialApplyError is returned by Ensure when applying one of the operator CRDs fails after others were applied, leaving
+// the cluster with a partial set of CRDs.
+type PartialApplyError struct {
+	// Applied lists the CRDs which were applied, or were already up-to-date.
+	Applied []string
+	// Failed is the CRD whose application failed.
+	Failed string
+	// Pending lists the CRDs which weren't attempted.
+	Pending []string
+	Err     error
+}
+
+func (e *PartialApplyError) Error() string {
+	return fmt.Sprintf("error provisioning the %s CRD: %v", e.Failed, e.Err)
+}
+
+func (e *PartialApplyError) Unwrap() error {
+	return e.Err
+}
+
+// Ensure updates or installs the operator CRDs in the cluster, in order, and returns whether any was created. The CRDs
+// which already serve the intended versions are skipped, so that running it again after a partial failure picks up from
+// the CRD which failed; a failure is reported with the applied and pending CRDs, and returned as a PartialApplyError.
+func Ensure(ctx context.Context, crdUpdater crd.Updater, status reporter.Interface) (bool, error) {
+	intended, err := intendedCRDs()
 	if err != nil {
-		return false, errors.Wrap(err, "error provisioning ServiceDiscovery CRD")
+		return false, err
 	}
 
-	brokerCreated, err := crdUpdater.CreateOrUpdateFromEmbedded(ctx,
-		embeddedyamls.Deploy_crds_submariner_io_brokers_yaml)
+	created := false
+	applied := []string{}
+	resumed := []string{}
+
+	for i := range intended {
+		existing, err := crdUpdater.Get(ctx, intended[i].Name, metav1.GetOptions{})
+		if err == nil && isApplied(existing, intended[i]) {
+			applied = append(applied, intended[i].Name)
+			resumed = append(resumed, intended[i].Name)
+
+			continue
+		}
+
+		if len(resumed) > 0 && apierrors.IsNotFound(err) {
+			status.Success("The operator CRDs %s are already applied, picking up with the %s CRD", strings.Join(resumed, ", "),
+				intended[i].Name)
+
+			resumed = nil
+		}
+
+		crdCreated, err := crdUpdater.CreateOrUpdateFromEmbedded(ctx, operatorCRDs[i])
+		if err != nil {
+			partialErr := &PartialApplyError{Applied: applied, Failed: intended[i].Name, Err: err}
+
+			for j := i + 1; j < len(intended); j++ {
+				partialErr.Pending = append(partialErr.Pending, intended[j].Name)
+			}
+
+			reportPartialApply(partialErr, status)
+
+			return created, partialErr
+		}
+
+		created = created || crdCreated
+		applied = append(applied, intended[i].Name)
+	}
+
+	return created, nil
+}
+
+func reportPartialApply(err *PartialApplyError, status reporter.Interface) {
+	if len(err.Applied) == 0 {
+		return
+	}
+
+	pending := "none"
+	if len(err.Pending) > 0 {
+		pending = strings.Join(err.Pending, ", ")
+	}
+
+	status.Warning("The operator CRDs are partially applied: applied %s; failed %s; pending %s. Running the deployment again "+
+		"picks up from the failed CRD", strings.Join(err.Applied, ", "), err.Failed, pending)
+}
+
+func intendedCRDs() ([]*apiextensions.CustomResourceDefinition, error) {
+	intended := make([]*apiextensions.CustomResourceDefinition, len(operatorCRDs))
+
+	for i, crdYAML := range operatorCRDs {
+		intended[i] = &apiextensions.CustomResourceDefinition{}
+		if err := embeddedyamls.GetObject(crdYAML, intended[i]); err != nil {
+			return nil, errors.Wrap(err, "error extracting embedded CRD")
+		}
+	}
+
+	return intended, nil
+}
 
-	return submarinerCreated || serviceDiscoveryCreated || brokerCreated, errors.Wrap(err, "error provisioning Broker CRD")
+// isApplied returns whether the existing CRD has the intended names and versions, including their schemas.
+func isApplied(existing, intended *apiextensions.CustomResourceDefinition) bool {
+	return equality.Semantic.DeepEqual(existing.Spec.Names, intended.Spec.Names) &&
+		equality.Semantic.DeepEqual(existing.Spec.Versions, intended.Spec.Versions)
 }
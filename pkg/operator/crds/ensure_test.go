@@ -0,0 +1,133 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crds_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/reporter"
+	"github.com/submariner-io/subctl/pkg/operator/crds"
+	"github.com/submariner-io/submariner-operator/pkg/crd"
+	"github.com/submariner-io/submariner-operator/pkg/embeddedyamls"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	submarinersCRD      = "submariners.submariner.io"
+	serviceDiscoveryCRD = "servicediscoveries.submariner.io"
+	brokersCRD          = "brokers.submariner.io"
+)
+
+var _ = Describe("Ensure", func() {
+	var (
+		updater *failingUpdater
+		status  *recordingReporter
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(apiextensions.AddToScheme(scheme)).To(Succeed())
+
+		updater = &failingUpdater{
+			Updater: crd.UpdaterFromControllerClient(fake.NewClientBuilder().WithScheme(scheme).Build()),
+			failing: embeddedyamls.Deploy_crds_submariner_io_servicediscoveries_yaml,
+		}
+		status = &recordingReporter{}
+	})
+
+	When("applying a CRD fails midway", func() {
+		It("should report the applied and pending CRDs", func() {
+			_, err := crds.Ensure(context.TODO(), updater, &reporter.Adapter{Basic: status})
+
+			var partialErr *crds.PartialApplyError
+			Expect(errors.As(err, &partialErr)).To(BeTrue())
+			Expect(partialErr.Applied).To(Equal([]string{submarinersCRD}))
+			Expect(partialErr.Failed).To(Equal(serviceDiscoveryCRD))
+			Expect(partialErr.Pending).To(Equal([]string{brokersCRD}))
+
+			Expect(status.warnings).To(ConsistOf(And(ContainSubstring("applied "+submarinersCRD),
+				ContainSubstring("failed "+serviceDiscoveryCRD), ContainSubstring("pending "+brokersCRD))))
+		})
+
+		It("should pick up from the failed CRD when run again", func() {
+			_, err := crds.Ensure(context.TODO(), updater, &reporter.Adapter{Basic: status})
+			Expect(err).To(HaveOccurred())
+
+			updater.failing = ""
+			updater.applied = nil
+
+			created, err := crds.Ensure(context.TODO(), updater, &reporter.Adapter{Basic: status})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).To(BeTrue())
+			Expect(status.successes).To(ContainElement(ContainSubstring("picking up with the " + serviceDiscoveryCRD)))
+			Expect(updater.applied).To(Equal([]string{
+				embeddedyamls.Deploy_crds_submariner_io_servicediscoveries_yaml,
+				embeddedyamls.Deploy_crds_submariner_io_brokers_yaml,
+			}))
+
+			for _, name := range []string{submarinersCRD, serviceDiscoveryCRD, brokersCRD} {
+				_, err := updater.Get(context.TODO(), name, metav1.GetOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	})
+})
+
+// failingUpdater fails to apply the given CRD, and records those it applies.
+type failingUpdater struct {
+	crd.Updater
+	failing string
+	applied []string
+}
+
+func (u *failingUpdater) CreateOrUpdateFromEmbedded(ctx context.Context, crdYAML string) (bool, error) {
+	if crdYAML == u.failing {
+		return false, errors.New("fake apply failure")
+	}
+
+	u.applied = append(u.applied, crdYAML)
+
+	return u.Updater.CreateOrUpdateFromEmbedded(ctx, crdYAML) //nolint:wrapcheck // No need to wrap here
+}
+
+type recordingReporter struct {
+	successes []string
+	warnings  []string
+}
+
+func (r *recordingReporter) Start(_ string, _ ...interface{}) {}
+
+func (r *recordingReporter) Success(message string, args ...interface{}) {
+	r.successes = append(r.successes, fmt.Sprintf(message, args...))
+}
+
+func (r *recordingReporter) Failure(_ string, _ ...interface{}) {}
+
+func (r *recordingReporter) End() {}
+
+func (r *recordingReporter) Warning(message string, args ...interface{}) {
+	r.warnings = append(r.warnings, fmt.Sprintf(message, args...))
+}
@@ -20,6 +20,7 @@ package deployment
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -29,13 +30,28 @@ import (
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/utils/pointer"
 )
 
+// Placement constrains the nodes on which the operator runs, and the resources its container is allotted; the zero value
+// leaves it unconstrained. Env adds environment variables to its container, which mustn't use the ReservedEnvNames.
+type Placement struct {
+	NodeSelector map[string]string
+	Tolerations  []v1.Toleration
+	Resources    v1.ResourceRequirements
+	Env          map[string]string
+}
+
+// ReservedEnvNames are the operator container's environment variables set by subctl, which can't be overridden.
+var ReservedEnvNames = []string{"WATCH_NAMESPACE", "POD_NAME", "OPERATOR_NAME"}
+
 // Ensure the operator is deployed, and running.
-func Ensure(ctx context.Context, kubeClient kubernetes.Interface, namespace, image string, debug bool) (bool, error) {
+func Ensure(ctx context.Context, kubeClient kubernetes.Interface, namespace, image string, debug bool, placement Placement,
+) (bool, error) {
 	operatorName := names.OperatorComponent
 	replicas := int32(1)
 	imagePullPolicy := v1.PullAlways
@@ -66,12 +82,15 @@ func Ensure(ctx context.Context, kubeClient kubernetes.Interface, namespace, ima
 				},
 				Spec: v1.PodSpec{
 					ServiceAccountName: operatorName,
+					NodeSelector:       placement.NodeSelector,
+					Tolerations:        placement.Tolerations,
 					Containers: []v1.Container{
 						{
 							Name:            operatorName,
 							Image:           image,
 							Command:         command,
 							ImagePullPolicy: imagePullPolicy,
+							Resources:       placement.Resources,
 							SecurityContext: &v1.SecurityContext{
 								RunAsNonRoot:             pointer.Bool(true),
 								AllowPrivilegeEscalation: pointer.Bool(false),
@@ -100,6 +119,13 @@ func Ensure(ctx context.Context, kubeClient kubernetes.Interface, namespace, ima
 		},
 	}
 
+	container := &opDeployment.Spec.Template.Spec.Containers[0]
+
+	// The variables are sorted so that the deployment doesn't change between runs
+	for _, name := range sets.List(sets.KeySet(placement.Env)) {
+		container.Env = append(container.Env, v1.EnvVar{Name: name, Value: placement.Env[name]})
+	}
+
 	created, err := deployment.Ensure(ctx, kubeClient, namespace, opDeployment)
 	if err != nil {
 		return false, errors.Wrap(err, "error creating/updating Deployment")
@@ -122,3 +148,54 @@ func GetPodLabelSelector(kubeClient kubernetes.Interface, namespace string) (str
 
 	return labels.SelectorFromSet(dep.Spec.Template.ObjectMeta.Labels).String(), nil
 }
+
+// Existing returns the namespace in which the operator is deployed, and the placement it was deployed with; the namespace
+// is empty if the operator isn't deployed. Only the environment variables with a value, which aren't reserved, are part of
+// the placement.
+func Existing(ctx context.Context, kubeClient kubernetes.Interface) (string, Placement, error) {
+	deployments, err := kubeClient.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", names.OperatorComponent).String(),
+	})
+	if err != nil {
+		return "", Placement{}, errors.Wrap(err, "error listing the operator deployments")
+	}
+
+	switch len(deployments.Items) {
+	case 0:
+		return "", Placement{}, nil
+	case 1:
+	default:
+		namespaces := []string{}
+		for i := range deployments.Items {
+			namespaces = append(namespaces, deployments.Items[i].Namespace)
+		}
+
+		return "", Placement{}, fmt.Errorf("the operator is deployed in several namespaces (%s)", strings.Join(namespaces, ", "))
+	}
+
+	podSpec := &deployments.Items[0].Spec.Template.Spec
+	placement := Placement{NodeSelector: podSpec.NodeSelector, Tolerations: podSpec.Tolerations}
+	reserved := sets.New(ReservedEnvNames...)
+
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name != names.OperatorComponent {
+			continue
+		}
+
+		placement.Resources = podSpec.Containers[i].Resources
+
+		for _, env := range podSpec.Containers[i].Env {
+			if reserved.Has(env.Name) || env.ValueFrom != nil {
+				continue
+			}
+
+			if placement.Env == nil {
+				placement.Env = map[string]string{}
+			}
+
+			placement.Env[env.Name] = env.Value
+		}
+	}
+
+	return deployments.Items[0].Namespace, placement, nil
+}
@@ -37,8 +37,9 @@ import (
 //nolint:wrapcheck // No need to wrap errors here.
 func Ensure(ctx context.Context,
 	status reporter.Interface, clientProducer client.Producer, operatorNamespace, operatorImage string, debug bool,
+	placement deployment.Placement,
 ) error {
-	if created, err := opcrds.Ensure(ctx, crd.UpdaterFromControllerClient(clientProducer.ForGeneral())); err != nil {
+	if created, err := opcrds.Ensure(ctx, crd.UpdaterFromControllerClient(clientProducer.ForGeneral()), status); err != nil {
 		return err
 	} else if created {
 		status.Success("Created operator CRDs")
@@ -83,7 +84,8 @@ func Ensure(ctx context.Context,
 		return err
 	}
 
-	if created, err := deployment.Ensure(ctx, clientProducer.ForKubernetes(), operatorNamespace, operatorImage, debug); err != nil {
+	if created, err := deployment.Ensure(ctx, clientProducer.ForKubernetes(), operatorNamespace, operatorImage, debug,
+		placement); err != nil {
 		return err
 	} else if created {
 		status.Success("Deployed the operator successfully")